@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"todo-app/api/problem"
+	"todo-app/domain"
+	"todo-app/i18n"
+	"todo-app/logging"
+	"todo-app/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	BulkModeAtomic     = "atomic"
+	BulkModeBestEffort = "best_effort"
+)
+
+// BulkRequest is the body of POST /todos/bulk.
+type BulkRequest struct {
+	// Mode is atomic (all or nothing) or best_effort (the default).
+	Mode       string                 `json:"mode" enums:"atomic,best_effort" example:"best_effort"`
+	Operations []domain.BulkOperation `json:"operations"`
+}
+
+// BulkItemResult reports one operation, in request order.
+type BulkItemResult struct {
+	Index  int              `json:"index" example:"0"`
+	Op     domain.BulkOp    `json:"op" example:"create"`
+	ID     *uuid.UUID       `json:"id,omitempty" swaggertype:"string" format:"uuid"`
+	Status int              `json:"status" example:"201"`
+	Todo   *domain.Todo     `json:"todo,omitempty"`
+	Error  *problem.Problem `json:"error,omitempty"`
+}
+
+type BulkResponse struct {
+	Mode      string           `json:"mode" example:"best_effort"`
+	Succeeded int              `json:"succeeded" example:"2"`
+	Failed    int              `json:"failed" example:"1"`
+	Results   []BulkItemResult `json:"results"`
+}
+
+type BulkController struct {
+	usecase       domain.TodoUsecase
+	maxOperations int
+	logger        *slog.Logger
+}
+
+func NewBulkController(usecase domain.TodoUsecase, maxOperations int, logger *slog.Logger) *BulkController {
+	return &BulkController{
+		usecase:       usecase,
+		maxOperations: maxOperations,
+		logger:        logger,
+	}
+}
+
+func (h *BulkController) log(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx, h.logger)
+}
+
+// Bulk applies create, update and delete operations in one request
+// @Summary Bulk create, update and delete todos
+// @Description Applies up to limits.max_bulk_operations operations in order. In best_effort mode every operation is attempted and the response is 207 Multi-Status with one result per operation. In atomic mode the batch runs in one transaction: 200 if all succeed, otherwise nothing is applied and the response carries the failing operation's status, with the other operations reported as 424 bulk_aborted.
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Produce application/problem+json
+// @Param Idempotency-Key header string false "Makes retries safe: the first response is replayed for repeated requests with the same key"
+// @Param request body BulkRequest true "Bulk operations"
+// @Success 200 {object} BulkResponse
+// @Success 207 {object} BulkResponse
+// @Failure 400 {object} BulkResponse "Atomic batch rejected by validation, or a malformed request (problem+json)"
+// @Failure 404 {object} BulkResponse "Atomic batch referenced a missing todo"
+// @Failure 409 {object} problem.Problem "A request with the same Idempotency-Key is still running"
+// @Failure 413 {object} problem.Problem
+// @Failure 422 {object} problem.Problem "Idempotency-Key reused with a different body"
+// @Failure 429 {object} problem.Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} problem.Problem
+// @Router /todos/bulk [post]
+func (h *BulkController) Bulk(c *gin.Context) {
+	ctx, span := tracing.Tracer().Start(c.Request.Context(), "BulkController.Bulk")
+	defer span.End()
+
+	var req BulkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(ctx, c, h.log(ctx), err)
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = BulkModeBestEffort
+	}
+	if req.Mode != BulkModeAtomic && req.Mode != BulkModeBestEffort {
+		problem.Write(c, problem.Validation(domain.NewValidationError(
+			domain.FieldViolation{Field: "mode", Rule: "oneof", Param: BulkModeAtomic + " " + BulkModeBestEffort})))
+		return
+	}
+	if len(req.Operations) == 0 {
+		problem.Write(c, problem.Validation(domain.NewValidationError(
+			domain.FieldViolation{Field: "operations", Rule: "required"})))
+		return
+	}
+	if len(req.Operations) > h.maxOperations {
+		problem.Write(c, problem.TooManyOperations(h.maxOperations))
+		return
+	}
+
+	atomic := req.Mode == BulkModeAtomic
+	results, err := h.usecase.Bulk(ctx, req.Operations, atomic)
+	if err != nil && !errors.Is(err, domain.ErrBulkAborted) {
+		logError(ctx, h.log(ctx), err)
+		problem.Write(c, problem.FromError(err))
+		return
+	}
+
+	resp := BulkResponse{Mode: req.Mode, Results: make([]BulkItemResult, len(results))}
+	status := http.StatusMultiStatus
+	if atomic {
+		status = http.StatusOK
+	}
+	trans := i18n.FromContext(ctx)
+	for i, result := range results {
+		item := BulkItemResult{Index: i, Op: result.Op, Todo: result.Todo, Status: successStatus(result.Op)}
+		if result.ID != uuid.Nil {
+			id := result.ID
+			item.ID = &id
+		}
+		if result.Err != nil {
+			logError(ctx, h.log(ctx), result.Err)
+			item.Error = problem.FromError(result.Err)
+			item.Error.Localize(trans)
+			item.Status = item.Error.Status
+			item.Todo = nil
+			resp.Failed++
+			if atomic && !errors.Is(result.Err, domain.ErrBulkAborted) {
+				status = item.Status
+			}
+		} else {
+			resp.Succeeded++
+		}
+		resp.Results[i] = item
+	}
+	c.JSON(status, resp)
+}
+
+func successStatus(op domain.BulkOp) int {
+	switch op {
+	case domain.BulkCreate:
+		return http.StatusCreated
+	case domain.BulkDelete:
+		return http.StatusNoContent
+	default:
+		return http.StatusOK
+	}
+}
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-app/api/problem"
+	"todo-app/domain"
+	"todo-app/domain/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkController_Bulk(t *testing.T) {
+	mockUsecase := new(mocks.MockTodoUsecase)
+	controller := NewBulkController(mockUsecase, 3, slog.Default())
+	router := setupRouter()
+	router.POST("/todos/bulk", controller.Bulk)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/todos/bulk", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	created := &domain.Todo{ID: uuid.New(), Title: "New", Status: "IN_PROGRESS"}
+	missing := uuid.New()
+
+	t.Run("best effort returns multi-status", func(t *testing.T) {
+		mockUsecase.On("Bulk", mock.Anything, mock.Anything, false).Return([]domain.BulkResult{
+			{Op: domain.BulkCreate, ID: created.ID, Todo: created},
+			{Op: domain.BulkDelete, ID: missing, Err: domain.ErrNotFound},
+		}, nil).Once()
+
+		w := post(fmt.Sprintf(`{"operations":[{"op":"create","todo":{"title":"New","status":"IN_PROGRESS"}},{"op":"delete","id":"%s"}]}`, missing))
+
+		assert.Equal(t, http.StatusMultiStatus, w.Code)
+		var resp BulkResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, BulkModeBestEffort, resp.Mode)
+		assert.Equal(t, 1, resp.Succeeded)
+		assert.Equal(t, 1, resp.Failed)
+		assert.Equal(t, http.StatusCreated, resp.Results[0].Status)
+		assert.Equal(t, created.ID, resp.Results[0].Todo.ID)
+		assert.Equal(t, http.StatusNotFound, resp.Results[1].Status)
+		assert.Equal(t, problem.CodeNotFound, resp.Results[1].Error.Code)
+		assert.Equal(t, missing, *resp.Results[1].ID)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("atomic failure uses the failing status", func(t *testing.T) {
+		mockUsecase.On("Bulk", mock.Anything, mock.Anything, true).Return([]domain.BulkResult{
+			{Op: domain.BulkCreate, Err: domain.ErrBulkAborted},
+			{Op: domain.BulkCreate, Err: domain.NewValidationError(domain.FieldViolation{Field: "title", Rule: "required"})},
+		}, fmt.Errorf("%w: operation 1", domain.ErrBulkAborted)).Once()
+
+		w := post(`{"mode":"atomic","operations":[{"op":"create","todo":{"title":"A"}},{"op":"create","todo":{}}]}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var resp BulkResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, 2, resp.Failed)
+		assert.Equal(t, http.StatusFailedDependency, resp.Results[0].Status)
+		assert.Equal(t, problem.CodeBulkAborted, resp.Results[0].Error.Code)
+		assert.Equal(t, "title is required", resp.Results[1].Error.Errors[0].Message)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("too many operations", func(t *testing.T) {
+		ops := strings.Repeat(`{"op":"delete","id":"`+missing.String()+`"},`, 4)
+
+		w := post(`{"operations":[` + strings.TrimSuffix(ops, ",") + `]}`)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Contains(t, w.Body.String(), "at most 3 operations")
+	})
+
+	t.Run("invalid mode", func(t *testing.T) {
+		w := post(`{"mode":"sometimes","operations":[{"op":"delete"}]}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"field":"mode"`)
+	})
+
+	t.Run("empty batch", func(t *testing.T) {
+		w := post(`{"operations":[]}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"field":"operations"`)
+	})
+}
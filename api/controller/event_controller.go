@@ -0,0 +1,201 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"todo-app/api/problem"
+	"todo-app/domain"
+	"todo-app/events"
+	"todo-app/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EventReset is sent instead of the missed events when a client resumes from
+// an ID that is no longer in the replay buffer. The client should reload the
+// todos it shows.
+const EventReset = "reset"
+
+// eventRetry is how long a disconnected client waits before reconnecting.
+const eventRetry = 3 * time.Second
+
+// todoStatuses are the values accepted by the status filter.
+var todoStatuses = []string{"IN_PROGRESS", "COMPLETED"}
+
+type EventController struct {
+	broker    *events.Broker
+	heartbeat time.Duration
+	logger    *slog.Logger
+}
+
+func NewEventController(broker *events.Broker, heartbeat time.Duration, logger *slog.Logger) *EventController {
+	return &EventController{
+		broker:    broker,
+		heartbeat: heartbeat,
+		logger:    logger,
+	}
+}
+
+func (h *EventController) log(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx, h.logger)
+}
+
+// Stream sends todo changes as Server-Sent Events
+// @Summary Stream todo changes
+// @Description Server-Sent Events stream of todo.created, todo.updated and todo.deleted events. Each event's data is a JSON TodoEvent.
+// @Description Reconnect with the Last-Event-ID header to receive the events missed in between; a "reset" event means they are no longer available and the client should reload.
+// @Description Filters take comma separated values: an event matches when it matches one value of every given filter.
+// @Tags todos
+// @Produce text/event-stream
+// @Produce application/problem+json
+// @Param Last-Event-ID header string false "ID of the last event received"
+// @Param project query string false "Only todos in these projects"
+// @Param tag query string false "Only todos with one of these tags"
+// @Param status query string false "Only todos with these statuses (IN_PROGRESS, COMPLETED)"
+// @Success 200 {object} domain.TodoEvent
+// @Failure 400 {object} problem.Problem
+// @Failure 429 {object} problem.Problem "Rate limit exceeded, see Retry-After"
+// @Router /todos/events [get]
+func (h *EventController) Stream(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	filter := events.Filter{
+		Projects: splitList(c.Query("project")),
+		Tags:     splitList(c.Query("tag")),
+		Statuses: splitList(c.Query("status")),
+	}
+	for _, status := range filter.Statuses {
+		if !slices.Contains(todoStatuses, status) {
+			problem.Write(c, problem.Validation(domain.NewValidationError(domain.FieldViolation{
+				Field: "status", Rule: "oneof", Param: strings.Join(todoStatuses, " "),
+			})))
+			return
+		}
+	}
+
+	sub, missed, complete := h.broker.Subscribe(c.GetHeader("Last-Event-ID"))
+	defer sub.Close()
+
+	// The server's write timeout would cut the stream off; heartbeats keep
+	// dead connections from lingering instead.
+	rc := http.NewResponseController(c.Writer)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		h.log(ctx).WarnContext(ctx, "Failed to clear write deadline", "error", err)
+	}
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	stream := &eventStream{w: c.Writer}
+	stream.retry(eventRetry)
+	if !complete {
+		h.log(ctx).InfoContext(ctx, "Event stream reset", "last_event_id", c.GetHeader("Last-Event-ID"))
+		stream.reset()
+	}
+	for _, event := range missed {
+		stream.send(event, filter)
+	}
+	if stream.flush(); stream.err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stream.keepAlive()
+		case event, ok := <-sub.C:
+			if !ok {
+				// Dropped for falling behind, or shutting down: the client
+				// reconnects and resumes from its last event.
+				h.log(ctx).InfoContext(ctx, "Event stream closed by broker")
+				return
+			}
+			stream.send(event, filter)
+		}
+		if stream.flush(); stream.err != nil {
+			h.log(ctx).DebugContext(ctx, "Event stream write failed", "error", stream.err)
+			return
+		}
+	}
+}
+
+// eventStream writes the text/event-stream format, remembering the first
+// write error.
+type eventStream struct {
+	w gin.ResponseWriter
+	// seen and sent are the IDs of the last event received and written.
+	seen, sent string
+	err        error
+}
+
+func (s *eventStream) printf(format string, args ...any) {
+	if s.err == nil {
+		_, s.err = fmt.Fprintf(s.w, format, args...)
+	}
+}
+
+func (s *eventStream) retry(d time.Duration) {
+	s.printf("retry: %d\n\n", d.Milliseconds())
+}
+
+func (s *eventStream) reset() {
+	s.printf("event: %s\ndata: {}\n\n", EventReset)
+}
+
+func (s *eventStream) send(event domain.TodoEvent, filter events.Filter) {
+	s.seen = event.ID
+	if !filter.Match(event) {
+		return
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		s.err = err
+		return
+	}
+	s.printf("id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	s.sent = event.ID
+}
+
+// keepAlive writes a heartbeat comment. When events were filtered out since
+// the last one sent, it first moves the client's last event ID past them
+// with a data-less message, so a resume does not replay or reset over events
+// the client never wanted.
+func (s *eventStream) keepAlive() {
+	if s.seen != s.sent {
+		s.printf("id: %s\n\n", s.seen)
+		s.sent = s.seen
+	}
+	s.printf(": heartbeat\n\n")
+}
+
+func (s *eventStream) flush() {
+	if s.err == nil {
+		s.w.Flush()
+	}
+}
+
+// splitList parses a comma separated query value, ignoring empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"bufio"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"todo-app/domain"
+	"todo-app/events"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sseClient reads a text/event-stream response one message at a time.
+type sseClient struct {
+	t      *testing.T
+	resp   *http.Response
+	reader *bufio.Reader
+}
+
+func connect(t *testing.T, url, lastEventID string) *sseClient {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return &sseClient{t: t, resp: resp, reader: bufio.NewReader(resp.Body)}
+}
+
+// next returns the lines of the next message.
+func (c *sseClient) next() []string {
+	var lines []string
+	for {
+		line, err := c.reader.ReadString('\n')
+		require.NoError(c.t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return lines
+		}
+		lines = append(lines, line)
+	}
+}
+
+func publishTodo(b *events.Broker, typ domain.TodoEventType, todo domain.Todo) {
+	b.Publish(context.Background(), domain.TodoEvent{Type: typ, Todo: todo})
+}
+
+func TestEventController_Stream(t *testing.T) {
+	broker := events.NewBroker(2, 10)
+	controller := NewEventController(broker, time.Hour, slog.Default())
+	router := setupRouter()
+	router.GET("/todos/events", controller.Stream)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+	defer broker.Close()
+
+	t.Run("streams matching events", func(t *testing.T) {
+		client := connect(t, srv.URL+"/todos/events?project=home,work&status=IN_PROGRESS", "")
+		assert.Equal(t, "text/event-stream", client.resp.Header.Get("Content-Type"))
+		assert.Equal(t, []string{"retry: 3000"}, client.next())
+
+		publishTodo(broker, domain.TodoCreated, domain.Todo{Title: "Other project", Project: "gym", Status: "IN_PROGRESS"})
+		publishTodo(broker, domain.TodoUpdated, domain.Todo{Title: "Match", Project: "work", Status: "IN_PROGRESS"})
+
+		msg := client.next()
+		require.Len(t, msg, 3)
+		assert.True(t, strings.HasPrefix(msg[0], "id: "))
+		assert.Equal(t, "event: todo.updated", msg[1])
+		assert.Contains(t, msg[2], `"title":"Match"`)
+	})
+
+	t.Run("resumes after last event id", func(t *testing.T) {
+		live := connect(t, srv.URL+"/todos/events", "")
+		live.next()
+		publishTodo(broker, domain.TodoCreated, domain.Todo{Title: "Seen"})
+		seen := strings.TrimPrefix(live.next()[0], "id: ")
+		publishTodo(broker, domain.TodoDeleted, domain.Todo{Title: "Missed"})
+
+		client := connect(t, srv.URL+"/todos/events", seen)
+		client.next()
+
+		msg := client.next()
+		assert.Equal(t, "event: todo.deleted", msg[1])
+		assert.Contains(t, msg[2], `"title":"Missed"`)
+	})
+
+	t.Run("resets when the events are gone", func(t *testing.T) {
+		client := connect(t, srv.URL+"/todos/events", "unknown-1")
+		client.next()
+
+		assert.Equal(t, []string{"event: reset", "data: {}"}, client.next())
+	})
+
+	t.Run("rejects unknown status", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/todos/events?status=DONE", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestEventStream_KeepAliveAdvancesFilteredID(t *testing.T) {
+	w := httptest.NewRecorder()
+	router := setupRouter()
+	router.GET("/", func(c *gin.Context) {
+		stream := &eventStream{w: c.Writer}
+		stream.send(domain.TodoEvent{ID: "b-1", Type: domain.TodoCreated, Todo: domain.Todo{Project: "gym"}}, events.Filter{Projects: []string{"home"}})
+		stream.keepAlive()
+		stream.keepAlive()
+	})
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, "id: b-1\n\n: heartbeat\n\n: heartbeat\n\n", w.Body.String())
+}
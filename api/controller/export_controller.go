@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+	"todo-app/api/problem"
+	"todo-app/domain"
+	"todo-app/export"
+	"todo-app/logging"
+	"todo-app/tracing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExportController struct {
+	usecase      domain.TodoUsecase
+	writeTimeout time.Duration
+	logger       *slog.Logger
+}
+
+// NewExportController returns a controller whose downloads may take as
+// long as they need, as long as each write to the client completes within
+// writeTimeout. 0 means no limit.
+func NewExportController(usecase domain.TodoUsecase, writeTimeout time.Duration, logger *slog.Logger) *ExportController {
+	return &ExportController{
+		usecase:      usecase,
+		writeTimeout: writeTimeout,
+		logger:       logger,
+	}
+}
+
+// log returns the request-scoped logger from ctx, falling back to the
+// controller's own logger outside of a request.
+func (h *ExportController) log(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx, h.logger)
+}
+
+// Export downloads todos as a file
+// @Summary Export todos
+// @Description Download the todos GET /todos would return, with the same sort_by, search and q parameters, as a CSV, JSON, NDJSON, Markdown or todo.txt file. Todos are streamed from the database as they are written, except with search, which ranks every match first.
+// @Tags todos
+// @Produce text/csv
+// @Produce json
+// @Produce application/x-ndjson
+// @Produce text/markdown
+// @Produce text/plain
+// @Produce application/problem+json
+// @Param format query string false "File format" Enums(csv, json, ndjson, markdown, todotxt) default(csv)
+// @Param columns query string false "Comma separated CSV columns out of id, title, description, status, project, tags, due_date, created_at, updated_at, completed_at and image; all but image by default"
+// @Param sort_by query string false "Sort by field (title, date, status, due)"
+// @Param search query string false "Only todos matching this full-text search, see GET /todos/search"
+// @Param q query string false "Only todos matching this filter query, see GET /todos"
+// @Param tz query string false "IANA time zone in which the dates of q are days, such as Asia/Bangkok; UTC by default"
+// @Param Time-Zone header string false "Time zone used when tz is not given"
+// @Success 200 {file} file "The exported todos, as an attachment"
+// @Failure 400 {object} problem.Problem
+// @Failure 429 {object} problem.Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} problem.Problem
+// @Router /todos/export [get]
+func (h *ExportController) Export(c *gin.Context) {
+	ctx, span := tracing.Tracer().Start(c.Request.Context(), "ExportController.Export")
+	defer span.End()
+
+	var violations []domain.FieldViolation
+	format, err := export.Lookup(c.Query("format"))
+	violations = appendViolations(violations, err)
+	columns, err := export.ParseColumns(c.Query("columns"))
+	violations = appendViolations(violations, err)
+	if len(violations) > 0 {
+		h.log(ctx).WarnContext(ctx, "Invalid export parameters", "format", c.Query("format"), "columns", c.Query("columns"))
+		problem.Write(c, problem.Validation(domain.NewValidationError(violations...)))
+		return
+	}
+
+	filename := fmt.Sprintf("todos-%s.%s", time.Now().UTC().Format(time.DateOnly), format.Extension)
+	c.Header("Content-Type", format.ContentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+
+	// Large exports take longer than the server's write timeout allows, so
+	// each write gets the full timeout instead.
+	out := io.Writer(c.Writer)
+	if h.writeTimeout > 0 {
+		out = &deadlineWriter{w: c.Writer, rc: http.NewResponseController(c.Writer), timeout: h.writeTimeout}
+	}
+	w := format.NewWriter(out, columns)
+	count := 0
+	err = h.usecase.Export(ctx, domain.ListOptions{
+		SortBy: c.Query("sort_by"),
+		Search: c.Query("search"),
+		Query:  c.Query("q"),
+	}, func(todo domain.Todo) error {
+		count++
+		return w.Write(todo)
+	})
+	if err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		if c.Writer.Written() {
+			// The status is sent, so all that is left is to cut the file short.
+			h.log(ctx).WarnContext(ctx, "Export aborted", "error", err, "count", count)
+			c.Abort()
+			return
+		}
+		c.Writer.Header().Del("Content-Disposition")
+		logError(ctx, h.log(ctx), err)
+		problem.Write(c, problem.FromError(err))
+		return
+	}
+	h.log(ctx).InfoContext(ctx, "Todos exported", "format", format.Name, "count", count)
+}
+
+// deadlineWriter moves the write deadline of the connection to timeout from
+// now before each write, so a client that stops reading is still cut off.
+type deadlineWriter struct {
+	w       io.Writer
+	rc      *http.ResponseController
+	timeout time.Duration
+}
+
+func (w *deadlineWriter) Write(p []byte) (int, error) {
+	if err := w.rc.SetWriteDeadline(time.Now().Add(w.timeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return 0, err
+	}
+	return w.w.Write(p)
+}
+
+// appendViolations appends the violations of a validation error to
+// violations.
+func appendViolations(violations []domain.FieldViolation, err error) []domain.FieldViolation {
+	var validationErr *domain.ValidationError
+	if errors.As(err, &validationErr) {
+		violations = append(violations, validationErr.Violations...)
+	}
+	return violations
+}
@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"todo-app/api/problem"
+	"todo-app/domain"
+	"todo-app/domain/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportController_Export(t *testing.T) {
+	mockUsecase := new(mocks.MockTodoUsecase)
+	controller := NewExportController(mockUsecase, 0, slog.Default())
+	router := setupRouter()
+	router.GET("/todos/export", controller.Export)
+
+	get := func(url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+		return w
+	}
+	todos := []domain.Todo{
+		{Title: "Ship release", Status: "IN_PROGRESS", Tags: []string{"backend"}},
+		{Title: "Write notes", Status: "COMPLETED"},
+	}
+
+	t.Run("csv with columns and filters", func(t *testing.T) {
+		opts := domain.ListOptions{SortBy: "title", Query: "tag:backend"}
+		mockUsecase.On("Export", mock.Anything, opts, mock.Anything).Return(todos[:1], nil).Once()
+
+		w := get("/todos/export?columns=title,status,tags&sort_by=title&q=tag:backend")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Regexp(t, `^attachment; filename="todos-\d{4}-\d{2}-\d{2}\.csv"$`, w.Header().Get("Content-Disposition"))
+		assert.Equal(t, "title,status,tags\nShip release,IN_PROGRESS,backend\n", w.Body.String())
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		mockUsecase.On("Export", mock.Anything, domain.ListOptions{}, mock.Anything).Return(todos, nil).Once()
+
+		w := get("/todos/export?format=ndjson")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), `.ndjson"`)
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		require.Len(t, lines, 2)
+		var todo domain.Todo
+		require.NoError(t, json.Unmarshal([]byte(lines[1]), &todo))
+		assert.Equal(t, "Write notes", todo.Title)
+	})
+
+	t.Run("invalid format and columns", func(t *testing.T) {
+		w := get("/todos/export?format=xlsx&columns=title,priority")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var body problem.Problem
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Len(t, body.Errors, 2)
+		assert.Equal(t, "format", body.Errors[0].Field)
+		assert.Equal(t, "format must be one of: csv, json, ndjson, markdown, todotxt", body.Errors[0].Message)
+		assert.Equal(t, "columns", body.Errors[1].Field)
+	})
+
+	t.Run("usecase error before the first todo", func(t *testing.T) {
+		err := domain.NewValidationError(domain.FieldViolation{Field: "sort_by", Rule: "oneof", Param: "title date status due"})
+		mockUsecase.On("Export", mock.Anything, domain.ListOptions{SortBy: "priority"}, mock.Anything).Return(nil, err).Once()
+
+		w := get("/todos/export?format=json&sort_by=priority")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+		assert.Empty(t, w.Header().Get("Content-Disposition"))
+	})
+}
+
+func TestExportController_WriteTimeout(t *testing.T) {
+	const timeout = 200 * time.Millisecond
+	mockUsecase := new(mocks.MockTodoUsecase)
+	controller := NewExportController(mockUsecase, timeout, slog.Default())
+	done := make(chan struct{}, 1)
+	router := setupRouter()
+	router.GET("/todos/export", func(c *gin.Context) {
+		defer func() { done <- struct{}{} }()
+		controller.Export(c)
+	})
+	server := httptest.NewUnstartedServer(router)
+	server.Config.WriteTimeout = timeout
+	server.Start()
+	defer server.Close()
+
+	t.Run("slow exports finish", func(t *testing.T) {
+		mockUsecase.On("Export", mock.Anything, domain.ListOptions{}, mock.Anything).
+			Run(func(mock.Arguments) { time.Sleep(2 * timeout) }).
+			Return([]domain.Todo{{Title: "Ship release", Status: "IN_PROGRESS"}}, nil).Once()
+
+		resp, err := http.Get(server.URL + "/todos/export?columns=title")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "title\nShip release\n", string(body))
+		<-done
+	})
+
+	t.Run("clients that stop reading are cut off", func(t *testing.T) {
+		// Far more than the socket buffers hold.
+		todos := make([]domain.Todo, 200)
+		for i := range todos {
+			todos[i] = domain.Todo{Title: "Ship release", Description: strings.Repeat("x", 64<<10), Status: "IN_PROGRESS"}
+		}
+		mockUsecase.On("Export", mock.Anything, domain.ListOptions{}, mock.Anything).Return(todos, nil).Once()
+
+		resp, err := http.Get(server.URL + "/todos/export?columns=description")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the export still blocks on a client that stopped reading")
+		}
+	})
+}
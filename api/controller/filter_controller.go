@@ -0,0 +1,235 @@
+package controller
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"todo-app/api/middleware"
+	"todo-app/api/problem"
+	"todo-app/domain"
+	"todo-app/logging"
+	"todo-app/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// FilterRequest is the body of POST /filters and PUT /filters/{id}.
+type FilterRequest struct {
+	Name string `json:"name" example:"Backend this sprint"`
+	// Query is a filter query as accepted by GET /todos?q=.
+	Query string `json:"query" example:"tag:backend status:IN_PROGRESS due<today+14d"`
+	// Sort is a sort_by value of GET /todos.
+	Sort string `json:"sort,omitempty" example:"due"`
+}
+
+func (r FilterRequest) filter(owner string) *domain.SavedFilter {
+	return &domain.SavedFilter{Name: r.Name, Query: r.Query, Sort: r.Sort, Owner: owner}
+}
+
+type FilterController struct {
+	usecase domain.SavedFilterUsecase
+	logger  *slog.Logger
+}
+
+func NewFilterController(usecase domain.SavedFilterUsecase, logger *slog.Logger) *FilterController {
+	return &FilterController{
+		usecase: usecase,
+		logger:  logger,
+	}
+}
+
+func (h *FilterController) log(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx, h.logger)
+}
+
+// Create saves a filter
+// @Summary Save a filter
+// @Description Save a filter query and sort order of the calling user. The query is checked like GET /todos?q=.
+// @Tags filters
+// @Accept json
+// @Produce json
+// @Produce application/problem+json
+// @Param X-User-ID header string true "Authenticated user"
+// @Param filter body FilterRequest true "Filter"
+// @Success 201 {object} domain.SavedFilter
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 429 {object} problem.Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} problem.Problem
+// @Router /filters [post]
+func (h *FilterController) Create(c *gin.Context) {
+	ctx, span := tracing.Tracer().Start(c.Request.Context(), "FilterController.Create")
+	defer span.End()
+
+	var req FilterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(ctx, c, h.log(ctx), err)
+		return
+	}
+
+	filter := req.filter(middleware.GetUserID(c))
+	if err := h.usecase.Create(ctx, filter); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, filter)
+}
+
+// Update replaces a saved filter
+// @Summary Update a saved filter
+// @Description Replace the name, query and sort of a saved filter. Smart lists cannot be changed.
+// @Tags filters
+// @Accept json
+// @Produce json
+// @Produce application/problem+json
+// @Param X-User-ID header string true "Authenticated user"
+// @Param id path string true "Filter ID"
+// @Param filter body FilterRequest true "Filter"
+// @Success 200 {object} domain.SavedFilter
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem "Smart lists are read-only"
+// @Failure 404 {object} problem.Problem
+// @Failure 429 {object} problem.Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} problem.Problem
+// @Router /filters/{id} [put]
+func (h *FilterController) Update(c *gin.Context) {
+	ctx, span := tracing.Tracer().Start(c.Request.Context(), "FilterController.Update")
+	defer span.End()
+
+	id := filterID(c)
+	var req FilterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(ctx, c, h.log(ctx), err)
+		return
+	}
+
+	filter := req.filter(middleware.GetUserID(c))
+	filter.ID = id
+	if err := h.usecase.Update(ctx, filter); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, filter)
+}
+
+// Get returns a saved filter
+// @Summary Get a saved filter
+// @Tags filters
+// @Produce json
+// @Produce application/problem+json
+// @Param X-User-ID header string true "Authenticated user"
+// @Param id path string true "Filter ID or smart list key"
+// @Success 200 {object} domain.SavedFilter
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 429 {object} problem.Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} problem.Problem
+// @Router /filters/{id} [get]
+func (h *FilterController) Get(c *gin.Context) {
+	ctx, span := tracing.Tracer().Start(c.Request.Context(), "FilterController.Get")
+	defer span.End()
+
+	id := filterID(c)
+	filter, err := h.usecase.Get(ctx, middleware.GetUserID(c), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, filter)
+}
+
+// List returns the smart lists and saved filters
+// @Summary List saved filters
+// @Description The built-in smart lists followed by the saved filters of the calling user, oldest first
+// @Tags filters
+// @Produce json
+// @Produce application/problem+json
+// @Param X-User-ID header string true "Authenticated user"
+// @Success 200 {array} domain.SavedFilter
+// @Failure 401 {object} problem.Problem
+// @Failure 429 {object} problem.Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} problem.Problem
+// @Router /filters [get]
+func (h *FilterController) List(c *gin.Context) {
+	ctx, span := tracing.Tracer().Start(c.Request.Context(), "FilterController.List")
+	defer span.End()
+
+	filters, err := h.usecase.List(ctx, middleware.GetUserID(c))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, filters)
+}
+
+// Delete removes a saved filter
+// @Summary Delete a saved filter
+// @Description Smart lists cannot be deleted.
+// @Tags filters
+// @Produce application/problem+json
+// @Param X-User-ID header string true "Authenticated user"
+// @Param id path string true "Filter ID"
+// @Success 204
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem "Smart lists are read-only"
+// @Failure 404 {object} problem.Problem
+// @Failure 429 {object} problem.Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} problem.Problem
+// @Router /filters/{id} [delete]
+func (h *FilterController) Delete(c *gin.Context) {
+	ctx, span := tracing.Tracer().Start(c.Request.Context(), "FilterController.Delete")
+	defer span.End()
+
+	id := filterID(c)
+	if err := h.usecase.Delete(ctx, middleware.GetUserID(c), id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Todos runs a saved filter
+// @Summary Run a saved filter
+// @Description The todos matching the filter's query in its sort order, as GET /todos?q=&sort_by= would return them
+// @Tags filters
+// @Produce json
+// @Produce application/problem+json
+// @Param X-User-ID header string true "Authenticated user"
+// @Param id path string true "Filter ID or smart list key"
+// @Param tz query string false "IANA time zone in which the dates of the query are days, such as Asia/Bangkok; UTC by default"
+// @Param Time-Zone header string false "Time zone used when tz is not given"
+// @Success 200 {array} domain.Todo
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 429 {object} problem.Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} problem.Problem
+// @Router /filters/{id}/todos [get]
+func (h *FilterController) Todos(c *gin.Context) {
+	ctx, span := tracing.Tracer().Start(c.Request.Context(), "FilterController.Todos")
+	defer span.End()
+
+	id := filterID(c)
+	todos, err := h.usecase.Todos(ctx, middleware.GetUserID(c), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, todos)
+}
+
+// filterID accepts a filter ID or the key of a smart list, such as "today".
+// An unknown key resolves to an ID that is not found.
+func filterID(c *gin.Context) uuid.UUID {
+	if id, err := uuid.Parse(c.Param("id")); err == nil {
+		return id
+	}
+	return domain.SmartListID(c.Param("id"))
+}
+
+func (h *FilterController) handleError(c *gin.Context, err error) {
+	logError(c.Request.Context(), h.log(c.Request.Context()), err)
+	problem.Write(c, problem.FromError(err))
+}
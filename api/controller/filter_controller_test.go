@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-app/api/middleware"
+	"todo-app/api/problem"
+	"todo-app/domain"
+	"todo-app/domain/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterController(t *testing.T) {
+	mockUsecase := new(mocks.MockSavedFilterUsecase)
+	controller := NewFilterController(mockUsecase, slog.Default())
+	router := setupRouter()
+	filters := router.Group("/filters", middleware.RequestID(slog.Default()), middleware.RequireUser())
+	filters.POST("", controller.Create)
+	filters.PUT("/:id", controller.Update)
+	filters.DELETE("/:id", controller.Delete)
+	filters.GET("/:id/todos", controller.Todos)
+
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middleware.UserHeader, "alice")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	id := uuid.New()
+
+	t.Run("create saves for the caller", func(t *testing.T) {
+		mockUsecase.On("Create", mock.Anything, mock.MatchedBy(func(f *domain.SavedFilter) bool {
+			return f.Owner == "alice" && f.Query == "tag:backend" && f.Sort == "due"
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.SavedFilter).ID = id
+		}).Return(nil).Once()
+
+		w := do(http.MethodPost, "/filters", `{"name":"Backend","query":"tag:backend","sort":"due"}`)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), id.String())
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("todos accepts a smart list key", func(t *testing.T) {
+		todos := []domain.Todo{{Title: "Due today", Status: "IN_PROGRESS"}}
+		mockUsecase.On("Todos", mock.Anything, "alice", domain.SmartListID("today")).Return(todos, nil).Once()
+
+		w := do(http.MethodGet, "/filters/today/todos", "")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Due today")
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("smart lists are read-only", func(t *testing.T) {
+		mockUsecase.On("Delete", mock.Anything, "alice", domain.SmartListID("overdue")).Return(domain.ErrReadOnly).Once()
+
+		w := do(http.MethodDelete, "/filters/overdue", "")
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		var body problem.Problem
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, problem.CodeReadOnly, body.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("update reports query errors", func(t *testing.T) {
+		err := &domain.QueryError{Position: 1, Code: domain.QueryUnknownField, Param: "colour"}
+		mockUsecase.On("Update", mock.Anything, mock.MatchedBy(func(f *domain.SavedFilter) bool { return f.ID == id })).Return(err).Once()
+
+		w := do(http.MethodPut, "/filters/"+id.String(), `{"name":"Backend","query":"colour:red"}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"position":1`)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("requires a user", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/filters/today/todos", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
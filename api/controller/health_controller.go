@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"log/slog"
+	"net/http"
+	"todo-app/health"
+	"todo-app/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthController struct {
+	registry *health.Registry
+	logger   *slog.Logger
+}
+
+func NewHealthController(registry *health.Registry, logger *slog.Logger) *HealthController {
+	return &HealthController{
+		registry: registry,
+		logger:   logger,
+	}
+}
+
+// Liveness reports that the process is running
+// @Summary Liveness probe
+// @Description Always returns 200 while the process can serve requests
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /healthz [get]
+func (h *HealthController) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readiness reports whether the service can take traffic
+// @Summary Readiness probe
+// @Description Checks the database, migrations and other registered dependencies
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /readyz [get]
+func (h *HealthController) Readiness(c *gin.Context) {
+	ctx := c.Request.Context()
+	report := h.registry.Check(ctx)
+	if !report.Ready() {
+		logging.FromContext(ctx, h.logger).WarnContext(ctx, "Readiness check failed", "status", report.Status, "checks", report.Checks)
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"todo-app/health"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthController_Liveness(t *testing.T) {
+	controller := NewHealthController(health.NewRegistry(time.Second), slog.Default())
+	router := setupRouter()
+	router.GET("/healthz", controller.Liveness)
+
+	req := httptest.NewRequest("GET", "/healthz", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestHealthController_Readiness(t *testing.T) {
+	dbErr := error(nil)
+	registry := health.NewRegistry(time.Second)
+	registry.Register("database", func(ctx context.Context) error { return dbErr })
+	controller := NewHealthController(registry, slog.Default())
+	router := setupRouter()
+	router.GET("/readyz", controller.Readiness)
+
+	t.Run("ready", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/readyz", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var report health.Report
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, health.StatusReady, report.Status)
+		assert.Equal(t, health.StatusUp, report.Checks["database"].Status)
+	})
+
+	t.Run("dependency down", func(t *testing.T) {
+		dbErr = errors.New("connection refused")
+		defer func() { dbErr = nil }()
+
+		req := httptest.NewRequest("GET", "/readyz", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Contains(t, w.Body.String(), "connection refused")
+	})
+
+	t.Run("shutting down", func(t *testing.T) {
+		registry.SetShuttingDown()
+
+		req := httptest.NewRequest("GET", "/readyz", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Contains(t, w.Body.String(), health.StatusShuttingDown)
+	})
+}
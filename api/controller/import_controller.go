@@ -0,0 +1,204 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"todo-app/api/problem"
+	"todo-app/domain"
+	"todo-app/i18n"
+	"todo-app/importer"
+	"todo-app/logging"
+	"todo-app/tracing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportRowError reports a row that was not imported.
+type ImportRowError struct {
+	// Row is the 1-based row of a CSV file, counting the header, or the
+	// 1-based index in a JSON array.
+	Row    int                     `json:"row" example:"3"`
+	Errors []domain.FieldViolation `json:"errors"`
+}
+
+// ImportResponse is the report of POST /todos/import.
+type ImportResponse struct {
+	DryRun bool `json:"dry_run" example:"false"`
+	Total  int  `json:"total" example:"3"`
+	// Created counts the todos created, or that would be in a dry run.
+	Created int `json:"created" example:"2"`
+	Failed  int `json:"failed" example:"1"`
+	// IgnoredColumns are the columns or JSON keys that set no field.
+	IgnoredColumns []string `json:"ignored_columns" example:"Owner"`
+	// Todos are the created todos, or the todos that would be created in a
+	// dry run, without IDs.
+	Todos  []domain.Todo    `json:"todos"`
+	Errors []ImportRowError `json:"errors"`
+}
+
+type ImportController struct {
+	usecase domain.TodoUsecase
+	logger  *slog.Logger
+}
+
+func NewImportController(usecase domain.TodoUsecase, logger *slog.Logger) *ImportController {
+	return &ImportController{
+		usecase: usecase,
+		logger:  logger,
+	}
+}
+
+// log returns the request-scoped logger from ctx, falling back to the
+// controller's own logger outside of a request.
+func (h *ImportController) log(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx, h.logger)
+}
+
+// importFormats maps the media types and file extensions of uploads to
+// import formats.
+var importFormats = map[string]string{
+	"text/csv":         "csv",
+	"application/csv":  "csv",
+	"application/json": "json",
+	"text/plain":       "todotxt",
+	".csv":             "csv",
+	".json":            "json",
+	".txt":             "todotxt",
+}
+
+// Import creates todos from a CSV, JSON or todo.txt file
+// @Summary Import todos
+// @Description Creates todos from a CSV file with a header row, a JSON array of objects or a todo.txt file, such as the files of GET /todos/export, sent as the request body or as the file field of a multipart form. Columns match the todo fields title, description, status, project, tags, due_date and image by name; map[column]=field maps others. Every row is validated; valid rows are created in one transaction and invalid ones are reported by row. With dry_run nothing is created.
+// @Tags todos
+// @Accept text/csv
+// @Accept json
+// @Accept text/plain
+// @Accept multipart/form-data
+// @Produce json
+// @Produce application/problem+json
+// @Param Idempotency-Key header string false "Makes retries safe: the first response is replayed for repeated requests with the same key"
+// @Param format query string false "File format, by default taken from the content type or file name" Enums(csv, json, todotxt)
+// @Param dry_run query bool false "Only validate and report what would be created"
+// @Param map[column] query string false "Field set by a column or JSON key, for example map[Task]=title"
+// @Param file formData file false "The file, for multipart uploads"
+// @Success 200 {object} ImportResponse "Dry run, or no row was valid"
+// @Success 201 {object} ImportResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 413 {object} problem.Problem
+// @Failure 429 {object} problem.Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} problem.Problem
+// @Router /todos/import [post]
+func (h *ImportController) Import(c *gin.Context) {
+	ctx, span := tracing.Tracer().Start(c.Request.Context(), "ImportController.Import")
+	defer span.End()
+
+	var violations []domain.FieldViolation
+	dryRun := false
+	if raw := c.Query("dry_run"); raw != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(raw); err != nil {
+			violations = append(violations, domain.FieldViolation{Field: "dry_run", Rule: "boolean"})
+		}
+	}
+	mapping := importer.Mapping(c.QueryMap("map"))
+	violations = appendViolations(violations, mapping.Validate())
+
+	body, mediaType, filename, err := h.upload(c)
+	if err != nil {
+		bindError(ctx, c, h.log(ctx), err)
+		return
+	}
+	defer body.Close()
+
+	format := c.Query("format")
+	if format == "" {
+		if format = importFormats[mediaType]; format == "" {
+			format = importFormats[filepath.Ext(filename)]
+		}
+	}
+	if format != "csv" && format != "json" && format != "todotxt" {
+		violations = append(violations, domain.FieldViolation{Field: "format", Rule: "oneof", Param: "csv json todotxt"})
+	}
+	if len(violations) > 0 {
+		h.log(ctx).WarnContext(ctx, "Invalid import parameters", "format", format, "content_type", mediaType)
+		problem.Write(c, problem.Validation(domain.NewValidationError(violations...)))
+		return
+	}
+
+	var file *importer.File
+	switch format {
+	case "csv":
+		file, err = importer.ReadCSV(body, mapping)
+	case "json":
+		file, err = importer.ReadJSON(body, mapping)
+	case "todotxt":
+		file, err = importer.ReadTodoTxt(body)
+	}
+	if err != nil {
+		bindError(ctx, c, h.log(ctx), err)
+		return
+	}
+
+	records, err := h.usecase.Import(ctx, file.Records, dryRun)
+	if err != nil {
+		logError(ctx, h.log(ctx), err)
+		problem.Write(c, problem.FromError(err))
+		return
+	}
+
+	trans := i18n.FromContext(ctx)
+	resp := ImportResponse{
+		DryRun:         dryRun,
+		Total:          len(records),
+		IgnoredColumns: file.Ignored,
+		Todos:          []domain.Todo{},
+		Errors:         []ImportRowError{},
+	}
+	if resp.IgnoredColumns == nil {
+		resp.IgnoredColumns = []string{}
+	}
+	for _, record := range records {
+		if record.Err == nil {
+			resp.Todos = append(resp.Todos, record.Todo)
+			continue
+		}
+		var validationErr *domain.ValidationError
+		if !errors.As(record.Err, &validationErr) {
+			validationErr = domain.NewValidationError(domain.FieldViolation{Field: "row", Rule: "default", Param: record.Err.Error()})
+		}
+		resp.Errors = append(resp.Errors, ImportRowError{Row: record.Row, Errors: i18n.Translate(trans, validationErr.Violations)})
+	}
+	resp.Created, resp.Failed = len(resp.Todos), len(resp.Errors)
+
+	h.log(ctx).InfoContext(ctx, "Todos imported", "dry_run", dryRun, "created", resp.Created, "failed", resp.Failed)
+	status := http.StatusOK
+	if !dryRun && resp.Created > 0 {
+		status = http.StatusCreated
+	}
+	c.JSON(status, resp)
+}
+
+// upload returns the uploaded file with its media type and, for multipart
+// forms, its file name.
+func (h *ImportController) upload(c *gin.Context) (io.ReadCloser, string, string, error) {
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return c.Request.Body, mediaType, "", nil
+	}
+	header, err := c.FormFile("file")
+	if err != nil {
+		return nil, "", "", err
+	}
+	f, err := header.Open()
+	if err != nil {
+		return nil, "", "", err
+	}
+	mediaType, _, _ = mime.ParseMediaType(header.Header.Get("Content-Type"))
+	return f, mediaType, header.Filename, nil
+}
@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-app/api/problem"
+	"todo-app/domain"
+	"todo-app/domain/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportController_Import(t *testing.T) {
+	mockUsecase := new(mocks.MockTodoUsecase)
+	controller := NewImportController(mockUsecase, slog.Default())
+	router := setupRouter()
+	router.POST("/todos/import", controller.Import)
+
+	post := func(url, contentType string, body *bytes.Buffer) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", url, body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("csv with mapping", func(t *testing.T) {
+		read := mock.MatchedBy(func(records []domain.ImportRecord) bool {
+			return len(records) == 3 && records[0].Todo.Title == "Ship release" && records[0].Todo.DueDate != nil &&
+				records[1].Err == nil && records[2].Err != nil
+		})
+		mockUsecase.On("Import", mock.Anything, read, false).Return([]domain.ImportRecord{
+			{Row: 2, Todo: domain.Todo{ID: uuid.New(), Title: "Ship release", Status: "IN_PROGRESS"}},
+			{Row: 3, Err: domain.NewValidationError(domain.FieldViolation{Field: "title", Rule: "required"})},
+			{Row: 4, Err: domain.NewValidationError(domain.FieldViolation{Field: "due_date", Rule: "datetime"})},
+		}, nil).Once()
+
+		body := bytes.NewBufferString("Task,Owner,Due\nShip release,ann,2026-11-01\n,bob,\nPlan,,soon\n")
+		w := post("/todos/import?map[Task]=title&map[Due]=due_date", "text/csv", body)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var resp ImportResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.False(t, resp.DryRun)
+		assert.Equal(t, 3, resp.Total)
+		assert.Equal(t, 1, resp.Created)
+		assert.Equal(t, 2, resp.Failed)
+		assert.Equal(t, []string{"Owner"}, resp.IgnoredColumns)
+		require.Len(t, resp.Todos, 1)
+		assert.Equal(t, "Ship release", resp.Todos[0].Title)
+		assert.Equal(t, []ImportRowError{
+			{Row: 3, Errors: []domain.FieldViolation{{Field: "title", Rule: "required", Message: "title is required"}}},
+			{Row: 4, Errors: []domain.FieldViolation{{Field: "due_date", Rule: "datetime", Message: "due_date must be a date such as 2026-11-01 or an RFC 3339 time"}}},
+		}, resp.Errors)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("multipart json dry run", func(t *testing.T) {
+		records := []domain.ImportRecord{{Row: 1, Todo: domain.Todo{Title: "Plan", Status: "IN_PROGRESS"}}}
+		mockUsecase.On("Import", mock.Anything, records, true).Return(records, nil).Once()
+
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, err := form.CreateFormFile("file", "todos.json")
+		require.NoError(t, err)
+		_, err = part.Write([]byte(`[{"title": "Plan"}]`))
+		require.NoError(t, err)
+		require.NoError(t, form.Close())
+		w := post("/todos/import?dry_run=true", form.FormDataContentType(), &body)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp ImportResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.True(t, resp.DryRun)
+		assert.Equal(t, 1, resp.Created)
+		assert.Empty(t, resp.Errors)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("todo.txt", func(t *testing.T) {
+		read := mock.MatchedBy(func(records []domain.ImportRecord) bool {
+			return len(records) == 2 && records[0].Row == 1 && records[0].Todo.Project == "Launch" &&
+				records[1].Row == 3 && records[1].Todo.Status == "COMPLETED"
+		})
+		mockUsecase.On("Import", mock.Anything, read, false).Return([]domain.ImportRecord{
+			{Row: 1, Todo: domain.Todo{ID: uuid.New(), Title: "Write notes", Status: "IN_PROGRESS", Project: "Launch"}},
+			{Row: 3, Todo: domain.Todo{ID: uuid.New(), Title: "Ship", Status: "COMPLETED"}},
+		}, nil).Once()
+
+		body := bytes.NewBufferString("(A) Write notes +Launch @docs\n\nx 2026-10-16 Ship\n")
+		w := post("/todos/import", "text/plain; charset=utf-8", body)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var resp ImportResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, 2, resp.Created)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		w := post("/todos/import?dry_run=maybe&map[Owner]=assignee", "application/xml", bytes.NewBufferString("title\nPlan\n"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var body problem.Problem
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		var fields []string
+		for _, v := range body.Errors {
+			fields = append(fields, v.Field)
+		}
+		assert.Equal(t, []string{"dry_run", "map[Owner]", "format"}, fields)
+	})
+
+	t.Run("malformed file", func(t *testing.T) {
+		w := post("/todos/import", "application/json", bytes.NewBufferString(`{"title": "Plan"}`))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var body problem.Problem
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, problem.CodeInvalidBody, body.Code)
+	})
+}
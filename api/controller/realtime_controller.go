@@ -0,0 +1,217 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"todo-app/api/middleware"
+	"todo-app/config"
+	"todo-app/i18n"
+	"todo-app/logging"
+	"todo-app/realtime"
+
+	"github.com/gin-gonic/gin"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/gorilla/websocket"
+)
+
+// maxListLength matches the length limit of a todo's project.
+const maxListLength = 100
+
+// Error codes sent in realtime error messages.
+const (
+	realtimeInvalidMessage       = "invalid_message"
+	realtimeUnknownType          = "unknown_type"
+	realtimeInvalidList          = "invalid_list"
+	realtimeTooManySubscriptions = "too_many_subscriptions"
+)
+
+type RealtimeController struct {
+	hub      *realtime.Hub
+	upgrader websocket.Upgrader
+	cfg      config.Realtime
+	logger   *slog.Logger
+}
+
+func NewRealtimeController(hub *realtime.Hub, cfg config.Realtime, logger *slog.Logger) *RealtimeController {
+	return &RealtimeController{
+		hub: hub,
+		upgrader: websocket.Upgrader{
+			HandshakeTimeout: cfg.WriteTimeout,
+			CheckOrigin:      checkOrigin(cfg.AllowedOrigins),
+		},
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (h *RealtimeController) log(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx, h.logger)
+}
+
+// Connect upgrades to the realtime collaboration WebSocket
+// @Summary Realtime collaboration channel
+// @Description WebSocket of JSON messages. Send {"type":"subscribe","list":"<project>"} or {"type":"unsubscribe","list":"<project>"}.
+// @Description The server sends "event" messages with the TodoEvent and, for updates, a JSON merge patch; "presence" messages listing the users viewing a list; "reset" when events were lost and lists should be reloaded; and "error" messages for rejected client messages.
+// @Description Requires the X-User-ID header set by the authenticating gateway. Clients that fall behind are closed with code 1013 and should reconnect.
+// @Tags todos
+// @Produce application/problem+json
+// @Param X-User-ID header string true "Authenticated user"
+// @Success 101 {object} realtime.Message
+// @Failure 400 {string} string "Not a WebSocket handshake"
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {string} string "Origin not allowed"
+// @Failure 429 {object} problem.Problem "Rate limit exceeded, see Retry-After"
+// @Router /todos/ws [get]
+func (h *RealtimeController) Connect(c *gin.Context) {
+	ctx := c.Request.Context()
+	user := middleware.GetUserID(c)
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already written the error response.
+		h.log(ctx).InfoContext(ctx, "Realtime handshake failed", "error", err)
+		return
+	}
+	defer conn.Close()
+
+	client := h.hub.Connect(user)
+	defer client.Close()
+	h.log(ctx).InfoContext(ctx, "Realtime client connected")
+
+	readDone := make(chan struct{})
+	go func() {
+		defer close(readDone)
+		h.read(ctx, conn, client, i18n.FromContext(ctx))
+	}()
+	h.write(ctx, conn, client)
+	conn.Close()
+	<-readDone
+	h.log(ctx).InfoContext(ctx, "Realtime client disconnected", "reason", closeReason(client.Err()))
+}
+
+// read handles client messages until the connection fails, then closes
+// client so that write returns.
+func (h *RealtimeController) read(ctx context.Context, conn *websocket.Conn, client *realtime.Client, trans ut.Translator) {
+	defer client.Close()
+
+	// Pongs answer the pings sent by write, so a silent connection is dead.
+	timeout := h.cfg.PingInterval + h.cfg.WriteTimeout
+	conn.SetReadLimit(h.cfg.MaxMessageBytes)
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(timeout))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				h.log(ctx).DebugContext(ctx, "Realtime read failed", "error", err)
+			}
+			return
+		}
+		conn.SetReadDeadline(time.Now().Add(timeout))
+
+		var msg realtime.Message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			client.Send(realtimeError(trans, realtimeInvalidMessage))
+			continue
+		}
+		switch msg.Type {
+		case realtime.TypeSubscribe, realtime.TypeUnsubscribe:
+			if msg.List == "" || len(msg.List) > maxListLength {
+				client.Send(realtimeError(trans, realtimeInvalidList, strconv.Itoa(maxListLength)))
+				continue
+			}
+			if msg.Type == realtime.TypeUnsubscribe {
+				client.Unsubscribe(msg.List)
+				continue
+			}
+			if err := client.Subscribe(msg.List); errors.Is(err, realtime.ErrTooManySubscriptions) {
+				client.Send(realtimeError(trans, realtimeTooManySubscriptions, strconv.Itoa(h.cfg.MaxSubscriptions)))
+			}
+		default:
+			client.Send(realtimeError(trans, realtimeUnknownType, strconv.Quote(msg.Type)))
+		}
+	}
+}
+
+// write sends the client's messages and keepalive pings until the client is
+// closed or a write fails.
+func (h *RealtimeController) write(ctx context.Context, conn *websocket.Conn, client *realtime.Client) {
+	ticker := time.NewTicker(h.cfg.PingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case msg, ok := <-client.C:
+			if !ok {
+				code, text := closeCode(client.Err())
+				deadline := time.Now().Add(h.cfg.WriteTimeout)
+				conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), deadline)
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(h.cfg.WriteTimeout))
+			if err := conn.WriteJSON(msg); err != nil {
+				h.log(ctx).DebugContext(ctx, "Realtime write failed", "error", err)
+				return
+			}
+		case <-ticker.C:
+			deadline := time.Now().Add(h.cfg.WriteTimeout)
+			if err := conn.WriteControl(websocket.PingMessage, nil, deadline); err != nil {
+				h.log(ctx).DebugContext(ctx, "Realtime ping failed", "error", err)
+				return
+			}
+		}
+	}
+}
+
+func realtimeError(trans ut.Translator, code string, params ...string) realtime.Message {
+	return realtime.Message{
+		Type:    realtime.TypeError,
+		Code:    code,
+		Message: i18n.T(trans, "realtime."+code, params...),
+	}
+}
+
+// closeCode maps why the hub dropped a client to a WebSocket close status.
+func closeCode(err error) (int, string) {
+	switch {
+	case errors.Is(err, realtime.ErrSlowClient):
+		return websocket.CloseTryAgainLater, "client too slow"
+	case errors.Is(err, realtime.ErrHubClosed):
+		return websocket.CloseGoingAway, "server shutting down"
+	default:
+		return websocket.CloseNormalClosure, ""
+	}
+}
+
+func closeReason(err error) string {
+	if err == nil {
+		return "closed"
+	}
+	return err.Error()
+}
+
+// checkOrigin allows requests without an Origin header, from the service's
+// own host and from the allowed origins. "*" allows every origin.
+func checkOrigin(allowed []string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" || slices.Contains(allowed, "*") {
+			return true
+		}
+		if slices.ContainsFunc(allowed, func(o string) bool { return strings.EqualFold(o, origin) }) {
+			return true
+		}
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+}
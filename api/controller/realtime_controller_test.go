@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"todo-app/api/middleware"
+	"todo-app/config"
+	"todo-app/domain"
+	"todo-app/events"
+	"todo-app/realtime"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dialRealtime(t *testing.T, url, user string) *websocket.Conn {
+	header := http.Header{}
+	header.Set(middleware.UserHeader, user)
+	conn, _, err := websocket.DefaultDialer.Dial(url, header)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return conn
+}
+
+func readMessage(t *testing.T, conn *websocket.Conn) realtime.Message {
+	var msg realtime.Message
+	require.NoError(t, conn.ReadJSON(&msg))
+	return msg
+}
+
+func TestRealtimeController_Connect(t *testing.T) {
+	cfg := config.Default().Realtime
+	cfg.MaxSubscriptions = 1
+	broker := events.NewBroker(10, 10)
+	hub := realtime.NewHub(broker, cfg.SendBuffer, cfg.MaxSubscriptions, slog.Default())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hub.Run(ctx)
+
+	controller := NewRealtimeController(hub, cfg, slog.Default())
+	router := setupRouter()
+	router.GET("/todos/ws", middleware.RequestID(slog.Default()), middleware.RequireUser(), controller.Connect)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/todos/ws"
+
+	t.Run("subscribes and receives events", func(t *testing.T) {
+		alice := dialRealtime(t, url, "alice")
+		require.NoError(t, alice.WriteJSON(realtime.Message{Type: realtime.TypeSubscribe, List: "home"}))
+		assert.Equal(t, []string{"alice"}, readMessage(t, alice).Users)
+
+		bob := dialRealtime(t, url, "bob")
+		require.NoError(t, bob.WriteJSON(realtime.Message{Type: realtime.TypeSubscribe, List: "home"}))
+		assert.Equal(t, []string{"alice", "bob"}, readMessage(t, alice).Users)
+
+		publishTodo(broker, domain.TodoCreated, domain.Todo{Title: "Groceries", Project: "home"})
+
+		msg := readMessage(t, alice)
+		assert.Equal(t, realtime.TypeEvent, msg.Type)
+		assert.Equal(t, "home", msg.List)
+		assert.Equal(t, "Groceries", msg.Event.Todo.Title)
+
+		bob.Close()
+		assert.Equal(t, []string{"alice"}, readMessage(t, alice).Users)
+	})
+
+	t.Run("rejects invalid messages", func(t *testing.T) {
+		conn := dialRealtime(t, url, "alice")
+
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("{")))
+		assert.Equal(t, "invalid_message", readMessage(t, conn).Code)
+
+		require.NoError(t, conn.WriteJSON(realtime.Message{Type: "edit"}))
+		assert.Equal(t, "unknown_type", readMessage(t, conn).Code)
+
+		require.NoError(t, conn.WriteJSON(realtime.Message{Type: realtime.TypeSubscribe}))
+		assert.Equal(t, "invalid_list", readMessage(t, conn).Code)
+
+		require.NoError(t, conn.WriteJSON(realtime.Message{Type: realtime.TypeSubscribe, List: "home"}))
+		readMessage(t, conn)
+		require.NoError(t, conn.WriteJSON(realtime.Message{Type: realtime.TypeSubscribe, List: "work"}))
+		msg := readMessage(t, conn)
+		assert.Equal(t, "too_many_subscriptions", msg.Code)
+		assert.Equal(t, "A connection may follow at most 1 lists.", msg.Message)
+	})
+
+	t.Run("requires a user", func(t *testing.T) {
+		_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+
+		require.Error(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("rejects foreign origins", func(t *testing.T) {
+		header := http.Header{}
+		header.Set(middleware.UserHeader, "alice")
+		header.Set("Origin", "https://evil.example")
+		_, resp, err := websocket.DefaultDialer.Dial(url, header)
+
+		require.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("closes on shutdown", func(t *testing.T) {
+		conn := dialRealtime(t, url, "alice")
+		require.NoError(t, conn.WriteJSON(realtime.Message{Type: realtime.TypeSubscribe, List: "home"}))
+		readMessage(t, conn)
+
+		hub.Close()
+
+		_, _, err := conn.ReadMessage()
+		assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), err)
+	})
+}
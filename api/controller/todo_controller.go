@@ -1,10 +1,15 @@
 package controller
 
 import (
+	"context"
 	"errors"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"todo-app/api/problem"
 	"todo-app/domain"
+	"todo-app/logging"
+	"todo-app/tracing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -22,26 +27,39 @@ func NewTodoController(usecase domain.TodoUsecase, logger *slog.Logger) *TodoCon
 	}
 }
 
+// log returns the request-scoped logger from ctx, falling back to the
+// controller's own logger outside of a request.
+func (h *TodoController) log(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx, h.logger)
+}
+
 // Create creates a new todo
 // @Summary Create a new todo
 // @Description Create a new todo item
 // @Tags todos
 // @Accept json
 // @Produce json
+// @Produce application/problem+json
+// @Param Idempotency-Key header string false "Makes retries safe: the first response is replayed for repeated requests with the same key"
 // @Param todo body domain.Todo true "Todo object"
 // @Success 201 {object} domain.Todo
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 409 {object} problem.Problem "A request with the same Idempotency-Key is still running"
+// @Failure 422 {object} problem.Problem "Idempotency-Key reused with a different body"
+// @Failure 429 {object} problem.Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} problem.Problem
 // @Router /todos [post]
 func (h *TodoController) Create(c *gin.Context) {
+	ctx, span := tracing.Tracer().Start(c.Request.Context(), "TodoController.Create")
+	defer span.End()
+
 	var todo domain.Todo
 	if err := c.ShouldBindJSON(&todo); err != nil {
-		h.logger.Warn("Invalid request body", "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		h.bindError(c, err)
 		return
 	}
 
-	if err := h.usecase.Create(&todo); err != nil {
+	if err := h.usecase.Create(ctx, &todo); err != nil {
 		h.handleError(c, err)
 		return
 	}
@@ -54,30 +72,37 @@ func (h *TodoController) Create(c *gin.Context) {
 // @Tags todos
 // @Accept json
 // @Produce json
+// @Produce application/problem+json
+// @Param Idempotency-Key header string false "Makes retries safe: the first response is replayed for repeated requests with the same key"
 // @Param id path string true "Todo ID"
 // @Param todo body domain.Todo true "Todo object"
 // @Success 200 {object} domain.Todo
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem "A request with the same Idempotency-Key is still running"
+// @Failure 422 {object} problem.Problem "Idempotency-Key reused with a different body"
+// @Failure 429 {object} problem.Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} problem.Problem
 // @Router /todos/{id} [put]
 func (h *TodoController) Update(c *gin.Context) {
+	ctx, span := tracing.Tracer().Start(c.Request.Context(), "TodoController.Update")
+	defer span.End()
+
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		h.logger.Warn("Invalid UUID", "id", c.Param("id"), "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID format"})
+		h.log(ctx).WarnContext(ctx, "Invalid UUID", "id", c.Param("id"), "error", err)
+		problem.Write(c, problem.InvalidID())
 		return
 	}
 
 	var todo domain.Todo
 	if err := c.ShouldBindJSON(&todo); err != nil {
-		h.logger.Warn("Invalid request body", "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		h.bindError(c, err)
 		return
 	}
 	todo.ID = id
 
-	if err := h.usecase.Update(&todo); err != nil {
+	if err := h.usecase.Update(ctx, &todo); err != nil {
 		h.handleError(c, err)
 		return
 	}
@@ -86,20 +111,29 @@ func (h *TodoController) Update(c *gin.Context) {
 
 // List returns a list of todos
 // @Summary List todos
-// @Description Get a list of todos with optional sorting and searching
+// @Description Get a list of todos with optional sorting, searching and filtering. The filter query combines field:value terms such as status:IN_PROGRESS, tag:backend, project:Home and due<2026-11-01 with bare words, "quoted phrases", OR, -negation and parentheses. Syntax errors are reported as invalid_query problems with the position of the error.
 // @Tags todos
 // @Produce json
-// @Param sort_by query string false "Sort by field (title, date, status)"
-// @Param search query string false "Search in title or description"
+// @Produce application/problem+json
+// @Param sort_by query string false "Sort by field (title, date, status, due)"
+// @Param search query string false "Only todos matching this full-text search, see GET /todos/search"
+// @Param q query string false "Only todos matching this filter query, at most 500 characters"
+// @Param tz query string false "IANA time zone in which the dates of q are days, such as Asia/Bangkok; UTC by default"
+// @Param Time-Zone header string false "Time zone used when tz is not given"
 // @Success 200 {array} domain.Todo
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 429 {object} problem.Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} problem.Problem
 // @Router /todos [get]
 func (h *TodoController) List(c *gin.Context) {
-	sortBy := c.Query("sort_by")
-	search := c.Query("search")
+	ctx, span := tracing.Tracer().Start(c.Request.Context(), "TodoController.List")
+	defer span.End()
 
-	todos, err := h.usecase.List(sortBy, search)
+	todos, err := h.usecase.List(ctx, domain.ListOptions{
+		SortBy: c.Query("sort_by"),
+		Search: c.Query("search"),
+		Query:  c.Query("q"),
+	})
 	if err != nil {
 		h.handleError(c, err)
 		return
@@ -107,42 +141,179 @@ func (h *TodoController) List(c *gin.Context) {
 	c.JSON(http.StatusOK, todos)
 }
 
+// Facets counts todos per status, tag and project
+// @Summary Count todos per facet
+// @Description Number of todos per status, tag and project among those matching the filter query, most frequent first, for sidebars. Todos without a project only count towards total.
+// @Tags todos
+// @Produce json
+// @Produce application/problem+json
+// @Param q query string false "Only count todos matching this filter query, see GET /todos"
+// @Param tz query string false "IANA time zone in which the dates of q are days, such as Asia/Bangkok; UTC by default"
+// @Param Time-Zone header string false "Time zone used when tz is not given"
+// @Success 200 {object} domain.Facets
+// @Failure 400 {object} problem.Problem
+// @Failure 429 {object} problem.Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} problem.Problem
+// @Router /todos/facets [get]
+func (h *TodoController) Facets(c *gin.Context) {
+	ctx, span := tracing.Tracer().Start(c.Request.Context(), "TodoController.Facets")
+	defer span.End()
+
+	facets, err := h.usecase.Facets(ctx, c.Query("q"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, facets)
+}
+
+// Search runs a full-text search over todos
+// @Summary Search todos
+// @Description Full-text search over title and description, most relevant first. The query supports "quoted phrases", OR and -excluded words. Highlights are escaped HTML with matched terms wrapped in <mark>.
+// @Tags todos
+// @Produce json
+// @Produce application/problem+json
+// @Param q query string true "Search query, at most 200 characters"
+// @Param limit query int false "Maximum number of results, 1 to 100" default(20)
+// @Success 200 {array} domain.SearchResult
+// @Failure 400 {object} problem.Problem
+// @Failure 429 {object} problem.Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} problem.Problem
+// @Router /todos/search [get]
+func (h *TodoController) Search(c *gin.Context) {
+	ctx, span := tracing.Tracer().Start(c.Request.Context(), "TodoController.Search")
+	defer span.End()
+
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		var err error
+		if limit, err = strconv.Atoi(raw); err != nil {
+			h.log(ctx).WarnContext(ctx, "Invalid search limit", "limit", raw)
+			problem.Write(c, problem.Validation(domain.NewValidationError(domain.FieldViolation{Field: "limit", Rule: "number"})))
+			return
+		}
+	}
+
+	results, err := h.usecase.Search(ctx, c.Query("q"), limit)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// FuzzySearch finds todos by a possibly misspelled title
+// @Summary Fuzzy search todos
+// @Description Typo tolerant search over titles by trigram similarity, best match first. A title matches when it, or a run of its words, is at least threshold similar to the query.
+// @Tags todos
+// @Produce json
+// @Produce application/problem+json
+// @Param q query string true "Search query, at most 200 characters"
+// @Param threshold query number false "Minimum similarity, above 0 up to 1" default(0.3)
+// @Param limit query int false "Maximum number of results, 1 to 100" default(20)
+// @Success 200 {array} domain.FuzzyMatch
+// @Failure 400 {object} problem.Problem
+// @Failure 429 {object} problem.Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} problem.Problem
+// @Router /todos/search/fuzzy [get]
+func (h *TodoController) FuzzySearch(c *gin.Context) {
+	ctx, span := tracing.Tracer().Start(c.Request.Context(), "TodoController.FuzzySearch")
+	defer span.End()
+
+	var violations []domain.FieldViolation
+	threshold := 0.0
+	if raw := c.Query("threshold"); raw != "" {
+		var err error
+		if threshold, err = strconv.ParseFloat(raw, 64); err != nil {
+			h.log(ctx).WarnContext(ctx, "Invalid fuzzy search threshold", "threshold", raw)
+			violations = append(violations, domain.FieldViolation{Field: "threshold", Rule: "numeric"})
+		}
+	}
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		var err error
+		if limit, err = strconv.Atoi(raw); err != nil {
+			h.log(ctx).WarnContext(ctx, "Invalid search limit", "limit", raw)
+			violations = append(violations, domain.FieldViolation{Field: "limit", Rule: "number"})
+		}
+	}
+	if len(violations) > 0 {
+		problem.Write(c, problem.Validation(domain.NewValidationError(violations...)))
+		return
+	}
+
+	matches, err := h.usecase.FuzzySearch(ctx, c.Query("q"), threshold, limit)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, matches)
+}
+
 // Delete removes a todo
 // @Summary Delete a todo
 // @Description Delete a todo item by ID
 // @Tags todos
+// @Produce application/problem+json
 // @Param id path string true "Todo ID"
 // @Success 204
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 429 {object} problem.Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} problem.Problem
 // @Router /todos/{id} [delete]
 func (h *TodoController) Delete(c *gin.Context) {
+	ctx, span := tracing.Tracer().Start(c.Request.Context(), "TodoController.Delete")
+	defer span.End()
+
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		h.logger.Warn("Invalid UUID", "id", c.Param("id"), "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID format"})
+		h.log(ctx).WarnContext(ctx, "Invalid UUID", "id", c.Param("id"), "error", err)
+		problem.Write(c, problem.InvalidID())
 		return
 	}
 
-	if err := h.usecase.Delete(id); err != nil {
+	if err := h.usecase.Delete(ctx, id); err != nil {
 		h.handleError(c, err)
 		return
 	}
 	c.Status(http.StatusNoContent)
 }
 
+func (h *TodoController) bindError(c *gin.Context, err error) {
+	ctx := c.Request.Context()
+	bindError(ctx, c, h.log(ctx), err)
+}
+
+// bindError answers a request whose body could not be decoded. The decoder
+// message is logged but not returned, as it can echo arbitrary input.
+func bindError(ctx context.Context, c *gin.Context, logger *slog.Logger, err error) {
+	logger.WarnContext(ctx, "Invalid request body", "error", err)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		problem.Write(c, problem.BodyTooLarge())
+		return
+	}
+	problem.Write(c, problem.InvalidBody())
+}
+
 func (h *TodoController) handleError(c *gin.Context, err error) {
+	logError(c.Request.Context(), h.log(c.Request.Context()), err)
+	problem.Write(c, problem.FromError(err))
+}
+
+// logError logs err unless it is an expected client error, which the
+// usecase or repository already logged at warn level.
+func logError(ctx context.Context, logger *slog.Logger, err error) {
 	switch {
-	case errors.Is(err, domain.ErrValidationFailed):
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-	case errors.Is(err, domain.ErrNotFound):
-		c.JSON(http.StatusNotFound, gin.H{"error": "todo not found"})
+	case errors.Is(err, domain.ErrValidationFailed),
+		errors.Is(err, domain.ErrNotFound),
+		errors.Is(err, domain.ErrBulkAborted),
+		errors.Is(err, domain.ErrInvalidQuery),
+		errors.Is(err, domain.ErrReadOnly):
 	case errors.Is(err, domain.ErrDatabaseOperation):
-		h.logger.Error("Database error", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		logger.ErrorContext(ctx, "Database error", "error", err)
 	default:
-		h.logger.Error("Unexpected error", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		logger.ErrorContext(ctx, "Unexpected error", "error", err)
 	}
 }
@@ -7,12 +7,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"todo-app/api/problem"
 	"todo-app/domain"
 	"todo-app/domain/mocks"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func setupRouter() *gin.Engine {
@@ -35,7 +37,7 @@ func TestTodoController_Create(t *testing.T) {
 			Status:      "IN_PROGRESS",
 		}
 
-		mockUsecase.On("Create", mock.AnythingOfType("*domain.Todo")).Return(nil).Once()
+		mockUsecase.On("Create", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil).Once()
 
 		body, _ := json.Marshal(todo)
 		req := httptest.NewRequest("POST", "/todos", bytes.NewBuffer(body))
@@ -58,6 +60,29 @@ func TestTodoController_Create(t *testing.T) {
 		router.ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), `"code":"invalid_request_body"`)
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		mockUsecase.On("Create", mock.Anything, mock.AnythingOfType("*domain.Todo")).
+			Return(domain.NewValidationError(domain.FieldViolation{Field: "title", Rule: "required"})).Once()
+
+		req := httptest.NewRequest("POST", "/todos", bytes.NewBufferString(`{"status":"IN_PROGRESS"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+		var body problem.Problem
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, problem.CodeValidation, body.Code)
+		assert.Equal(t, "/problems/validation_error", body.Type)
+		assert.Equal(t, "/todos", body.Instance)
+		assert.Equal(t, []domain.FieldViolation{{Field: "title", Rule: "required", Message: "title is required"}}, body.Errors)
+		mockUsecase.AssertExpectations(t)
 	})
 }
 
@@ -76,7 +101,7 @@ func TestTodoController_Update(t *testing.T) {
 			Status:      "COMPLETED",
 		}
 
-		mockUsecase.On("Update", mock.AnythingOfType("*domain.Todo")).Return(nil).Once()
+		mockUsecase.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil).Once()
 
 		body, _ := json.Marshal(todo)
 		req := httptest.NewRequest("PUT", "/todos/123e4567-e89b-12d3-a456-426614174000", bytes.NewBuffer(body))
@@ -129,7 +154,7 @@ func TestTodoController_List(t *testing.T) {
 			},
 		}
 
-		mockUsecase.On("List", "", "").Return(todos, nil).Once()
+		mockUsecase.On("List", mock.Anything, domain.ListOptions{}).Return(todos, nil).Once()
 
 		req := httptest.NewRequest("GET", "/todos", nil)
 		w := httptest.NewRecorder()
@@ -142,7 +167,7 @@ func TestTodoController_List(t *testing.T) {
 
 	t.Run("with sort and search", func(t *testing.T) {
 		todos := []domain.Todo{}
-		mockUsecase.On("List", "title", "test").Return(todos, nil).Once()
+		mockUsecase.On("List", mock.Anything, domain.ListOptions{SortBy: "title", Search: "test"}).Return(todos, nil).Once()
 
 		req := httptest.NewRequest("GET", "/todos?sort_by=title&search=test", nil)
 		w := httptest.NewRecorder()
@@ -152,6 +177,116 @@ func TestTodoController_List(t *testing.T) {
 		assert.Equal(t, http.StatusOK, w.Code)
 		mockUsecase.AssertExpectations(t)
 	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		err := &domain.QueryError{Position: 5, Code: domain.QueryUnterminatedQuote}
+		mockUsecase.On("List", mock.Anything, domain.ListOptions{Query: `tag:"api`}).Return([]domain.Todo(nil), err).Once()
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/todos?q=tag:%22api", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var body problem.Problem
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, problem.CodeInvalidQuery, body.Code)
+		assert.Equal(t, 5, body.Position)
+		assert.Equal(t, "At position 5: the quote is never closed.", body.Detail)
+		mockUsecase.AssertExpectations(t)
+	})
+}
+
+func TestTodoController_Facets(t *testing.T) {
+	mockUsecase := new(mocks.MockTodoUsecase)
+	controller := NewTodoController(mockUsecase, slog.Default())
+	router := setupRouter()
+
+	router.GET("/todos/facets", controller.Facets)
+
+	facets := &domain.Facets{
+		Total:   2,
+		Status:  []domain.FacetCount{{Value: "IN_PROGRESS", Count: 2}},
+		Tag:     []domain.FacetCount{{Value: "backend", Count: 2}},
+		Project: []domain.FacetCount{},
+	}
+	mockUsecase.On("Facets", mock.Anything, "tag:backend").Return(facets, nil).Once()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/todos/facets?q=tag:backend", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"total":2,"status":[{"value":"IN_PROGRESS","count":2}],"tag":[{"value":"backend","count":2}],"project":[]}`, w.Body.String())
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestTodoController_Search(t *testing.T) {
+	mockUsecase := new(mocks.MockTodoUsecase)
+	controller := NewTodoController(mockUsecase, slog.Default())
+	router := setupRouter()
+
+	router.GET("/todos/search", controller.Search)
+
+	t.Run("success", func(t *testing.T) {
+		results := []domain.SearchResult{{
+			Todo:       domain.Todo{Title: "Weekly report", Status: "IN_PROGRESS"},
+			Rank:       0.6,
+			Highlights: domain.SearchHighlights{Title: "Weekly <mark>report</mark>"},
+		}}
+		mockUsecase.On("Search", mock.Anything, "report", 5).Return(results, nil).Once()
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/todos/search?q=report&limit=5", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var body []domain.SearchResult
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, results, body)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/todos/search?q=report&limit=ten", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var body problem.Problem
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, []domain.FieldViolation{{Field: "limit", Rule: "number", Message: "limit must be a whole number"}}, body.Errors)
+	})
+}
+
+func TestTodoController_FuzzySearch(t *testing.T) {
+	mockUsecase := new(mocks.MockTodoUsecase)
+	controller := NewTodoController(mockUsecase, slog.Default())
+	router := setupRouter()
+
+	router.GET("/todos/search/fuzzy", controller.FuzzySearch)
+
+	t.Run("success", func(t *testing.T) {
+		matches := []domain.FuzzyMatch{{Todo: domain.Todo{Title: "Buy groceries", Status: "IN_PROGRESS"}, Score: 0.58}}
+		mockUsecase.On("FuzzySearch", mock.Anything, "grocerys", 0.5, 0).Return(matches, nil).Once()
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/todos/search/fuzzy?q=grocerys&threshold=0.5", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var body []domain.FuzzyMatch
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, matches, body)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("invalid threshold and limit", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/todos/search/fuzzy?q=grocerys&threshold=high&limit=ten", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var body problem.Problem
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, []domain.FieldViolation{
+			{Field: "threshold", Rule: "numeric", Message: "threshold must be a number"},
+			{Field: "limit", Rule: "number", Message: "limit must be a whole number"},
+		}, body.Errors)
+	})
 }
 
 func TestTodoController_Delete(t *testing.T) {
@@ -163,7 +298,7 @@ func TestTodoController_Delete(t *testing.T) {
 	router.DELETE("/todos/:id", controller.Delete)
 
 	t.Run("success", func(t *testing.T) {
-		mockUsecase.On("Delete", mock.AnythingOfType("uuid.UUID")).Return(nil).Once()
+		mockUsecase.On("Delete", mock.Anything, mock.AnythingOfType("uuid.UUID")).Return(nil).Once()
 
 		req := httptest.NewRequest("DELETE", "/todos/123e4567-e89b-12d3-a456-426614174000", nil)
 		w := httptest.NewRecorder()
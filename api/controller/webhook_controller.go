@@ -0,0 +1,268 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"todo-app/api/middleware"
+	"todo-app/api/problem"
+	"todo-app/domain"
+	"todo-app/logging"
+	"todo-app/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// WebhookRequest is the body of POST /webhooks and PUT /webhooks/{id}.
+type WebhookRequest struct {
+	URL string `json:"url" example:"https://example.com/hooks/todos"`
+	// Secret signs the deliveries. POST generates one when it is empty and
+	// PUT keeps the current one.
+	Secret     string                 `json:"secret,omitempty" example:"a-long-shared-secret"`
+	EventTypes []domain.TodoEventType `json:"event_types" example:"todo.created,todo.updated"`
+	// Enabled defaults to true. Enabling a webhook that was disabled after
+	// repeated failures resumes its pending deliveries.
+	Enabled *bool `json:"enabled,omitempty" example:"true"`
+}
+
+func (r WebhookRequest) webhook(owner string) *domain.Webhook {
+	enabled := r.Enabled == nil || *r.Enabled
+	return &domain.Webhook{URL: r.URL, Secret: r.Secret, EventTypes: r.EventTypes, Enabled: enabled, Owner: owner}
+}
+
+type WebhookController struct {
+	usecase domain.WebhookUsecase
+	logger  *slog.Logger
+}
+
+func NewWebhookController(usecase domain.WebhookUsecase, logger *slog.Logger) *WebhookController {
+	return &WebhookController{
+		usecase: usecase,
+		logger:  logger,
+	}
+}
+
+func (h *WebhookController) log(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx, h.logger)
+}
+
+// Create registers a webhook
+// @Summary Register a webhook
+// @Description Subscribe a URL to todo events for the calling user. The response is the only one that includes the secret; retries with the same Idempotency-Key replay it without the secret.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Produce application/problem+json
+// @Param X-User-ID header string true "Authenticated user"
+// @Param webhook body WebhookRequest true "Webhook"
+// @Success 201 {object} domain.Webhook
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 429 {object} problem.Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} problem.Problem
+// @Router /webhooks [post]
+func (h *WebhookController) Create(c *gin.Context) {
+	ctx, span := tracing.Tracer().Start(c.Request.Context(), "WebhookController.Create")
+	defer span.End()
+
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(ctx, c, h.log(ctx), err)
+		return
+	}
+
+	webhook := req.webhook(middleware.GetUserID(c))
+	if err := h.usecase.Create(ctx, webhook); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	// The secret is shown once: retries with the same Idempotency-Key
+	// replay the webhook without it, so it is not kept with the key.
+	replay, err := json.Marshal(redact(*webhook))
+	if err != nil {
+		h.log(ctx).ErrorContext(ctx, "Failed to encode webhook for idempotent replay", "error", err, "webhook_id", webhook.ID)
+	}
+	middleware.SetReplayBody(c, replay)
+	c.JSON(http.StatusCreated, webhook)
+}
+
+// Update replaces a webhook
+// @Summary Update a webhook
+// @Description Replace the URL, event types and enabled state of a webhook, and its secret when one is given
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Produce application/problem+json
+// @Param X-User-ID header string true "Authenticated user"
+// @Param id path string true "Webhook ID"
+// @Param webhook body WebhookRequest true "Webhook"
+// @Success 200 {object} domain.Webhook
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 429 {object} problem.Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} problem.Problem
+// @Router /webhooks/{id} [put]
+func (h *WebhookController) Update(c *gin.Context) {
+	ctx, span := tracing.Tracer().Start(c.Request.Context(), "WebhookController.Update")
+	defer span.End()
+
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(ctx, c, h.log(ctx), err)
+		return
+	}
+
+	webhook := req.webhook(middleware.GetUserID(c))
+	webhook.ID = id
+	if err := h.usecase.Update(ctx, webhook); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, redact(*webhook))
+}
+
+// Get returns a webhook
+// @Summary Get a webhook
+// @Tags webhooks
+// @Produce json
+// @Produce application/problem+json
+// @Param X-User-ID header string true "Authenticated user"
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} domain.Webhook
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 429 {object} problem.Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} problem.Problem
+// @Router /webhooks/{id} [get]
+func (h *WebhookController) Get(c *gin.Context) {
+	ctx, span := tracing.Tracer().Start(c.Request.Context(), "WebhookController.Get")
+	defer span.End()
+
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	webhook, err := h.usecase.Get(ctx, middleware.GetUserID(c), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, redact(*webhook))
+}
+
+// List returns the webhooks of the calling user
+// @Summary List webhooks
+// @Description The webhooks of the calling user, oldest first
+// @Tags webhooks
+// @Produce json
+// @Produce application/problem+json
+// @Param X-User-ID header string true "Authenticated user"
+// @Success 200 {array} domain.Webhook
+// @Failure 401 {object} problem.Problem
+// @Failure 429 {object} problem.Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} problem.Problem
+// @Router /webhooks [get]
+func (h *WebhookController) List(c *gin.Context) {
+	ctx, span := tracing.Tracer().Start(c.Request.Context(), "WebhookController.List")
+	defer span.End()
+
+	webhooks, err := h.usecase.List(ctx, middleware.GetUserID(c))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	for i := range webhooks {
+		webhooks[i] = redact(webhooks[i])
+	}
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// Delete removes a webhook
+// @Summary Delete a webhook
+// @Description Delete a webhook together with its queued deliveries
+// @Tags webhooks
+// @Produce application/problem+json
+// @Param X-User-ID header string true "Authenticated user"
+// @Param id path string true "Webhook ID"
+// @Success 204
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 429 {object} problem.Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} problem.Problem
+// @Router /webhooks/{id} [delete]
+func (h *WebhookController) Delete(c *gin.Context) {
+	ctx, span := tracing.Tracer().Start(c.Request.Context(), "WebhookController.Delete")
+	defer span.End()
+
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	if err := h.usecase.Delete(ctx, middleware.GetUserID(c), id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Deliveries returns the recent deliveries of a webhook
+// @Summary List webhook deliveries
+// @Description The 50 most recent deliveries of a webhook, newest first, with every attempt made
+// @Tags webhooks
+// @Produce json
+// @Produce application/problem+json
+// @Param X-User-ID header string true "Authenticated user"
+// @Param id path string true "Webhook ID"
+// @Success 200 {array} domain.WebhookDelivery
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 429 {object} problem.Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} problem.Problem
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookController) Deliveries(c *gin.Context) {
+	ctx, span := tracing.Tracer().Start(c.Request.Context(), "WebhookController.Deliveries")
+	defer span.End()
+
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	deliveries, err := h.usecase.Deliveries(ctx, middleware.GetUserID(c), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
+
+func (h *WebhookController) parseID(c *gin.Context) (uuid.UUID, bool) {
+	ctx := c.Request.Context()
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.log(ctx).WarnContext(ctx, "Invalid UUID", "id", c.Param("id"), "error", err)
+		problem.Write(c, problem.InvalidID())
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (h *WebhookController) handleError(c *gin.Context, err error) {
+	logError(c.Request.Context(), h.log(c.Request.Context()), err)
+	problem.Write(c, problem.FromError(err))
+}
+
+// redact hides the secret, which is only shown when a webhook is created.
+func redact(webhook domain.Webhook) domain.Webhook {
+	webhook.Secret = ""
+	return webhook
+}
@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"todo-app/api/middleware"
+	"todo-app/domain"
+	"todo-app/domain/mocks"
+	"todo-app/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookController(t *testing.T) {
+	mockUsecase := new(mocks.MockWebhookUsecase)
+	controller := NewWebhookController(mockUsecase, slog.Default())
+	router := setupRouter()
+	webhooks := router.Group("/webhooks", middleware.RequestID(slog.Default()), middleware.RequireUser())
+	webhooks.POST("", controller.Create)
+	webhooks.GET("", controller.List)
+	webhooks.GET("/:id", controller.Get)
+	webhooks.PUT("/:id", controller.Update)
+	webhooks.GET("/:id/deliveries", controller.Deliveries)
+
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middleware.UserHeader, "alice")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	id := uuid.New()
+
+	t.Run("create returns the secret and defaults to enabled", func(t *testing.T) {
+		mockUsecase.On("Create", mock.Anything, mock.MatchedBy(func(w *domain.Webhook) bool {
+			return w.Enabled && w.URL == "https://example.com/hook" && w.Owner == "alice"
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.Webhook).Secret = "generated-secret-value"
+		}).Return(nil).Once()
+
+		w := do(http.MethodPost, "/webhooks", `{"url":"https://example.com/hook","event_types":["todo.created"]}`)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"secret":"generated-secret-value"`)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("reads redact the secret", func(t *testing.T) {
+		webhook := domain.Webhook{ID: id, URL: "https://example.com/hook", Secret: "0123456789abcdef", Enabled: true}
+		mockUsecase.On("Get", mock.Anything, "alice", id).Return(&webhook, nil).Once()
+		mockUsecase.On("List", mock.Anything, "alice").Return([]domain.Webhook{webhook}, nil).Once()
+
+		w := do(http.MethodGet, "/webhooks/"+id.String(), "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "secret")
+
+		w = do(http.MethodGet, "/webhooks", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "secret")
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("update can disable", func(t *testing.T) {
+		mockUsecase.On("Update", mock.Anything, mock.MatchedBy(func(w *domain.Webhook) bool {
+			return w.ID == id && !w.Enabled && w.Owner == "alice"
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.Webhook).Secret = "0123456789abcdef"
+		}).Return(nil).Once()
+
+		w := do(http.MethodPut, "/webhooks/"+id.String(), `{"url":"https://example.com/hook","event_types":["todo.created"],"enabled":false}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "secret")
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("deliveries", func(t *testing.T) {
+		mockUsecase.On("Deliveries", mock.Anything, "alice", id).Return([]domain.WebhookDelivery{{
+			WebhookID: id,
+			Status:    domain.DeliveryPending,
+			Payload:   json.RawMessage(`{"type":"todo.created"}`),
+			Attempts:  []domain.WebhookAttempt{{Number: 1, StatusCode: 503}},
+		}}, nil).Once()
+
+		w := do(http.MethodGet, "/webhooks/"+id.String()+"/deliveries", "")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var deliveries []domain.WebhookDelivery
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &deliveries))
+		require.Len(t, deliveries, 1)
+		assert.JSONEq(t, `{"type":"todo.created"}`, string(deliveries[0].Payload))
+		assert.Equal(t, 503, deliveries[0].Attempts[0].StatusCode)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockUsecase.On("Get", mock.Anything, "alice", id).Return(nil, domain.ErrNotFound).Once()
+
+		w := do(http.MethodGet, "/webhooks/"+id.String(), "")
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "The requested resource was not found.")
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		w := do(http.MethodGet, "/webhooks/nope", "")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"invalid_id"`)
+	})
+}
+
+func TestWebhookController_CreateIdempotency(t *testing.T) {
+	mockUsecase := new(mocks.MockWebhookUsecase)
+	controller := NewWebhookController(mockUsecase, slog.Default())
+	store := repository.NewMemoryIdempotencyRepo()
+	router := setupRouter()
+	router.POST("/webhooks", middleware.RequestID(slog.Default()), middleware.Idempotency(store, time.Hour, slog.Default()), controller.Create)
+	mockUsecase.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.Webhook).Secret = "generated-secret-value"
+	}).Return(nil).Once()
+
+	do := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewBufferString(`{"url":"https://example.com/hook","event_types":["todo.created"]}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middleware.UserHeader, "alice")
+		req.Header.Set(middleware.IdempotencyKeyHeader, "hook-1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := do()
+	retry := do()
+
+	assert.Contains(t, first.Body.String(), "generated-secret-value")
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get(middleware.IdempotentReplayedHeader))
+	assert.Contains(t, retry.Body.String(), "https://example.com/hook")
+	assert.NotContains(t, retry.Body.String(), "secret")
+	stored, err := store.Find(context.Background(), "alice", "hook-1")
+	require.NoError(t, err)
+	assert.NotContains(t, string(stored.Body), "generated-secret-value")
+	mockUsecase.AssertExpectations(t)
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+	"todo-app/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccessLog writes one structured line per request through the
+// request-scoped logger. Server errors log at error level, client errors at
+// warn. Requests to skipPaths, such as probes and metrics scrapes, are not
+// logged.
+func AccessLog(fallback *slog.Logger, skipPaths []string) gin.HandlerFunc {
+	skip := make(map[string]struct{}, len(skipPaths))
+	for _, p := range skipPaths {
+		skip[p] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		if _, ok := skip[c.Request.URL.Path]; ok {
+			return
+		}
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.Int("status", status),
+			slog.String("path", c.Request.URL.Path),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.String("client_ip", c.ClientIP()),
+			slog.String("user_agent", c.Request.UserAgent()),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+
+		ctx := c.Request.Context()
+		logging.FromContext(ctx, fallback).LogAttrs(ctx, level, "Request handled", attrs...)
+	}
+}
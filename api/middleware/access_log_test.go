@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	router := gin.New()
+	router.Use(RequestID(logger), AccessLog(logger, []string{"/healthz"}))
+	router.GET("/todos/:id", func(c *gin.Context) { c.String(http.StatusNotFound, "missing") })
+	router.GET("/healthz", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest("GET", "/todos/1", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "Request handled", record["msg"])
+	assert.Equal(t, "WARN", record["level"])
+	assert.Equal(t, "req-1", record["request_id"])
+	assert.Equal(t, "/todos/:id", record["route"])
+	assert.Equal(t, "/todos/1", record["path"])
+	assert.EqualValues(t, http.StatusNotFound, record["status"])
+	assert.EqualValues(t, len("missing"), record["bytes"])
+	assert.Contains(t, record, "latency_ms")
+
+	buf.Reset()
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/healthz", nil))
+	assert.Empty(t, buf.String(), "skipped paths are not logged")
+}
@@ -0,0 +1,203 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"todo-app/api/problem"
+	"todo-app/domain"
+	"todo-app/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+	anonymousScope          = "anonymous"
+	replayBodyKey           = "idempotent_replay_body"
+)
+
+// Idempotency makes POST, PUT and PATCH requests that carry an
+// Idempotency-Key header safe to retry. The first request reserves the key
+// together with a fingerprint of its method, path, query and body, and its
+// response is stored for ttl. Retries of the same request get the stored
+// response replayed; reusing the key with a different request is rejected
+// with 422, and a retry that
+// arrives while the first request is still running gets 409.
+//
+// Keys are scoped to the X-User-ID caller. Server errors and panics release
+// the key so the request can be retried. Handlers whose response must not be
+// stored call SetReplayBody to store a different body.
+func Idempotency(store domain.IdempotencyRepository, ttl time.Duration, fallback *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			c.Next()
+			return
+		}
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if violation := checkIdempotencyKey(key); violation != nil {
+			problem.Write(c, problem.Validation(domain.NewValidationError(*violation)))
+			return
+		}
+
+		ctx := c.Request.Context()
+		logger := logging.FromContext(ctx, fallback)
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				problem.Write(c, problem.BodyTooLarge())
+				return
+			}
+			problem.Write(c, problem.InvalidBody())
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		scope := GetUserID(c)
+		if scope == "" {
+			scope = anonymousScope
+		}
+		rec := &domain.IdempotencyRecord{
+			Scope:       scope,
+			Key:         key,
+			Fingerprint: fingerprint(c.Request, body),
+			ExpiresAt:   time.Now().Add(ttl),
+		}
+
+		err = store.Reserve(ctx, rec)
+		if errors.Is(err, domain.ErrIdempotencyKeyExists) {
+			replay(c, store, rec, logger)
+			return
+		}
+		if err != nil {
+			problem.Write(c, problem.Internal())
+			return
+		}
+
+		// The stored response must survive a client that hangs up.
+		storeCtx := context.WithoutCancel(ctx)
+		defer func() {
+			if r := recover(); r != nil {
+				release(storeCtx, store, rec, logger)
+				panic(r)
+			}
+		}()
+
+		recorder := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			release(storeCtx, store, rec, logger)
+			return
+		}
+		rec.Status = c.Writer.Status()
+		rec.ContentType = c.Writer.Header().Get("Content-Type")
+		rec.Body = recorder.body.Bytes()
+		if body, ok := c.Get(replayBodyKey); ok {
+			rec.Body = body.([]byte)
+		}
+		if err := store.Complete(storeCtx, rec); err != nil {
+			logger.ErrorContext(ctx, "Failed to store idempotent response", "idempotency_key", key, "error", err)
+		}
+	}
+}
+
+// SetReplayBody makes Idempotency store body instead of the response body,
+// so retries replay body. It is used for responses that hold secrets.
+func SetReplayBody(c *gin.Context, body []byte) {
+	c.Set(replayBodyKey, body)
+}
+
+// replay answers a request whose key is already reserved.
+func replay(c *gin.Context, store domain.IdempotencyRepository, rec *domain.IdempotencyRecord, logger *slog.Logger) {
+	ctx := c.Request.Context()
+	stored, err := store.Find(ctx, rec.Scope, rec.Key)
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		// Released or expired since the reservation attempt.
+		problem.Write(c, problem.IdempotencyInProgress())
+		return
+	case err != nil:
+		problem.Write(c, problem.Internal())
+		return
+	case stored.Fingerprint != rec.Fingerprint:
+		logger.WarnContext(ctx, "Idempotency key reused with a different request", "idempotency_key", rec.Key)
+		problem.Write(c, problem.IdempotencyKeyReused())
+		return
+	case !stored.Completed():
+		problem.Write(c, problem.IdempotencyInProgress())
+		return
+	}
+
+	logger.InfoContext(ctx, "Replaying idempotent response", "idempotency_key", rec.Key, "status", stored.Status)
+	c.Header(IdempotentReplayedHeader, "true")
+	c.Data(stored.Status, stored.ContentType, stored.Body)
+	c.Abort()
+}
+
+func release(ctx context.Context, store domain.IdempotencyRepository, rec *domain.IdempotencyRecord, logger *slog.Logger) {
+	if err := store.Release(ctx, rec.Scope, rec.Key); err != nil {
+		logger.ErrorContext(ctx, "Failed to release idempotency key", "idempotency_key", rec.Key, "error", err)
+	}
+}
+
+func checkIdempotencyKey(key string) *domain.FieldViolation {
+	if len(key) > maxIdempotencyKeyLength {
+		return &domain.FieldViolation{Field: IdempotencyKeyHeader, Rule: "max", Param: strconv.Itoa(maxIdempotencyKeyLength)}
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return &domain.FieldViolation{Field: IdempotencyKeyHeader, Rule: "printascii"}
+		}
+	}
+	return nil
+}
+
+// fingerprint identifies the request a key was first used with.
+func fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method)
+	h.Write([]byte{0})
+	io.WriteString(h, r.URL.Path)
+	h.Write([]byte{0})
+	// The query selects behaviour such as dry runs, so it is part of the
+	// request.
+	io.WriteString(h, r.URL.RawQuery)
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recordingWriter keeps a copy of the response body for storage.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
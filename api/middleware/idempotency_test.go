@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"todo-app/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var calls atomic.Int32
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	store := repository.NewMemoryIdempotencyRepo()
+	router := gin.New()
+	router.Use(RequestID(logger), Idempotency(store, time.Hour, logger))
+	router.POST("/todos", func(c *gin.Context) {
+		n := calls.Add(1)
+		c.JSON(http.StatusCreated, gin.H{"call": n})
+	})
+	router.PUT("/todos/:id", func(c *gin.Context) {
+		n := calls.Add(1)
+		c.JSON(http.StatusOK, gin.H{"call": n})
+	})
+	router.POST("/secrets", func(c *gin.Context) {
+		calls.Add(1)
+		SetReplayBody(c, []byte(`{"id":"1"}`))
+		c.JSON(http.StatusCreated, gin.H{"id": "1", "secret": "s3cr3t"})
+	})
+	router.POST("/fail", func(c *gin.Context) {
+		calls.Add(1)
+		c.Status(http.StatusInternalServerError)
+	})
+
+	do := func(method, path, key, user, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		if user != "" {
+			req.Header.Set(UserHeader, user)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	send := func(path, key, user, body string) *httptest.ResponseRecorder {
+		return do(http.MethodPost, path, key, user, body)
+	}
+
+	t.Run("replays the stored response", func(t *testing.T) {
+		calls.Store(0)
+		first := send("/todos", "create-1", "alice", `{"title":"a"}`)
+		retry := send("/todos", "create-1", "alice", `{"title":"a"}`)
+
+		assert.Equal(t, http.StatusCreated, first.Code)
+		assert.Equal(t, http.StatusCreated, retry.Code)
+		assert.JSONEq(t, first.Body.String(), retry.Body.String())
+		assert.Equal(t, "true", retry.Header().Get(IdempotentReplayedHeader))
+		assert.Empty(t, first.Header().Get(IdempotentReplayedHeader))
+		assert.EqualValues(t, 1, calls.Load())
+	})
+
+	t.Run("rejects key reuse with a different body", func(t *testing.T) {
+		send("/todos", "create-2", "alice", `{"title":"a"}`)
+		w := send("/todos", "create-2", "alice", `{"title":"b"}`)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"idempotency_key_reused"`)
+	})
+
+	t.Run("rejects key reuse with a different query", func(t *testing.T) {
+		calls.Store(0)
+		send("/todos?dry_run=true", "import-1", "alice", `{"title":"a"}`)
+		w := send("/todos", "import-1", "alice", `{"title":"a"}`)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.EqualValues(t, 1, calls.Load())
+	})
+
+	t.Run("replays updates", func(t *testing.T) {
+		calls.Store(0)
+		first := do(http.MethodPut, "/todos/1", "update-1", "alice", `{"title":"a"}`)
+		retry := do(http.MethodPut, "/todos/1", "update-1", "alice", `{"title":"a"}`)
+
+		assert.Equal(t, http.StatusOK, retry.Code)
+		assert.JSONEq(t, first.Body.String(), retry.Body.String())
+		assert.Equal(t, "true", retry.Header().Get(IdempotentReplayedHeader))
+		assert.EqualValues(t, 1, calls.Load())
+	})
+
+	t.Run("scopes keys per user", func(t *testing.T) {
+		calls.Store(0)
+		send("/todos", "create-3", "alice", `{}`)
+		send("/todos", "create-3", "bob", `{}`)
+
+		assert.EqualValues(t, 2, calls.Load())
+	})
+
+	t.Run("stores the replay body instead of the response", func(t *testing.T) {
+		calls.Store(0)
+		first := send("/secrets", "secret-1", "alice", `{}`)
+		retry := send("/secrets", "secret-1", "alice", `{}`)
+
+		assert.Contains(t, first.Body.String(), "s3cr3t")
+		assert.Equal(t, http.StatusCreated, retry.Code)
+		assert.JSONEq(t, `{"id":"1"}`, retry.Body.String())
+		stored, err := store.Find(context.Background(), "alice", "secret-1")
+		require.NoError(t, err)
+		assert.NotContains(t, string(stored.Body), "s3cr3t")
+		assert.EqualValues(t, 1, calls.Load())
+	})
+
+	t.Run("server errors release the key", func(t *testing.T) {
+		calls.Store(0)
+		send("/fail", "fail-1", "alice", `{}`)
+		send("/fail", "fail-1", "alice", `{}`)
+
+		assert.EqualValues(t, 2, calls.Load())
+	})
+
+	t.Run("requests without a key are not deduplicated", func(t *testing.T) {
+		calls.Store(0)
+		send("/todos", "", "alice", `{}`)
+		send("/todos", "", "alice", `{}`)
+
+		assert.EqualValues(t, 2, calls.Load())
+	})
+
+	t.Run("rejects malformed keys", func(t *testing.T) {
+		w := send("/todos", strings.Repeat("k", 256), "alice", `{}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"field":"Idempotency-Key"`)
+	})
+}
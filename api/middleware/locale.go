@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"todo-app/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Locale picks the translator matching the Accept-Language header, falling
+// back to English, and stores it in the request context for error responses.
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		trans := i18n.Match(c.GetHeader("Accept-Language"))
+		c.Header("Vary", "Accept-Language")
+		c.Request = c.Request.WithContext(i18n.NewContext(c.Request.Context(), trans))
+		c.Next()
+	}
+}
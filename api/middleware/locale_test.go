@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-app/api/problem"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocale(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Locale())
+	router.GET("/todos/:id", func(c *gin.Context) { problem.Write(c, problem.NotFound()) })
+
+	for _, tt := range []struct {
+		acceptLanguage, contentLanguage, title string
+	}{
+		{"th-TH,th;q=0.9", "th", `"title":"ไม่พบข้อมูล"`},
+		{"en-US", "en", `"title":"Not found"`},
+		{"ja", "en", `"title":"Not found"`},
+	} {
+		req := httptest.NewRequest("GET", "/todos/1", nil)
+		req.Header.Set("Accept-Language", tt.acceptLanguage)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, tt.contentLanguage, w.Header().Get("Content-Language"))
+		assert.Equal(t, "Accept-Language", w.Header().Get("Vary"))
+		assert.Contains(t, w.Body.String(), tt.title)
+	}
+}
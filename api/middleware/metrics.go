@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+	"todo-app/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels requests that hit no registered route, so scanners
+// probing random paths cannot blow up label cardinality.
+const unmatchedRoute = "unmatched"
+
+// Metrics records request count, latency and in-flight gauge per route
+// template and status code.
+func Metrics(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		done := m.TrackInFlight()
+		defer done()
+
+		start := time.Now()
+		c.Next()
+
+		m.ObserveHTTP(c.Request.Method, routeOf(c), strconv.Itoa(c.Writer.Status()), time.Since(start))
+	}
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-app/metrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := metrics.New()
+	router := gin.New()
+	router.Use(Metrics(m))
+	router.GET("/todos/:id", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	for _, path := range []string{"/todos/1", "/todos/2", "/nope"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Contains(t, w.Body.String(), `todo_http_requests_total{method="GET",route="/todos/:id",status="204"} 2`)
+	assert.Contains(t, w.Body.String(), `todo_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, w.Body.String(), "todo_http_requests_in_flight 0")
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"todo-app/api/problem"
+	"todo-app/logging"
+	"todo-app/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	APIKeyHeader = "X-API-Key"
+
+	rateLimitGroupRead  = "read"
+	rateLimitGroupWrite = "write"
+)
+
+// RateLimits are the limits of the read (GET, HEAD, OPTIONS) and write
+// route groups. Each client has one bucket per group.
+type RateLimits struct {
+	Read  ratelimit.Limit
+	Write ratelimit.Limit
+	// TrustIdentity identifies clients by X-API-Key and X-User-ID, which
+	// must then be set by an authenticating gateway. Otherwise clients are
+	// identified by IP alone.
+	TrustIdentity bool
+}
+
+// RateLimit throttles clients with a token bucket per client and route
+// group, identified by X-API-Key, then X-User-ID, then client IP when
+// limits.TrustIdentity is set, and by client IP otherwise. Every
+// response carries RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset
+// and RateLimit-Policy; throttled requests get 429 with Retry-After.
+//
+// If the store fails the request is let through: an unavailable shared
+// store must not take the API down with it.
+func RateLimit(store ratelimit.Store, limits RateLimits, fallback *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		group, limit := rateLimitGroupWrite, limits.Write
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			group, limit = rateLimitGroupRead, limits.Read
+		}
+
+		ctx := c.Request.Context()
+		client := rateLimitClient(c, limits.TrustIdentity)
+		res, err := store.Take(ctx, group+"|"+client, limit, time.Now())
+		if err != nil {
+			logging.FromContext(ctx, fallback).WarnContext(ctx, "Rate limit store unavailable, allowing request", "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(int(res.Reset.Seconds())))
+		c.Header("RateLimit-Policy", limit.Policy())
+		if !res.Allowed {
+			c.Header("Retry-After", strconv.Itoa(int(res.RetryAfter.Seconds())))
+			logging.FromContext(ctx, fallback).InfoContext(ctx, "Rate limit exceeded",
+				"client", client, "group", group, "retry_after", res.RetryAfter.String())
+			problem.Write(c, problem.RateLimited())
+			return
+		}
+		c.Next()
+	}
+}
+
+// rateLimitClient identifies the caller. Unless trustIdentity is set the
+// identity headers are unverified, and a client could send new ones with
+// every request, so only the IP counts. API keys are hashed so they never
+// end up in logs or store keys.
+func rateLimitClient(c *gin.Context, trustIdentity bool) string {
+	if !trustIdentity {
+		return "ip:" + c.ClientIP()
+	}
+	if key := c.GetHeader(APIKeyHeader); key != "" {
+		sum := sha256.Sum256([]byte(key))
+		return "key:" + hex.EncodeToString(sum[:8])
+	}
+	if user := GetUserID(c); user != "" {
+		return "user:" + user
+	}
+	return "ip:" + c.ClientIP()
+}
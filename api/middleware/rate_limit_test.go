@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"todo-app/ratelimit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, ratelimit.Limit, time.Time) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("store down")
+}
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	newRouter := func(store ratelimit.Store, trustIdentity bool) *gin.Engine {
+		router := gin.New()
+		router.Use(RequestID(logger), RateLimit(store, RateLimits{
+			Read:          ratelimit.Limit{PerMinute: 60, Burst: 2},
+			Write:         ratelimit.Limit{PerMinute: 60, Burst: 1},
+			TrustIdentity: trustIdentity,
+		}, logger))
+		router.GET("/todos", func(c *gin.Context) { c.Status(http.StatusOK) })
+		router.POST("/todos", func(c *gin.Context) { c.Status(http.StatusCreated) })
+		return router
+	}
+	send := func(router *gin.Engine, method string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/todos", nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("throttles per group", func(t *testing.T) {
+		router := newRouter(ratelimit.NewMemoryStore(), false)
+		alice := map[string]string{UserHeader: "alice"}
+
+		first := send(router, "POST", alice)
+		second := send(router, "POST", alice)
+		read := send(router, "GET", alice)
+
+		assert.Equal(t, http.StatusCreated, first.Code)
+		assert.Equal(t, "1", first.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "0", first.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "1", first.Header().Get("RateLimit-Reset"))
+		assert.Equal(t, "60;w=60;burst=1", first.Header().Get("RateLimit-Policy"))
+		assert.Equal(t, http.StatusTooManyRequests, second.Code)
+		assert.Equal(t, "1", second.Header().Get("Retry-After"))
+		assert.Contains(t, second.Body.String(), `"code":"rate_limited"`)
+		assert.Equal(t, http.StatusOK, read.Code, "reads have their own bucket")
+	})
+
+	t.Run("keys clients by IP", func(t *testing.T) {
+		router := newRouter(ratelimit.NewMemoryStore(), false)
+
+		assert.Equal(t, http.StatusCreated, send(router, "POST", map[string]string{APIKeyHeader: "k1", UserHeader: "alice"}).Code)
+		assert.Equal(t, http.StatusTooManyRequests, send(router, "POST", map[string]string{APIKeyHeader: "k2", UserHeader: "bob"}).Code,
+			"rotating unverified identity headers does not reset the limit")
+		assert.Equal(t, http.StatusTooManyRequests, send(router, "POST", map[string]string{UserHeader: "carol"}).Code)
+	})
+
+	t.Run("keys clients by trusted API key, user and IP", func(t *testing.T) {
+		router := newRouter(ratelimit.NewMemoryStore(), true)
+
+		assert.Equal(t, http.StatusCreated, send(router, "POST", map[string]string{APIKeyHeader: "k1", UserHeader: "alice"}).Code)
+		assert.Equal(t, http.StatusCreated, send(router, "POST", map[string]string{UserHeader: "alice"}).Code)
+		assert.Equal(t, http.StatusCreated, send(router, "POST", nil).Code)
+		assert.Equal(t, http.StatusTooManyRequests, send(router, "POST", map[string]string{APIKeyHeader: "k1", UserHeader: "bob"}).Code,
+			"the API key takes precedence over the user")
+	})
+
+	t.Run("fails open when the store errors", func(t *testing.T) {
+		router := newRouter(failingStore{}, false)
+
+		w := send(router, "POST", nil)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+	})
+}
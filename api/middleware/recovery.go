@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"todo-app/api/problem"
+	"todo-app/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery turns panics into a 500 problem response and logs the panic value and
+// stack through the request-scoped logger.
+func Recovery(fallback *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				if r == http.ErrAbortHandler {
+					panic(r)
+				}
+				ctx := c.Request.Context()
+				logging.FromContext(ctx, fallback).ErrorContext(ctx, "Panic recovered",
+					"panic", r,
+					"stack", string(debug.Stack()),
+				)
+				problem.Write(c, problem.Internal())
+			}
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-app/api/problem"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecovery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	router := gin.New()
+	router.Use(RequestID(logger), Recovery(logger))
+	router.GET("/panic", func(c *gin.Context) { panic("boom") })
+
+	req := httptest.NewRequest("GET", "/panic", nil)
+	req.Header.Set(RequestIDHeader, "req-panic")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), `"code":"internal_error"`)
+	assert.NotContains(t, w.Body.String(), "boom", "the panic value is not exposed")
+	assert.Contains(t, buf.String(), `"msg":"Panic recovered"`)
+	assert.Contains(t, buf.String(), `"request_id":"req-panic"`)
+	assert.Contains(t, buf.String(), `"panic":"boom"`)
+}
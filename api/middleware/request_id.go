@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"log/slog"
+	"todo-app/api/problem"
+	"todo-app/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	UserHeader      = "X-User-ID"
+
+	requestIDKey = "request_id"
+	userKey      = "user_id"
+
+	maxRequestIDLength = 128
+)
+
+// RequestID accepts a well-formed X-Request-ID from the client or generates
+// one, echoes it in the response and stores a logger enriched with the
+// request ID, method, route and user in the request context.
+//
+// The user comes from X-User-ID, which is expected to be set by the
+// authenticating gateway in front of the service.
+func RequestID(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		c.Set(requestIDKey, id)
+		c.Header(RequestIDHeader, id)
+
+		attrs := []any{
+			slog.String("request_id", id),
+			slog.String("method", c.Request.Method),
+			slog.String("route", routeOf(c)),
+		}
+		if user := c.GetHeader(UserHeader); user != "" {
+			c.Set(userKey, user)
+			attrs = append(attrs, slog.String("user", user))
+		}
+
+		ctx := logging.NewContext(c.Request.Context(), logger.With(attrs...))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// GetRequestID returns the request ID assigned by RequestID.
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// GetUserID returns the caller's user ID, or "" for anonymous requests.
+func GetUserID(c *gin.Context) string {
+	return c.GetString(userKey)
+}
+
+// RequireUser rejects anonymous requests with 401. It must run after
+// RequestID.
+func RequireUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if GetUserID(c) == "" {
+			problem.Write(c, problem.Unauthorized())
+			return
+		}
+		c.Next()
+	}
+}
+
+// validRequestID rejects empty, oversized or non-printable IDs so clients
+// cannot inject arbitrary content into logs and response headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func routeOf(c *gin.Context) string {
+	if route := c.FullPath(); route != "" {
+		return route
+	}
+	return unmatchedRoute
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-app/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	router := gin.New()
+	router.Use(RequestID(logger))
+	router.GET("/todos/:id", func(c *gin.Context) {
+		ctx := c.Request.Context()
+		logging.FromContext(ctx, nil).InfoContext(ctx, "handler")
+		c.String(http.StatusOK, GetRequestID(c))
+	})
+
+	t.Run("echoes client ID", func(t *testing.T) {
+		buf.Reset()
+		req := httptest.NewRequest("GET", "/todos/1", nil)
+		req.Header.Set(RequestIDHeader, "abc-123")
+		req.Header.Set(UserHeader, "alice")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, "abc-123", w.Header().Get(RequestIDHeader))
+		assert.Equal(t, "abc-123", w.Body.String())
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		assert.Equal(t, "abc-123", record["request_id"])
+		assert.Equal(t, "GET", record["method"])
+		assert.Equal(t, "/todos/:id", record["route"])
+		assert.Equal(t, "alice", record["user"])
+	})
+
+	t.Run("generates missing ID", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/todos/1", nil))
+
+		assert.Len(t, w.Header().Get(RequestIDHeader), 36)
+	})
+
+	t.Run("replaces invalid ID", func(t *testing.T) {
+		for _, id := range []string{"has space", strings.Repeat("x", 129), "tab\tid"} {
+			req := httptest.NewRequest("GET", "/todos/1", nil)
+			req.Header.Set(RequestIDHeader, id)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.NotEqual(t, id, w.Header().Get(RequestIDHeader))
+			assert.Len(t, w.Header().Get(RequestIDHeader), 36)
+		}
+	})
+}
+
+func TestRequireUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID(slog.Default()), RequireUser())
+	router.GET("/todos/ws", func(c *gin.Context) {
+		c.String(http.StatusOK, GetUserID(c))
+	})
+
+	t.Run("rejects anonymous requests", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/todos/ws", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"unauthorized"`)
+	})
+
+	t.Run("passes authenticated requests", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/todos/ws", nil)
+		req.Header.Set(UserHeader, "alice")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "alice", w.Body.String())
+	})
+}
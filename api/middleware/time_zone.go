@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"todo-app/api/problem"
+	"todo-app/domain"
+	"todo-app/timezone"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TimeZoneHeader names the client's IANA time zone, as does the tz query
+// parameter, which takes precedence.
+const TimeZoneHeader = "Time-Zone"
+
+// TimeZone stores the client's time zone in the request context, so dates
+// such as "today" in filter queries follow the client's calendar. Requests
+// naming neither default to UTC; unknown zones are rejected.
+func TimeZone() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer.Header().Add("Vary", TimeZoneHeader)
+		field, name := "tz", c.Query("tz")
+		if name == "" {
+			field, name = TimeZoneHeader, c.GetHeader(TimeZoneHeader)
+		}
+		if name == "" {
+			c.Next()
+			return
+		}
+		loc, err := timezone.Load(name)
+		if err != nil {
+			problem.Write(c, problem.Validation(domain.NewValidationError(domain.FieldViolation{Field: field, Rule: "timezone", Param: name})))
+			return
+		}
+		c.Request = c.Request.WithContext(timezone.NewContext(c.Request.Context(), loc))
+		c.Next()
+	}
+}
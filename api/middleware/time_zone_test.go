@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-app/timezone"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeZone(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(TimeZone())
+	router.GET("/todos", func(c *gin.Context) {
+		c.String(http.StatusOK, timezone.FromContext(c.Request.Context()).String())
+	})
+
+	for _, tt := range []struct {
+		name, query, header string
+		status              int
+		body                string
+	}{
+		{"default", "", "", http.StatusOK, "UTC"},
+		{"header", "", "Asia/Bangkok", http.StatusOK, "Asia/Bangkok"},
+		{"query wins", "?tz=America/New_York", "Asia/Bangkok", http.StatusOK, "America/New_York"},
+		{"unknown", "?tz=Mars/Olympus", "", http.StatusBadRequest, `"field":"tz","rule":"timezone"`},
+		{"server zone", "", "Local", http.StatusBadRequest, `"field":"Time-Zone","rule":"timezone"`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/todos"+tt.query, nil)
+			if tt.header != "" {
+				req.Header.Set(TimeZoneHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Contains(t, w.Body.String(), tt.body)
+			assert.Equal(t, TimeZoneHeader, w.Header().Get("Vary"))
+		})
+	}
+}
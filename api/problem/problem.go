@@ -0,0 +1,216 @@
+// Package problem writes RFC 7807 application/problem+json error responses.
+package problem
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"todo-app/domain"
+	"todo-app/i18n"
+
+	"github.com/gin-gonic/gin"
+	ut "github.com/go-playground/universal-translator"
+)
+
+// ContentType is the media type of every error response.
+const ContentType = "application/problem+json"
+
+// typeBase prefixes the stable problem type URIs. Clients should branch on
+// Type or Code, never on Title or Detail.
+const typeBase = "/problems/"
+
+const requestIDHeader = "X-Request-ID"
+
+// Codes identifying each kind of problem. They are part of the API contract
+// and must not change once released.
+const (
+	CodeValidation   = "validation_error"
+	CodeInvalidBody  = "invalid_request_body"
+	CodeBodyTooLarge = "request_body_too_large"
+	CodeInvalidID    = "invalid_id"
+	CodeNotFound     = "not_found"
+	CodeInternal     = "internal_error"
+	CodeBulkAborted  = "bulk_aborted"
+	CodeTooManyOps   = "too_many_operations"
+	CodeKeyReused    = "idempotency_key_reused"
+	CodeInProgress   = "idempotency_request_in_progress"
+	CodeRateLimited  = "rate_limited"
+	CodeUnauthorized = "unauthorized"
+	CodeInvalidQuery = "invalid_query"
+	CodeReadOnly     = "read_only"
+)
+
+// Problem is an RFC 7807 problem details object extended with a machine
+// readable code, the request ID and, for validation failures, the
+// per-field violations. Title, Detail and violation messages are localized
+// from the i18n catalogs when written.
+type Problem struct {
+	Type      string                  `json:"type" example:"/problems/validation_error"`
+	Title     string                  `json:"title" example:"Validation failed"`
+	Status    int                     `json:"status" example:"400"`
+	Detail    string                  `json:"detail,omitempty" example:"The request contains invalid fields."`
+	Instance  string                  `json:"instance,omitempty" example:"/todos"`
+	Code      string                  `json:"code" example:"validation_error"`
+	RequestID string                  `json:"request_id,omitempty" example:"6f1c2a7e-3b0d-4d5e-9a8f-2c1b0e9d7a64"`
+	Errors    []domain.FieldViolation `json:"errors,omitempty"`
+	// Position is the 1-based character offset of the error in an invalid
+	// filter query.
+	Position int `json:"position,omitempty" example:"12"`
+
+	// detailKey is the catalog key of the detail when it is not
+	// "problem.<code>.detail".
+	detailKey string
+	// params fill the placeholders of the localized detail.
+	params []string
+}
+
+// New returns a problem of the given status and code.
+func New(status int, code string) *Problem {
+	return &Problem{
+		Type:   typeBase + code,
+		Status: status,
+		Code:   code,
+	}
+}
+
+// Validation returns a 400 problem listing the violations of err.
+func Validation(err *domain.ValidationError) *Problem {
+	p := New(http.StatusBadRequest, CodeValidation)
+	p.Errors = err.Violations
+	return p
+}
+
+// InvalidBody returns a 400 problem for a body that could not be decoded.
+func InvalidBody() *Problem {
+	return New(http.StatusBadRequest, CodeInvalidBody)
+}
+
+// BodyTooLarge returns a 413 problem for a body over the configured limit.
+func BodyTooLarge() *Problem {
+	return New(http.StatusRequestEntityTooLarge, CodeBodyTooLarge)
+}
+
+// InvalidID returns a 400 problem for a malformed resource ID.
+func InvalidID() *Problem {
+	p := New(http.StatusBadRequest, CodeInvalidID)
+	p.Errors = []domain.FieldViolation{{Field: "id", Rule: "uuid"}}
+	return p
+}
+
+// NotFound returns a 404 problem.
+func NotFound() *Problem {
+	return New(http.StatusNotFound, CodeNotFound)
+}
+
+// Internal returns a 500 problem. It never carries the underlying error.
+func Internal() *Problem {
+	return New(http.StatusInternalServerError, CodeInternal)
+}
+
+// BulkAborted returns a 424 problem for a bulk operation that was rolled
+// back because another operation in the same atomic batch failed.
+func BulkAborted() *Problem {
+	return New(http.StatusFailedDependency, CodeBulkAborted)
+}
+
+// TooManyOperations returns a 413 problem for a bulk request over max
+// operations.
+func TooManyOperations(max int) *Problem {
+	p := New(http.StatusRequestEntityTooLarge, CodeTooManyOps)
+	p.params = []string{strconv.Itoa(max)}
+	return p
+}
+
+// IdempotencyKeyReused returns a 422 problem for an Idempotency-Key sent
+// again with a different request.
+func IdempotencyKeyReused() *Problem {
+	return New(http.StatusUnprocessableEntity, CodeKeyReused)
+}
+
+// IdempotencyInProgress returns a 409 problem for a retry that arrives while
+// the original request is still running.
+func IdempotencyInProgress() *Problem {
+	return New(http.StatusConflict, CodeInProgress)
+}
+
+// RateLimited returns a 429 problem for a throttled client.
+func RateLimited() *Problem {
+	return New(http.StatusTooManyRequests, CodeRateLimited)
+}
+
+// Unauthorized returns a 401 problem for a request without an authenticated
+// user.
+func Unauthorized() *Problem {
+	return New(http.StatusUnauthorized, CodeUnauthorized)
+}
+
+// ReadOnly returns a 403 problem for a change to a built-in resource.
+func ReadOnly() *Problem {
+	return New(http.StatusForbidden, CodeReadOnly)
+}
+
+// InvalidQuery returns a 400 problem for a filter query that could not be
+// parsed, explaining what is wrong at which position.
+func InvalidQuery(err *domain.QueryError) *Problem {
+	p := New(http.StatusBadRequest, CodeInvalidQuery)
+	p.Position = err.Position
+	p.detailKey = "query." + err.Code
+	p.params = []string{strconv.Itoa(err.Position), err.Param}
+	return p
+}
+
+// FromError maps a usecase error to its problem. Errors that are not part of
+// the domain contract become an internal error.
+func FromError(err error) *Problem {
+	var validationErr *domain.ValidationError
+	var queryErr *domain.QueryError
+	switch {
+	case errors.As(err, &validationErr):
+		return Validation(validationErr)
+	case errors.As(err, &queryErr):
+		return InvalidQuery(queryErr)
+	case errors.Is(err, domain.ErrValidationFailed):
+		return New(http.StatusBadRequest, CodeValidation)
+	case errors.Is(err, domain.ErrNotFound):
+		return NotFound()
+	case errors.Is(err, domain.ErrBulkAborted):
+		return BulkAborted()
+	case errors.Is(err, domain.ErrReadOnly):
+		return ReadOnly()
+	default:
+		return Internal()
+	}
+}
+
+// Localize fills in the title, detail and violation messages in the language
+// of trans.
+func (p *Problem) Localize(trans ut.Translator) {
+	if p.Title == "" {
+		p.Title = i18n.T(trans, "problem."+p.Code+".title")
+	}
+	if p.Detail == "" {
+		key := p.detailKey
+		if key == "" {
+			key = "problem." + p.Code + ".detail"
+		}
+		p.Detail = i18n.T(trans, key, p.params...)
+	}
+	p.Errors = i18n.Translate(trans, p.Errors)
+}
+
+// Write localizes p for the request's language, fills in the request path
+// and the request ID echoed by middleware.RequestID, sends it and aborts the
+// handler chain.
+func Write(c *gin.Context, p *Problem) {
+	trans := i18n.FromContext(c.Request.Context())
+	p.Localize(trans)
+	if p.Instance == "" {
+		p.Instance = c.Request.URL.Path
+	}
+	if p.RequestID == "" {
+		p.RequestID = c.Writer.Header().Get(requestIDHeader)
+	}
+	c.Header("Content-Type", ContentType)
+	c.Header("Content-Language", trans.Locale())
+	c.AbortWithStatusJSON(p.Status, p)
+}
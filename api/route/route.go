@@ -2,25 +2,156 @@ package route
 
 import (
 	"log/slog"
+	"time"
 	"todo-app/api/controller"
+	"todo-app/api/middleware"
+	"todo-app/config"
+	"todo-app/domain"
+	"todo-app/events"
+	"todo-app/health"
+	"todo-app/metrics"
+	"todo-app/ratelimit"
+	"todo-app/realtime"
 	"todo-app/repository"
 	"todo-app/usecase"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-func Setup(gin *gin.Engine, db *gorm.DB, logger *slog.Logger) {
-	NewTodoRoter(gin, db, logger)
+// Deps are the shared services the routers are built from. Metrics is nil
+// when metrics are disabled.
+type Deps struct {
+	Config         *config.Config
+	Backend        *repository.Backend
+	Health         *health.Registry
+	Metrics        *metrics.Metrics
+	Events         *events.Broker
+	Realtime       *realtime.Hub
+	Bus            domain.EventBus
+	RateLimitStore ratelimit.Store
+	Logger         *slog.Logger
 }
 
-func NewTodoRoter(gin *gin.Engine, db *gorm.DB, logger *slog.Logger) {
-	repo := repository.NewTodoRepo(db, logger)
-	usecase := usecase.NewTodoUsecase(repo, logger)
+func Setup(gin *gin.Engine, deps Deps) {
+	// Tracing, access logging and metrics record the response once the
+	// handlers return, so they go outside Recovery to see the 500 it writes
+	// for a panic.
+	gin.Use(
+		middleware.RequestID(deps.Logger),
+		middleware.Locale(),
+		otelgin.Middleware(deps.Config.Tracing.ServiceName),
+		middleware.AccessLog(deps.Logger, deps.Config.Log.AccessSkipPaths),
+	)
+	if deps.Metrics != nil {
+		gin.Use(middleware.Metrics(deps.Metrics))
+	}
+	gin.Use(middleware.Recovery(deps.Logger))
+	if deps.Metrics != nil {
+		gin.GET(deps.Config.Metrics.Path, metricsHandler(deps.Metrics))
+	}
+
+	NewHealthRouter(gin, deps.Health, deps.Logger)
+
+	if rl := deps.Config.RateLimit; rl.Enabled {
+		gin.Use(middleware.RateLimit(deps.RateLimitStore, middleware.RateLimits{
+			Read:          ratelimit.Limit{PerMinute: rl.ReadPerMinute, Burst: rl.ReadBurst},
+			Write:         ratelimit.Limit{PerMinute: rl.WritePerMinute, Burst: rl.WriteBurst},
+			TrustIdentity: rl.TrustIdentityHeaders,
+		}, deps.Logger))
+	}
+	gin.Use(
+		middleware.TimeZone(),
+		middleware.MaxBodyBytes(deps.Config.Limits.MaxBodyBytes),
+		middleware.Idempotency(deps.Backend.Idempotency, deps.Config.Idempotency.TTL, deps.Logger),
+	)
+
+	repo := deps.Backend.Todos
+	if deps.Metrics != nil {
+		repo = deps.Metrics.InstrumentTodoRepository(repo)
+	}
+	todos := NewTodoRoter(gin, repo, deps.Bus, deps.Metrics, deps.Config.Limits.MaxBulkOperations, deps.Config.Server.WriteTimeout, deps.Logger)
+	NewFilterRouter(gin, deps.Backend.Filters, todos, deps.Logger)
+	NewEventRouter(gin, deps.Events, deps.Config.Events.HeartbeatInterval, deps.Logger)
+	NewRealtimeRouter(gin, deps.Realtime, deps.Config.Realtime, deps.Logger)
+	NewWebhookRouter(gin, deps.Backend.Webhooks, deps.Logger)
+}
+
+func metricsHandler(m *metrics.Metrics) gin.HandlerFunc {
+	return gin.WrapH(m.Handler())
+}
+
+func NewHealthRouter(gin *gin.Engine, registry *health.Registry, logger *slog.Logger) {
+	hc := controller.NewHealthController(registry, logger)
+
+	gin.GET("/healthz", hc.Liveness)
+	gin.GET("/readyz", hc.Readiness)
+}
+
+// NewTodoRoter registers the todo routes and returns their usecase, which
+// other routers run todo queries through. writeTimeout bounds each write of
+// an export.
+func NewTodoRoter(gin *gin.Engine, repo domain.TodoRepository, bus domain.EventBus, m *metrics.Metrics, maxBulkOperations int, writeTimeout time.Duration, logger *slog.Logger) domain.TodoUsecase {
+	usecase := usecase.NewTodoUsecase(repo, bus, logger)
+	if m != nil {
+		usecase = m.InstrumentTodoUsecase(usecase)
+	}
 	tc := controller.NewTodoController(usecase, logger)
 
 	gin.POST("/todos", tc.Create)
 	gin.PUT("/todos/:id", tc.Update)
 	gin.GET("/todos", tc.List)
+	gin.GET("/todos/search", tc.Search)
+	gin.GET("/todos/search/fuzzy", tc.FuzzySearch)
+	gin.GET("/todos/facets", tc.Facets)
 	gin.DELETE("/todos/:id", tc.Delete)
+
+	ec := controller.NewExportController(usecase, writeTimeout, logger)
+	gin.GET("/todos/export", ec.Export)
+	ic := controller.NewImportController(usecase, logger)
+	gin.POST("/todos/import", ic.Import)
+
+	bc := controller.NewBulkController(usecase, maxBulkOperations, logger)
+	gin.POST("/todos/bulk", bc.Bulk)
+	return usecase
+}
+
+// NewFilterRouter registers the saved filter routes. Filters belong to the
+// caller, so every route requires a user.
+func NewFilterRouter(gin *gin.Engine, repo domain.SavedFilterRepository, todos domain.TodoUsecase, logger *slog.Logger) {
+	fc := controller.NewFilterController(usecase.NewSavedFilterUsecase(repo, todos, logger), logger)
+
+	filters := gin.Group("/filters", middleware.RequireUser())
+	filters.POST("", fc.Create)
+	filters.GET("", fc.List)
+	filters.GET("/:id", fc.Get)
+	filters.PUT("/:id", fc.Update)
+	filters.DELETE("/:id", fc.Delete)
+	filters.GET("/:id/todos", fc.Todos)
+}
+
+func NewEventRouter(gin *gin.Engine, broker *events.Broker, heartbeat time.Duration, logger *slog.Logger) {
+	ec := controller.NewEventController(broker, heartbeat, logger)
+
+	gin.GET("/todos/events", ec.Stream)
+}
+
+func NewRealtimeRouter(gin *gin.Engine, hub *realtime.Hub, cfg config.Realtime, logger *slog.Logger) {
+	rc := controller.NewRealtimeController(hub, cfg, logger)
+
+	gin.GET("/todos/ws", middleware.RequireUser(), rc.Connect)
+}
+
+// NewWebhookRouter registers the webhook routes. A webhook receives every
+// todo event, so only identified callers may manage them.
+func NewWebhookRouter(gin *gin.Engine, repo domain.WebhookRepository, logger *slog.Logger) {
+	wc := controller.NewWebhookController(usecase.NewWebhookUsecase(repo, logger), logger)
+
+	webhooks := gin.Group("/webhooks", middleware.RequireUser())
+	webhooks.POST("", wc.Create)
+	webhooks.GET("", wc.List)
+	webhooks.GET("/:id", wc.Get)
+	webhooks.PUT("/:id", wc.Update)
+	webhooks.DELETE("/:id", wc.Delete)
+	webhooks.GET("/:id/deliveries", wc.Deliveries)
 }
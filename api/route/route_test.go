@@ -0,0 +1,102 @@
+package route
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-app/config"
+	"todo-app/eventbus"
+	"todo-app/events"
+	"todo-app/health"
+	"todo-app/metrics"
+	"todo-app/ratelimit"
+	"todo-app/realtime"
+	"todo-app/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newEngine sets up every route over the in-memory backend. m may be nil.
+func newEngine(t *testing.T, cfg *config.Config, m *metrics.Metrics, logger *slog.Logger) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	cfg.Database.Driver = config.DriverMemory
+	backend, err := repository.Open(cfg.Database, logger)
+	require.NoError(t, err)
+	broker := events.NewBroker(cfg.Events.ReplayBufferSize, cfg.Events.SubscriberBuffer)
+	t.Cleanup(broker.Close)
+
+	engine := gin.New()
+	Setup(engine, Deps{
+		Config:         cfg,
+		Backend:        backend,
+		Health:         health.NewRegistry(cfg.Health.CheckTimeout),
+		Metrics:        m,
+		Events:         broker,
+		Realtime:       realtime.NewHub(broker, cfg.Realtime.SendBuffer, cfg.Realtime.MaxSubscriptions, logger),
+		Bus:            eventbus.New(cfg.EventBus.AsyncBuffer, logger),
+		RateLimitStore: ratelimit.NewMemoryStore(),
+		Logger:         logger,
+	})
+	return engine
+}
+
+func TestWebhooksRequireUser(t *testing.T) {
+	engine := newEngine(t, config.Default(), nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	for _, tc := range []struct{ method, path string }{
+		{http.MethodPost, "/webhooks"},
+		{http.MethodGet, "/webhooks"},
+		{http.MethodGet, "/webhooks/4b0e5a52-3f4c-4f39-9d5e-1c2b3a4d5e6f"},
+		{http.MethodPut, "/webhooks/4b0e5a52-3f4c-4f39-9d5e-1c2b3a4d5e6f"},
+		{http.MethodDelete, "/webhooks/4b0e5a52-3f4c-4f39-9d5e-1c2b3a4d5e6f"},
+		{http.MethodGet, "/webhooks/4b0e5a52-3f4c-4f39-9d5e-1c2b3a4d5e6f/deliveries"},
+	} {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, strings.NewReader(`{"url":"https://example.com/hook"}`)))
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		})
+	}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/webhooks", nil)
+	req.Header.Set("X-User-ID", "alice")
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestPanicsAreLoggedAndCounted(t *testing.T) {
+	var logs bytes.Buffer
+	m := metrics.New()
+	engine := newEngine(t, config.Default(), m, slog.New(slog.NewJSONHandler(&logs, nil)))
+	engine.GET("/boom", func(*gin.Context) { panic("boom") })
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/boom", nil))
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+
+	var accessLines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		if record["msg"] == "Request handled" {
+			accessLines = append(accessLines, record)
+		}
+	}
+	require.Len(t, accessLines, 1)
+	assert.EqualValues(t, http.StatusInternalServerError, accessLines[0]["status"])
+	assert.Equal(t, "ERROR", accessLines[0]["level"])
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, w.Body.String(), `todo_http_requests_total{method="GET",route="/boom",status="500"} 1`)
+}
@@ -0,0 +1,368 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Config is the effective application configuration. Every leaf field carries
+// a `config` key used in config files and an optional `env` variable name.
+// Fields tagged `secret:"true"` are redacted when the configuration is printed.
+type Config struct {
+	App         App         `config:"app"`
+	Log         Log         `config:"log"`
+	Server      Server      `config:"server"`
+	Database    Database    `config:"database"`
+	Limits      Limits      `config:"limits"`
+	Health      Health      `config:"health"`
+	Idempotency Idempotency `config:"idempotency"`
+	RateLimit   RateLimit   `config:"rate_limit"`
+	Events      Events      `config:"events"`
+	Realtime    Realtime    `config:"realtime"`
+	Webhooks    Webhooks    `config:"webhooks"`
+	Outbox      Outbox      `config:"outbox"`
+	EventBus    EventBus    `config:"event_bus"`
+	Metrics     Metrics     `config:"metrics"`
+	Tracing     Tracing     `config:"tracing"`
+
+	sources map[string]string
+}
+
+type App struct {
+	Env string `config:"env" env:"APP_ENV" validate:"oneof=development production test"`
+}
+
+type Log struct {
+	Level  string `config:"level" env:"LOG_LEVEL" validate:"oneof=debug info warn error"`
+	Format string `config:"format" env:"LOG_FORMAT" validate:"oneof=json text"`
+	// AccessSkipPaths are request paths left out of the access log.
+	AccessSkipPaths []string `config:"access_skip_paths" env:"LOG_ACCESS_SKIP_PATHS"`
+}
+
+type Server struct {
+	Port              int           `config:"port" env:"APP_PORT" validate:"min=1,max=65535"`
+	ReadHeaderTimeout time.Duration `config:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT" validate:"min=100ms,max=5m"`
+	ReadTimeout       time.Duration `config:"read_timeout" env:"SERVER_READ_TIMEOUT" validate:"min=0,max=1h"`
+	WriteTimeout      time.Duration `config:"write_timeout" env:"SERVER_WRITE_TIMEOUT" validate:"min=0,max=1h"`
+	IdleTimeout       time.Duration `config:"idle_timeout" env:"SERVER_IDLE_TIMEOUT" validate:"min=0,max=1h"`
+	// ShutdownDelay is how long the server keeps serving after readiness
+	// starts failing, so load balancers stop routing to it first.
+	ShutdownDelay   time.Duration `config:"shutdown_delay" env:"SERVER_SHUTDOWN_DELAY" validate:"min=0,max=5m"`
+	ShutdownTimeout time.Duration `config:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT" validate:"min=1s,max=10m"`
+	// WorkerShutdownTimeout is how long background workers, such as the
+	// outbox relay, may take to stop once requests have drained.
+	WorkerShutdownTimeout time.Duration `config:"worker_shutdown_timeout" env:"SERVER_WORKER_SHUTDOWN_TIMEOUT" validate:"min=1s,max=10m"`
+	// TrustedProxies are the proxy IPs or CIDRs whose X-Forwarded-For is
+	// believed when resolving the client IP. Empty trusts no proxy.
+	TrustedProxies []string `config:"trusted_proxies" env:"SERVER_TRUSTED_PROXIES"`
+}
+
+// Database drivers selectable with database.driver.
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+	DriverMemory   = "memory"
+)
+
+type Database struct {
+	Driver          string        `config:"driver" env:"DB_DRIVER" validate:"oneof=postgres sqlite memory"`
+	Host            string        `config:"host" env:"DB_HOST" validate:"required_if=Driver postgres"`
+	Port            int           `config:"port" env:"DB_PORT" validate:"min=1,max=65535"`
+	User            string        `config:"user" env:"DB_USER" validate:"required_if=Driver postgres"`
+	Password        string        `config:"password" env:"DB_PASSWORD" secret:"true"`
+	Name            string        `config:"name" env:"DB_NAME" validate:"required_if=Driver postgres"`
+	SSLMode         string        `config:"sslmode" env:"DB_SSLMODE" validate:"oneof=disable allow prefer require verify-ca verify-full"`
+	ConnectTimeout  time.Duration `config:"connect_timeout" env:"DB_CONNECT_TIMEOUT" validate:"min=1s,max=5m"`
+	MaxOpenConns    int           `config:"max_open_conns" env:"DB_MAX_OPEN_CONNS" validate:"min=1,max=1000"`
+	MaxIdleConns    int           `config:"max_idle_conns" env:"DB_MAX_IDLE_CONNS" validate:"min=0,ltefield=MaxOpenConns"`
+	ConnMaxLifetime time.Duration `config:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" validate:"min=0"`
+	SQLitePath      string        `config:"sqlite_path" env:"DB_SQLITE_PATH" validate:"required_if=Driver sqlite"`
+}
+
+type Limits struct {
+	MaxBodyBytes int64 `config:"max_body_bytes" env:"LIMIT_MAX_BODY_BYTES" validate:"min=1024,max=104857600"`
+	// MaxBulkOperations caps the operations in one POST /todos/bulk request.
+	MaxBulkOperations int `config:"max_bulk_operations" env:"LIMIT_MAX_BULK_OPERATIONS" validate:"min=1,max=10000"`
+}
+
+type Health struct {
+	CheckTimeout time.Duration `config:"check_timeout" env:"HEALTH_CHECK_TIMEOUT" validate:"min=100ms,max=1m"`
+}
+
+// RateLimit sets token buckets per client for the read and write route
+// groups: PerMinute is the sustained rate and Burst the bucket size.
+type RateLimit struct {
+	Enabled        bool `config:"enabled" env:"RATE_LIMIT_ENABLED"`
+	ReadPerMinute  int  `config:"read_per_minute" env:"RATE_LIMIT_READ_PER_MINUTE" validate:"min=1"`
+	ReadBurst      int  `config:"read_burst" env:"RATE_LIMIT_READ_BURST" validate:"min=1"`
+	WritePerMinute int  `config:"write_per_minute" env:"RATE_LIMIT_WRITE_PER_MINUTE" validate:"min=1"`
+	WriteBurst     int  `config:"write_burst" env:"RATE_LIMIT_WRITE_BURST" validate:"min=1"`
+	// TrustIdentityHeaders keys buckets on X-API-Key and X-User-ID. Only
+	// set it when a gateway authenticates every request and overwrites
+	// those headers; otherwise clients could rotate them for fresh buckets.
+	TrustIdentityHeaders bool `config:"trust_identity_headers" env:"RATE_LIMIT_TRUST_IDENTITY_HEADERS"`
+}
+
+type Idempotency struct {
+	// TTL is how long a stored response can be replayed.
+	TTL             time.Duration `config:"ttl" env:"IDEMPOTENCY_TTL" validate:"min=1m,max=720h"`
+	CleanupInterval time.Duration `config:"cleanup_interval" env:"IDEMPOTENCY_CLEANUP_INTERVAL" validate:"min=1s"`
+}
+
+// Events configures the todo change stream served at GET /todos/events.
+type Events struct {
+	// ReplayBufferSize is how many recent events a reconnecting client can
+	// resume from with Last-Event-ID.
+	ReplayBufferSize int `config:"replay_buffer_size" env:"EVENTS_REPLAY_BUFFER_SIZE" validate:"min=0,max=100000"`
+	// SubscriberBuffer is how many events a client may fall behind before
+	// it is disconnected.
+	SubscriberBuffer  int           `config:"subscriber_buffer" env:"EVENTS_SUBSCRIBER_BUFFER" validate:"min=1,max=10000"`
+	HeartbeatInterval time.Duration `config:"heartbeat_interval" env:"EVENTS_HEARTBEAT_INTERVAL" validate:"min=1s,max=5m"`
+}
+
+// Realtime configures the collaboration WebSocket served at GET /todos/ws.
+type Realtime struct {
+	// AllowedOrigins are the browser origins allowed to connect besides the
+	// service's own. Use "*" to allow any origin.
+	AllowedOrigins []string `config:"allowed_origins" env:"REALTIME_ALLOWED_ORIGINS"`
+	// SendBuffer is how many messages a client may fall behind before it is
+	// disconnected.
+	SendBuffer       int           `config:"send_buffer" env:"REALTIME_SEND_BUFFER" validate:"min=1,max=10000"`
+	MaxSubscriptions int           `config:"max_subscriptions" env:"REALTIME_MAX_SUBSCRIPTIONS" validate:"min=1,max=1000"`
+	MaxMessageBytes  int64         `config:"max_message_bytes" env:"REALTIME_MAX_MESSAGE_BYTES" validate:"min=128,max=1048576"`
+	PingInterval     time.Duration `config:"ping_interval" env:"REALTIME_PING_INTERVAL" validate:"min=1s,max=5m"`
+	WriteTimeout     time.Duration `config:"write_timeout" env:"REALTIME_WRITE_TIMEOUT" validate:"min=100ms,max=1m"`
+}
+
+// Webhooks configures delivery of todo events to the URLs registered at
+// /webhooks.
+type Webhooks struct {
+	// Enabled runs the delivery worker. Webhooks can be managed either way.
+	Enabled        bool          `config:"enabled" env:"WEBHOOKS_ENABLED"`
+	PollInterval   time.Duration `config:"poll_interval" env:"WEBHOOKS_POLL_INTERVAL" validate:"min=100ms,max=1m"`
+	RequestTimeout time.Duration `config:"request_timeout" env:"WEBHOOKS_REQUEST_TIMEOUT" validate:"min=1s,max=1m"`
+	// MaxAttempts is how often a delivery is tried before it is given up.
+	MaxAttempts int `config:"max_attempts" env:"WEBHOOKS_MAX_ATTEMPTS" validate:"min=1,max=50"`
+	// InitialBackoff is the wait before the first retry. It doubles after
+	// every failed attempt, up to MaxBackoff.
+	InitialBackoff time.Duration `config:"initial_backoff" env:"WEBHOOKS_INITIAL_BACKOFF" validate:"min=1s,max=24h"`
+	MaxBackoff     time.Duration `config:"max_backoff" env:"WEBHOOKS_MAX_BACKOFF" validate:"gtefield=InitialBackoff,max=168h"`
+	// DisableAfterFailures is how many failed attempts in a row disable a
+	// webhook until it is re-enabled.
+	DisableAfterFailures int `config:"disable_after_failures" env:"WEBHOOKS_DISABLE_AFTER_FAILURES" validate:"min=1,max=1000"`
+	// BatchSize is how many deliveries are sent at once.
+	BatchSize int `config:"batch_size" env:"WEBHOOKS_BATCH_SIZE" validate:"min=1,max=500"`
+	// AllowPrivateNetworks lets webhooks reach loopback, private and
+	// link-local addresses, for receivers inside the deployment. Off by
+	// default, as anyone who can register a webhook could then probe
+	// internal services.
+	AllowPrivateNetworks bool `config:"allow_private_networks" env:"WEBHOOKS_ALLOW_PRIVATE_NETWORKS"`
+}
+
+// Outbox configures the relay that hands the todo events recorded with each
+// write to the change stream, webhooks and the event log.
+type Outbox struct {
+	PollInterval time.Duration `config:"poll_interval" env:"OUTBOX_POLL_INTERVAL" validate:"min=10ms,max=1m"`
+	BatchSize    int           `config:"batch_size" env:"OUTBOX_BATCH_SIZE" validate:"min=1,max=1000"`
+	// RetryInterval is the wait after a sink first fails on an event. It
+	// doubles after every further failure, up to MaxRetryInterval.
+	RetryInterval    time.Duration `config:"retry_interval" env:"OUTBOX_RETRY_INTERVAL" validate:"min=100ms,max=1h"`
+	MaxRetryInterval time.Duration `config:"max_retry_interval" env:"OUTBOX_MAX_RETRY_INTERVAL" validate:"gtefield=RetryInterval,max=24h"`
+	// Retention is how long delivered events are kept before cleanup.
+	Retention       time.Duration `config:"retention" env:"OUTBOX_RETENTION" validate:"min=0s,max=720h"`
+	CleanupInterval time.Duration `config:"cleanup_interval" env:"OUTBOX_CLEANUP_INTERVAL" validate:"min=1s"`
+	// LogFile, when set, receives every event as a line of JSON.
+	LogFile string `config:"log_file" env:"OUTBOX_LOG_FILE"`
+}
+
+// EventBus configures the in-process bus that domain events are published
+// on after each committed write.
+type EventBus struct {
+	// AsyncBuffer is how many events an asynchronous subscriber may fall
+	// behind before further events are dropped for it.
+	AsyncBuffer int `config:"async_buffer" env:"EVENT_BUS_ASYNC_BUFFER" validate:"min=1,max=100000"`
+}
+
+type Metrics struct {
+	Enabled bool   `config:"enabled" env:"METRICS_ENABLED"`
+	Path    string `config:"path" env:"METRICS_PATH" validate:"required_if=Enabled true,omitempty,startswith=/"`
+	// ScrapeTimeout bounds the database queries behind business gauges.
+	ScrapeTimeout time.Duration `config:"scrape_timeout" env:"METRICS_SCRAPE_TIMEOUT" validate:"min=100ms,max=1m"`
+}
+
+// Trace exporters selectable with tracing.exporter.
+const (
+	TracingExporterNone   = "none"
+	TracingExporterStdout = "stdout"
+	TracingExporterFile   = "file"
+	TracingExporterOTLP   = "otlp"
+)
+
+type Tracing struct {
+	Exporter     string  `config:"exporter" env:"TRACING_EXPORTER" validate:"oneof=none stdout file otlp"`
+	ServiceName  string  `config:"service_name" env:"OTEL_SERVICE_NAME" validate:"required"`
+	SampleRatio  float64 `config:"sample_ratio" env:"TRACING_SAMPLE_RATIO" validate:"min=0,max=1"`
+	FilePath     string  `config:"file_path" env:"TRACING_FILE_PATH" validate:"required_if=Exporter file"`
+	OTLPEndpoint string  `config:"otlp_endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT" validate:"required_if=Exporter otlp,omitempty,url"`
+	OTLPInsecure bool    `config:"otlp_insecure" env:"TRACING_OTLP_INSECURE"`
+}
+
+// Default returns the configuration used when no other source sets a value.
+func Default() *Config {
+	return &Config{
+		App: App{Env: "development"},
+		Log: Log{
+			Level:           "info",
+			Format:          "json",
+			AccessSkipPaths: []string{"/healthz", "/readyz", "/metrics"},
+		},
+		Server: Server{
+			Port:                  8080,
+			ReadHeaderTimeout:     5 * time.Second,
+			ReadTimeout:           30 * time.Second,
+			WriteTimeout:          30 * time.Second,
+			IdleTimeout:           2 * time.Minute,
+			ShutdownDelay:         3 * time.Second,
+			ShutdownTimeout:       20 * time.Second,
+			WorkerShutdownTimeout: 5 * time.Second,
+		},
+		Database: Database{
+			Driver:          DriverPostgres,
+			Host:            "postgres",
+			Port:            5432,
+			User:            "postgres",
+			Password:        "postgres",
+			Name:            "todo",
+			SSLMode:         "disable",
+			ConnectTimeout:  10 * time.Second,
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 30 * time.Minute,
+			SQLitePath:      "todo.db",
+		},
+		Limits: Limits{
+			MaxBodyBytes:      1 << 20,
+			MaxBulkOperations: 500,
+		},
+		Health: Health{
+			CheckTimeout: 2 * time.Second,
+		},
+		RateLimit: RateLimit{
+			Enabled:        true,
+			ReadPerMinute:  600,
+			ReadBurst:      100,
+			WritePerMinute: 120,
+			WriteBurst:     30,
+		},
+		Idempotency: Idempotency{
+			TTL:             24 * time.Hour,
+			CleanupInterval: time.Hour,
+		},
+		Events: Events{
+			ReplayBufferSize:  1000,
+			SubscriberBuffer:  64,
+			HeartbeatInterval: 15 * time.Second,
+		},
+		Realtime: Realtime{
+			SendBuffer:       64,
+			MaxSubscriptions: 50,
+			MaxMessageBytes:  4096,
+			PingInterval:     30 * time.Second,
+			WriteTimeout:     10 * time.Second,
+		},
+		Webhooks: Webhooks{
+			Enabled:              true,
+			PollInterval:         time.Second,
+			RequestTimeout:       10 * time.Second,
+			MaxAttempts:          8,
+			InitialBackoff:       30 * time.Second,
+			MaxBackoff:           time.Hour,
+			DisableAfterFailures: 20,
+			BatchSize:            20,
+		},
+		Outbox: Outbox{
+			PollInterval:     200 * time.Millisecond,
+			BatchSize:        100,
+			RetryInterval:    time.Second,
+			MaxRetryInterval: 5 * time.Minute,
+			Retention:        24 * time.Hour,
+			CleanupInterval:  time.Hour,
+		},
+		EventBus: EventBus{AsyncBuffer: 1024},
+		Metrics: Metrics{
+			Enabled:       true,
+			Path:          "/metrics",
+			ScrapeTimeout: 5 * time.Second,
+		},
+		Tracing: Tracing{
+			Exporter:     TracingExporterNone,
+			ServiceName:  "todo-app",
+			SampleRatio:  1,
+			FilePath:     "traces.jsonl",
+			OTLPEndpoint: "http://localhost:4318",
+		},
+	}
+}
+
+// DSN builds the Postgres connection string for the database settings.
+func (d Database) DSN() string {
+	return fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%d sslmode=%s connect_timeout=%d TimeZone=UTC",
+		d.Host, d.User, d.Password, d.Name, d.Port, d.SSLMode, int(d.ConnectTimeout.Seconds()),
+	)
+}
+
+// Validate checks every section and reports all violations at once, keyed by
+// the config path of the offending field.
+func (c *Config) Validate() error {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(f reflect.StructField) string {
+		return f.Tag.Get("config")
+	})
+
+	err := validate.Struct(c)
+	if err == nil {
+		return nil
+	}
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return fmt.Errorf("config: %w", err)
+	}
+
+	errs := make([]error, 0, len(fieldErrs))
+	for _, fe := range fieldErrs {
+		path := strings.TrimPrefix(fe.Namespace(), "Config.")
+		errs = append(errs, fmt.Errorf("config: %s: %s (got %v)", path, describeRule(fe), fe.Value()))
+	}
+	return errors.Join(errs...)
+}
+
+func describeRule(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "required_if":
+		return "is required when " + strings.Replace(fe.Param(), " ", " is ", 1)
+	case "oneof":
+		return "must be one of [" + fe.Param() + "]"
+	case "min":
+		return "must be at least " + fe.Param()
+	case "max":
+		return "must be at most " + fe.Param()
+	case "url":
+		return "must be a URL"
+	case "startswith":
+		return "must start with " + fe.Param()
+	case "ltefield":
+		return "must not exceed " + fe.Param()
+	case "gtefield":
+		return "must be at least " + fe.Param()
+	default:
+		return "failed " + fe.Tag()
+	}
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func envMap(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := vars[key]
+		return v, ok
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := Load(Options{LookupEnv: envMap(nil)})
+
+	require.NoError(t, err)
+	assert.Equal(t, 8080, cfg.Server.Port)
+	assert.Equal(t, "postgres", cfg.Database.Host)
+	assert.Equal(t, 10*time.Second, cfg.Database.ConnectTimeout)
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	file := writeFile(t, "config.yaml", `
+server:
+  port: 9000
+database:
+  host: file-host
+  name: file-db
+  connect_timeout: 3s
+`)
+	dotenv := writeFile(t, ".env", "DB_HOST=dotenv-host\nDB_USER=dotenv-user\n")
+
+	cfg, err := Load(Options{
+		File:      file,
+		EnvFile:   dotenv,
+		LookupEnv: envMap(map[string]string{"DB_USER": "env-user"}),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 9000, cfg.Server.Port)
+	assert.Equal(t, "file-db", cfg.Database.Name)
+	assert.Equal(t, 3*time.Second, cfg.Database.ConnectTimeout)
+	assert.Equal(t, "dotenv-host", cfg.Database.Host)
+	assert.Equal(t, "env-user", cfg.Database.User)
+	assert.Equal(t, SourceFile, cfg.sources["server.port"])
+	assert.Equal(t, SourceDotEnv, cfg.sources["database.host"])
+	assert.Equal(t, SourceEnv, cfg.sources["database.user"])
+	assert.Equal(t, SourceDefault, cfg.sources["log.level"])
+}
+
+func TestLoad_TOML(t *testing.T) {
+	file := writeFile(t, "config.toml", "[server]\nport = 7000\n\n[log]\nlevel = \"debug\"\n")
+
+	cfg, err := Load(Options{File: file, LookupEnv: envMap(nil)})
+
+	require.NoError(t, err)
+	assert.Equal(t, 7000, cfg.Server.Port)
+	assert.Equal(t, "debug", cfg.Log.Level)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	t.Run("unknown key", func(t *testing.T) {
+		file := writeFile(t, "config.yaml", "server:\n  prot: 9000\n")
+
+		_, err := Load(Options{File: file, LookupEnv: envMap(nil)})
+
+		assert.ErrorContains(t, err, `unknown key "server.prot"`)
+	})
+
+	t.Run("malformed value", func(t *testing.T) {
+		_, err := Load(Options{LookupEnv: envMap(map[string]string{"APP_PORT": "http"})})
+
+		assert.ErrorContains(t, err, "APP_PORT")
+	})
+
+	t.Run("validation reports every field", func(t *testing.T) {
+		_, err := Load(Options{LookupEnv: envMap(map[string]string{
+			"APP_PORT":             "70000",
+			"DB_SSLMODE":           "sometimes",
+			"DB_CONNECT_TIMEOUT":   "0s",
+			"DB_MAX_IDLE_CONNS":    "100",
+			"WEBHOOKS_MAX_BACKOFF": "1s",
+		})})
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "server.port")
+		assert.ErrorContains(t, err, "database.sslmode")
+		assert.ErrorContains(t, err, "database.connect_timeout")
+		assert.ErrorContains(t, err, "database.max_idle_conns")
+		assert.ErrorContains(t, err, "webhooks.max_backoff: must be at least InitialBackoff")
+	})
+}
+
+func TestConfig_Print(t *testing.T) {
+	cfg, err := Load(Options{LookupEnv: envMap(map[string]string{"DB_PASSWORD": "hunter2"})})
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, cfg.Print(&out))
+
+	assert.NotContains(t, out.String(), "hunter2")
+	assert.Contains(t, out.String(), "database.password")
+	assert.Contains(t, out.String(), redacted)
+}
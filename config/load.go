@@ -0,0 +1,260 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Sources, from lowest to highest precedence.
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceDotEnv  = "dotenv"
+	SourceEnv     = "env"
+)
+
+// Options controls where Load looks for configuration.
+type Options struct {
+	// File is an optional YAML (.yaml, .yml) or TOML (.toml) config file.
+	File string
+	// EnvFile is an optional dotenv file; a missing file is not an error.
+	EnvFile string
+	// LookupEnv reads process environment variables. Defaults to os.LookupEnv.
+	LookupEnv func(key string) (string, bool)
+}
+
+// Load builds the effective configuration by layering defaults, the config
+// file, the dotenv file and the process environment, in that order, and then
+// validates the result.
+func Load(opts Options) (*Config, error) {
+	if opts.LookupEnv == nil {
+		opts.LookupEnv = os.LookupEnv
+	}
+
+	cfg := Default()
+	fields := cfg.fields()
+	cfg.sources = make(map[string]string, len(fields))
+	for _, f := range fields {
+		cfg.sources[f.path] = SourceDefault
+	}
+
+	if opts.File != "" {
+		values, err := readFile(opts.File)
+		if err != nil {
+			return nil, err
+		}
+		byPath := make(map[string]field, len(fields))
+		for _, f := range fields {
+			byPath[f.path] = f
+		}
+		for _, key := range sortedKeys(values) {
+			f, ok := byPath[key]
+			if !ok {
+				return nil, fmt.Errorf("config: %s: unknown key %q", opts.File, key)
+			}
+			if err := f.set(values[key]); err != nil {
+				return nil, fmt.Errorf("config: %s: %s: %w", opts.File, key, err)
+			}
+			cfg.sources[key] = SourceFile
+		}
+	}
+
+	if opts.EnvFile != "" {
+		dotenv, err := godotenv.Read(opts.EnvFile)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("config: reading %s: %w", opts.EnvFile, err)
+		}
+		for _, f := range fields {
+			if value, ok := dotenv[f.env]; ok && f.env != "" {
+				if err := f.set(value); err != nil {
+					return nil, fmt.Errorf("config: %s: %s: %w", opts.EnvFile, f.env, err)
+				}
+				cfg.sources[f.path] = SourceDotEnv
+			}
+		}
+	}
+
+	for _, f := range fields {
+		if f.env == "" {
+			continue
+		}
+		if value, ok := opts.LookupEnv(f.env); ok {
+			if err := f.set(value); err != nil {
+				return nil, fmt.Errorf("config: %s: %w", f.env, err)
+			}
+			cfg.sources[f.path] = SourceEnv
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// field is a settable leaf of Config addressed by its dotted config path.
+type field struct {
+	path   string
+	env    string
+	secret bool
+	value  reflect.Value
+}
+
+func (c *Config) fields() []field {
+	var out []field
+	var walk func(prefix string, v reflect.Value)
+	walk = func(prefix string, v reflect.Value) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			key := sf.Tag.Get("config")
+			if key == "" {
+				continue
+			}
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			fv := v.Field(i)
+			if sf.Type.Kind() == reflect.Struct {
+				walk(path, fv)
+				continue
+			}
+			out = append(out, field{
+				path:   path,
+				env:    sf.Tag.Get("env"),
+				secret: sf.Tag.Get("secret") == "true",
+				value:  fv,
+			})
+		}
+	}
+	walk("", reflect.ValueOf(c).Elem())
+	return out
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func (f field) set(raw string) error {
+	raw = strings.TrimSpace(raw)
+	v := f.value
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		v.SetInt(n)
+	case reflect.Float64:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		v.SetFloat(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		v.SetBool(b)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+	return nil
+}
+
+func (f field) String() string {
+	if f.value.Type() == durationType {
+		return time.Duration(f.value.Int()).String()
+	}
+	if f.value.Kind() == reflect.Slice {
+		return strings.Join(f.value.Interface().([]string), ",")
+	}
+	return fmt.Sprint(f.value.Interface())
+}
+
+// readFile parses a YAML or TOML file into a flat map keyed by dotted path.
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: reading %s: %w", path, err)
+	}
+
+	var tree map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	default:
+		return nil, fmt.Errorf("config: %s: unsupported file type, want .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config: parsing %s: %w", path, err)
+	}
+
+	flat := make(map[string]string)
+	flatten("", tree, flat)
+	return flat, nil
+}
+
+func flatten(prefix string, node map[string]any, out map[string]string) {
+	for key, value := range node {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		switch v := value.(type) {
+		case map[string]any:
+			flatten(path, v, out)
+		case []any:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			out[path] = strings.Join(items, ",")
+		case nil:
+			// An empty key keeps the value from the previous layer.
+		default:
+			out[path] = fmt.Sprint(v)
+		}
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+const redacted = "******"
+
+// Print writes the effective configuration as one `path = value` line per
+// setting, annotated with the source that set it. Secret values are redacted.
+func (c *Config) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, f := range c.fields() {
+		value := f.String()
+		if f.secret && value != "" {
+			value = redacted
+		}
+		source := c.sources[f.path]
+		if source == "" {
+			source = SourceDefault
+		}
+		env := f.env
+		if env == "" {
+			env = "-"
+		}
+		if _, err := fmt.Fprintf(tw, "%s\t= %s\t# %s\t%s\n", f.path, value, source, env); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// DomainEvent is a typed notification of something that happened in the
+// domain, published in process after the change was committed. Unlike
+// TodoEvent it is never persisted or sent to other replicas.
+type DomainEvent interface {
+	// EventName identifies the event type in logs and metrics.
+	EventName() string
+}
+
+// TodoCreatedEvent is published after a todo was created.
+type TodoCreatedEvent struct {
+	Todo       Todo
+	OccurredAt time.Time
+}
+
+func (TodoCreatedEvent) EventName() string { return "todo.created" }
+
+// TodoUpdatedEvent is published after a todo was updated. Changed lists the
+// JSON names of the fields that differ between Previous and Todo; it is
+// empty when the update did not change anything.
+type TodoUpdatedEvent struct {
+	Todo       Todo
+	Previous   Todo
+	Changed    []string
+	OccurredAt time.Time
+}
+
+func (TodoUpdatedEvent) EventName() string { return "todo.updated" }
+
+// HasChanged reports whether field, by its JSON name, was changed.
+func (e TodoUpdatedEvent) HasChanged(field string) bool {
+	return slices.Contains(e.Changed, field)
+}
+
+// TodoStatusChangedEvent is published after an update that moved a todo to
+// another status, following the TodoUpdatedEvent of the same update.
+type TodoStatusChangedEvent struct {
+	Todo       Todo
+	From       string
+	To         string
+	OccurredAt time.Time
+}
+
+func (TodoStatusChangedEvent) EventName() string { return "todo.status_changed" }
+
+// TodoDeletedEvent is published after a todo was deleted. Todo is its last
+// stored state.
+type TodoDeletedEvent struct {
+	Todo       Todo
+	OccurredAt time.Time
+}
+
+func (TodoDeletedEvent) EventName() string { return "todo.deleted" }
+
+// ChangedFields returns the JSON names of the fields a user can edit that
+// differ between before and after, in declaration order.
+func ChangedFields(before, after *Todo) []string {
+	var changed []string
+	add := func(field string, differs bool) {
+		if differs {
+			changed = append(changed, field)
+		}
+	}
+	add("title", before.Title != after.Title)
+	add("description", before.Description != after.Description)
+	add("image", before.Image != after.Image)
+	add("status", before.Status != after.Status)
+	add("project", before.Project != after.Project)
+	add("tags", !slices.Equal(before.Tags, after.Tags))
+	add("due_date", !equalTime(before.DueDate, after.DueDate))
+	return changed
+}
+
+func equalTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// EventHandler reacts to a domain event. A returned error is logged by the
+// bus; the change that caused the event stands either way.
+type EventHandler func(ctx context.Context, event DomainEvent) error
+
+// On adapts handle to an EventHandler that only sees events of type E and
+// ignores all others.
+func On[E DomainEvent](handle func(ctx context.Context, event E) error) EventHandler {
+	return func(ctx context.Context, event DomainEvent) error {
+		if e, ok := event.(E); ok {
+			return handle(ctx, e)
+		}
+		return nil
+	}
+}
+
+// EventBus delivers domain events to the subsystems that subscribed to
+// them, so they can react to changes without the usecase knowing them.
+type EventBus interface {
+	// Publish hands events, in order, to every subscriber. It returns once
+	// the synchronous subscribers have handled them.
+	Publish(ctx context.Context, events ...DomainEvent)
+	// Subscribe registers a synchronous handler. It runs in the publisher's
+	// goroutine, so it must be quick; it is suited to bookkeeping such as
+	// metrics that must not lag behind the change.
+	Subscribe(name string, handler EventHandler)
+	// SubscribeAsync registers a handler that runs in its own goroutine and
+	// receives events in publish order through a bounded queue. Events are
+	// dropped while its queue is full, so a slow handler never delays a
+	// request.
+	SubscribeAsync(name string, handler EventHandler)
+}
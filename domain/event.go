@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type TodoEventType string
+
+const (
+	TodoCreated TodoEventType = "todo.created"
+	TodoUpdated TodoEventType = "todo.updated"
+	TodoDeleted TodoEventType = "todo.deleted"
+)
+
+// TodoEvent records a change to a todo that has been written to storage.
+// Todo is the state after the change, or the last stored state for
+// TodoDeleted. Previous is the state before a TodoUpdated. ID is the
+// outbox ID, except on the change stream, whose broker numbers events itself.
+type TodoEvent struct {
+	ID         string        `json:"id"`
+	Type       TodoEventType `json:"type"`
+	Todo       Todo          `json:"todo"`
+	Previous   *Todo         `json:"previous,omitempty"`
+	OccurredAt time.Time     `json:"occurred_at"`
+}
+
+// TodoEventPublisher fans todo events out to subscribers. Publish must not
+// block on slow subscribers.
+type TodoEventPublisher interface {
+	Publish(ctx context.Context, event TodoEvent)
+}
@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidQuery is matched by every QueryError.
+var ErrInvalidQuery = errors.New("invalid query")
+
+// QueryError reports why a filter query could not be parsed. Position is
+// the 1-based character offset of the problem in the query; Code names the
+// kind of problem and Param is the offending text, if any.
+type QueryError struct {
+	Position int
+	Code     string
+	Param    string
+}
+
+// Codes of QueryError.
+const (
+	QueryUnexpectedToken   = "unexpected_token"
+	QueryUnexpectedEnd     = "unexpected_end"
+	QueryUnterminatedQuote = "unterminated_quote"
+	QueryUnclosedParen     = "unclosed_paren"
+	QueryMissingValue      = "missing_value"
+	QueryUnknownField      = "unknown_field"
+	QueryInvalidOperator   = "invalid_operator"
+	QueryInvalidValue      = "invalid_value"
+	QueryInvalidDate       = "invalid_date"
+)
+
+func (e *QueryError) Error() string {
+	if e.Param != "" {
+		return fmt.Sprintf("%s: %s %q at position %d", ErrInvalidQuery, e.Code, e.Param, e.Position)
+	}
+	return fmt.Sprintf("%s: %s at position %d", ErrInvalidQuery, e.Code, e.Position)
+}
+
+func (e *QueryError) Unwrap() error {
+	return ErrInvalidQuery
+}
+
+// Filter is a condition on todos. Filters are built from queries by the
+// querylang package and translated by each repository, into SQL for gorm.
+type Filter interface {
+	isFilter()
+}
+
+// AndFilter matches todos matching every filter. An empty AndFilter
+// matches every todo.
+type AndFilter []Filter
+
+// OrFilter matches todos matching any filter. An empty OrFilter matches no
+// todo.
+type OrFilter []Filter
+
+// NotFilter matches todos not matching Filter.
+type NotFilter struct {
+	Filter Filter
+}
+
+// FieldFilter compares one field of a todo with Value, a string for text
+// fields and a time.Time for FilterDue, FilterCreated, FilterUpdated and
+// FilterCompleted.
+type FieldFilter struct {
+	Field FilterField
+	Op    FilterOp
+	Value any
+}
+
+func (AndFilter) isFilter()   {}
+func (OrFilter) isFilter()    {}
+func (NotFilter) isFilter()   {}
+func (FieldFilter) isFilter() {}
+
+// FilterField names what a FieldFilter looks at.
+type FilterField string
+
+const (
+	FilterStatus      FilterField = "status"
+	FilterProject     FilterField = "project"
+	FilterTag         FilterField = "tag"
+	FilterTitle       FilterField = "title"
+	FilterDescription FilterField = "description"
+	// FilterText looks at the title and the description.
+	FilterText    FilterField = "text"
+	FilterDue     FilterField = "due"
+	FilterCreated FilterField = "created"
+	FilterUpdated FilterField = "updated"
+	// FilterCompleted looks at CompletedAt, which only completed todos have.
+	FilterCompleted FilterField = "completed"
+)
+
+// FilterOp is the comparison of a FieldFilter.
+type FilterOp string
+
+const (
+	// OpEq matches an exact value. On FilterTag it matches todos that have
+	// the tag.
+	OpEq FilterOp = "eq"
+	// OpContains matches text containing Value, ignoring case. Value must be
+	// lower case.
+	OpContains FilterOp = "contains"
+	// OpBefore matches a time that is set and earlier than Value.
+	OpBefore FilterOp = "before"
+	// OpNotBefore matches a time that is set and not earlier than Value.
+	OpNotBefore FilterOp = "not_before"
+	// OpUnset matches todos without a due date.
+	OpUnset FilterOp = "unset"
+)
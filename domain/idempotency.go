@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrIdempotencyKeyExists is returned when reserving a key that is already
+// stored for the same scope.
+var ErrIdempotencyKeyExists = errors.New("idempotency key already exists")
+
+// IdempotencyRecord stores the outcome of a request sent with an
+// Idempotency-Key header. Scope identifies the caller so keys of different
+// users never collide. A record with Status 0 is reserved by a request that
+// is still running.
+type IdempotencyRecord struct {
+	Scope       string `gorm:"type:varchar(255);primaryKey"`
+	Key         string `gorm:"column:idempotency_key;type:varchar(255);primaryKey"`
+	Fingerprint string `gorm:"type:varchar(64);not null"`
+	Status      int    `gorm:"not null"`
+	ContentType string `gorm:"type:varchar(100)"`
+	Body        []byte
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	ExpiresAt   time.Time `gorm:"not null;index"`
+}
+
+func (IdempotencyRecord) TableName() string {
+	return "idempotency_keys"
+}
+
+// Completed reports whether the request holding the key has finished.
+func (r *IdempotencyRecord) Completed() bool {
+	return r.Status != 0
+}
+
+type IdempotencyRepository interface {
+	// Reserve stores rec as a running request. It fails with
+	// ErrIdempotencyKeyExists if an unexpired record already holds the key;
+	// expired records are replaced.
+	Reserve(ctx context.Context, rec *IdempotencyRecord) error
+	// Find returns the unexpired record for scope and key, or ErrNotFound.
+	Find(ctx context.Context, scope, key string) (*IdempotencyRecord, error)
+	// Complete stores the response of a reserved request.
+	Complete(ctx context.Context, rec *IdempotencyRecord) error
+	// Release drops a reservation so the request can be retried.
+	Release(ctx context.Context, scope, key string) error
+	// DeleteExpired removes records that expired before now.
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
@@ -0,0 +1,46 @@
+package mocks
+
+import (
+	"context"
+	"todo-app/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSavedFilterUsecase struct {
+	mock.Mock
+}
+
+func (m *MockSavedFilterUsecase) Create(ctx context.Context, filter *domain.SavedFilter) error {
+	args := m.Called(ctx, filter)
+	return args.Error(0)
+}
+
+func (m *MockSavedFilterUsecase) Update(ctx context.Context, filter *domain.SavedFilter) error {
+	args := m.Called(ctx, filter)
+	return args.Error(0)
+}
+
+func (m *MockSavedFilterUsecase) Get(ctx context.Context, owner string, id uuid.UUID) (*domain.SavedFilter, error) {
+	args := m.Called(ctx, owner, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.SavedFilter), args.Error(1)
+}
+
+func (m *MockSavedFilterUsecase) List(ctx context.Context, owner string) ([]domain.SavedFilter, error) {
+	args := m.Called(ctx, owner)
+	return args.Get(0).([]domain.SavedFilter), args.Error(1)
+}
+
+func (m *MockSavedFilterUsecase) Delete(ctx context.Context, owner string, id uuid.UUID) error {
+	args := m.Called(ctx, owner, id)
+	return args.Error(0)
+}
+
+func (m *MockSavedFilterUsecase) Todos(ctx context.Context, owner string, id uuid.UUID) ([]domain.Todo, error) {
+	args := m.Called(ctx, owner, id)
+	return args.Get(0).([]domain.Todo), args.Error(1)
+}
@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"context"
 	"todo-app/domain"
 
 	"github.com/google/uuid"
@@ -11,30 +12,106 @@ type MockTodoRepository struct {
 	mock.Mock
 }
 
-func (m *MockTodoRepository) Create(todo *domain.Todo) error {
-	args := m.Called(todo)
+func (m *MockTodoRepository) Create(ctx context.Context, todo *domain.Todo) error {
+	args := m.Called(ctx, todo)
 	return args.Error(0)
 }
 
-func (m *MockTodoRepository) Update(todo *domain.Todo) error {
-	args := m.Called(todo)
+// CreateBatch records the call and, unless an error is configured, assigns
+// IDs to todos like the repositories do.
+func (m *MockTodoRepository) CreateBatch(ctx context.Context, todos []domain.Todo) error {
+	args := m.Called(ctx, todos)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	for i := range todos {
+		if todos[i].ID == uuid.Nil {
+			todos[i].ID = uuid.New()
+		}
+	}
+	return nil
+}
+
+func (m *MockTodoRepository) Update(ctx context.Context, todo *domain.Todo) error {
+	args := m.Called(ctx, todo)
 	return args.Error(0)
 }
 
-func (m *MockTodoRepository) Delete(id uuid.UUID) error {
-	args := m.Called(id)
+func (m *MockTodoRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-func (m *MockTodoRepository) FindByID(id uuid.UUID) (*domain.Todo, error) {
-	args := m.Called(id)
+func (m *MockTodoRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Todo, error) {
+	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*domain.Todo), args.Error(1)
 }
 
-func (m *MockTodoRepository) FindAll() ([]domain.Todo, error) {
-	args := m.Called()
+func (m *MockTodoRepository) FindAll(ctx context.Context) ([]domain.Todo, error) {
+	args := m.Called(ctx)
 	return args.Get(0).([]domain.Todo), args.Error(1)
 }
+
+func (m *MockTodoRepository) FindByFilter(ctx context.Context, filter domain.Filter) ([]domain.Todo, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]domain.Todo), args.Error(1)
+}
+
+func (m *MockTodoRepository) Facets(ctx context.Context, filter domain.Filter) (*domain.Facets, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Facets), args.Error(1)
+}
+
+func (m *MockTodoRepository) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+
+func (m *MockTodoRepository) Search(ctx context.Context, query string, limit int) ([]domain.SearchResult, error) {
+	args := m.Called(ctx, query, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.SearchResult), args.Error(1)
+}
+
+func (m *MockTodoRepository) FuzzySearch(ctx context.Context, query string, threshold float64, limit int) ([]domain.FuzzyMatch, error) {
+	args := m.Called(ctx, query, threshold, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.FuzzyMatch), args.Error(1)
+}
+
+// Stream records the call and passes the configured todos to fn before
+// returning the configured error.
+func (m *MockTodoRepository) Stream(ctx context.Context, filter domain.Filter, sortBy string, fn func(domain.Todo) error) error {
+	args := m.Called(ctx, filter, sortBy, fn)
+	if todos, ok := args.Get(0).([]domain.Todo); ok {
+		for _, todo := range todos {
+			if err := fn(todo); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+// Transaction records the call and, unless an error is configured, runs fn
+// against the mock itself.
+func (m *MockTodoRepository) Transaction(ctx context.Context, fn func(tx domain.TodoRepository) error) error {
+	args := m.Called(ctx, fn)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return fn(m)
+}
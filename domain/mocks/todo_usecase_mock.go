@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"context"
 	"todo-app/domain"
 
 	"github.com/google/uuid"
@@ -11,22 +12,76 @@ type MockTodoUsecase struct {
 	mock.Mock
 }
 
-func (m *MockTodoUsecase) Create(todo *domain.Todo) error {
-	args := m.Called(todo)
+func (m *MockTodoUsecase) Create(ctx context.Context, todo *domain.Todo) error {
+	args := m.Called(ctx, todo)
 	return args.Error(0)
 }
 
-func (m *MockTodoUsecase) Update(todo *domain.Todo) error {
-	args := m.Called(todo)
+func (m *MockTodoUsecase) Update(ctx context.Context, todo *domain.Todo) error {
+	args := m.Called(ctx, todo)
 	return args.Error(0)
 }
 
-func (m *MockTodoUsecase) Delete(id uuid.UUID) error {
-	args := m.Called(id)
+func (m *MockTodoUsecase) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-func (m *MockTodoUsecase) List(sortBy, search string) ([]domain.Todo, error) {
-	args := m.Called(sortBy, search)
+func (m *MockTodoUsecase) List(ctx context.Context, opts domain.ListOptions) ([]domain.Todo, error) {
+	args := m.Called(ctx, opts)
 	return args.Get(0).([]domain.Todo), args.Error(1)
 }
+
+func (m *MockTodoUsecase) Facets(ctx context.Context, query string) (*domain.Facets, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Facets), args.Error(1)
+}
+
+func (m *MockTodoUsecase) Search(ctx context.Context, query string, limit int) ([]domain.SearchResult, error) {
+	args := m.Called(ctx, query, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.SearchResult), args.Error(1)
+}
+
+func (m *MockTodoUsecase) FuzzySearch(ctx context.Context, query string, threshold float64, limit int) ([]domain.FuzzyMatch, error) {
+	args := m.Called(ctx, query, threshold, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.FuzzyMatch), args.Error(1)
+}
+
+func (m *MockTodoUsecase) Import(ctx context.Context, records []domain.ImportRecord, dryRun bool) ([]domain.ImportRecord, error) {
+	args := m.Called(ctx, records, dryRun)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.ImportRecord), args.Error(1)
+}
+
+// Export records the call and passes the configured todos to fn before
+// returning the configured error.
+func (m *MockTodoUsecase) Export(ctx context.Context, opts domain.ListOptions, fn func(domain.Todo) error) error {
+	args := m.Called(ctx, opts, fn)
+	if todos, ok := args.Get(0).([]domain.Todo); ok {
+		for _, todo := range todos {
+			if err := fn(todo); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockTodoUsecase) Bulk(ctx context.Context, ops []domain.BulkOperation, atomic bool) ([]domain.BulkResult, error) {
+	args := m.Called(ctx, ops, atomic)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.BulkResult), args.Error(1)
+}
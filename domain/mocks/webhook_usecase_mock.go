@@ -0,0 +1,49 @@
+package mocks
+
+import (
+	"context"
+	"todo-app/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWebhookUsecase struct {
+	mock.Mock
+}
+
+func (m *MockWebhookUsecase) Create(ctx context.Context, webhook *domain.Webhook) error {
+	args := m.Called(ctx, webhook)
+	return args.Error(0)
+}
+
+func (m *MockWebhookUsecase) Update(ctx context.Context, webhook *domain.Webhook) error {
+	args := m.Called(ctx, webhook)
+	return args.Error(0)
+}
+
+func (m *MockWebhookUsecase) Get(ctx context.Context, owner string, id uuid.UUID) (*domain.Webhook, error) {
+	args := m.Called(ctx, owner, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookUsecase) List(ctx context.Context, owner string) ([]domain.Webhook, error) {
+	args := m.Called(ctx, owner)
+	return args.Get(0).([]domain.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookUsecase) Delete(ctx context.Context, owner string, id uuid.UUID) error {
+	args := m.Called(ctx, owner, id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookUsecase) Deliveries(ctx context.Context, owner string, id uuid.UUID) ([]domain.WebhookDelivery, error) {
+	args := m.Called(ctx, owner, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.WebhookDelivery), args.Error(1)
+}
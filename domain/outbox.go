@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// OutboxEvent is a todo event stored in the same transaction as the write
+// that caused it, so the event survives a crash between commit and
+// publish. A relay hands pending events to the registered sinks and marks
+// them delivered once every sink accepted them.
+type OutboxEvent struct {
+	ID    uint64    `gorm:"primaryKey"`
+	Event TodoEvent `gorm:"type:text;not null;serializer:json"`
+	// DeliveredTo names the sinks that already accepted the event, so a
+	// retry after another sink failed skips them.
+	DeliveredTo   []string   `gorm:"type:text;serializer:json"`
+	Attempts      int        `gorm:"not null;default:0"`
+	NextAttemptAt time.Time  `gorm:"not null;index:idx_outbox_events_pending,priority:2"`
+	DeliveredAt   *time.Time `gorm:"index:idx_outbox_events_pending,priority:1"`
+	LastError     string     `gorm:"type:text"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime"`
+}
+
+// TodoEvent returns the stored event with its ID set to the outbox ID, which
+// stays the same when the event is relayed again.
+func (e *OutboxEvent) TodoEvent() TodoEvent {
+	event := e.Event
+	event.ID = strconv.FormatUint(e.ID, 10)
+	return event
+}
+
+// OutboxRepository is the relay's view of the outbox. Events are written by
+// TodoRepository.
+type OutboxRepository interface {
+	// ClaimPending returns up to limit undelivered events due at now, oldest
+	// first, and hides them from other relays for lease.
+	ClaimPending(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]OutboxEvent, error)
+	MarkDelivered(ctx context.Context, ids []uint64, at time.Time) error
+	// Retry records a failed relay attempt and the sinks that accepted the
+	// event so far, and schedules the next attempt.
+	Retry(ctx context.Context, id uint64, next time.Time, lastError string, deliveredTo []string) error
+	// DeleteDelivered removes events delivered before the given time.
+	DeleteDelivered(ctx context.Context, before time.Time) (int64, error)
+}
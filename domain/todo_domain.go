@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -12,6 +13,12 @@ var (
 	ErrNotFound          = errors.New("todo not found")
 	ErrValidationFailed  = errors.New("validation failed")
 	ErrDatabaseOperation = errors.New("database operation failed")
+	// ErrBulkAborted marks bulk operations that were rolled back or never
+	// attempted because another operation in an atomic batch failed.
+	ErrBulkAborted = errors.New("bulk operation aborted")
+	// ErrReadOnly is returned when changing a built-in resource such as a
+	// smart list.
+	ErrReadOnly = errors.New("resource is read-only")
 )
 
 type Todo struct {
@@ -22,21 +29,176 @@ type Todo struct {
 	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 	Image       string    `json:"image" gorm:"type:text" validate:"omitempty,base64"`
 	Status      string    `json:"status" gorm:"type:varchar(20);not null" validate:"required,oneof=IN_PROGRESS COMPLETED"`
+	Project     string    `json:"project" gorm:"type:varchar(100);index" validate:"max=100"`
+	Tags        []string  `json:"tags" gorm:"type:text;serializer:json" validate:"max=20,dive,required,max=50"`
+	// DueDate is when the todo should be done, if it has a deadline.
+	DueDate *time.Time `json:"due_date,omitempty" gorm:"index"`
+	// CompletedAt is when the status last became COMPLETED, and is nil while
+	// the todo is in progress. Updates cannot change it.
+	CompletedAt *time.Time `json:"completed_at,omitempty" gorm:"index"`
+}
+
+// HasTag reports whether t is tagged with tag.
+func (t *Todo) HasTag(tag string) bool {
+	for _, have := range t.Tags {
+		if have == tag {
+			return true
+		}
+	}
+	return false
 }
 
 type TodoRepository interface {
-	Create(todo *Todo) error
-	Update(todo *Todo) error
-	FindAll() ([]Todo, error)
-	FindByID(id uuid.UUID) (*Todo, error)
-	Delete(id uuid.UUID) error
+	Create(ctx context.Context, todo *Todo) error
+	// CreateBatch stores todos with one multi-row insert, assigning IDs and
+	// timestamps like Create and recording an outbox event for each. Either
+	// every todo is stored or none is.
+	CreateBatch(ctx context.Context, todos []Todo) error
+	Update(ctx context.Context, todo *Todo) error
+	FindAll(ctx context.Context) ([]Todo, error)
+	// FindByFilter returns the todos matching filter, in the same order as
+	// FindAll.
+	FindByFilter(ctx context.Context, filter Filter) ([]Todo, error)
+	// Stream calls fn for each todo matching filter, ordered by sortBy as
+	// ListOptions.SortBy, reading them in batches rather than loading them
+	// all, and without holding a database connection while fn runs. It
+	// stops at and returns the first error of fn.
+	Stream(ctx context.Context, filter Filter, sortBy string, fn func(Todo) error) error
+	FindByID(ctx context.Context, id uuid.UUID) (*Todo, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	CountByStatus(ctx context.Context) (map[string]int64, error)
+	// Facets counts the todos matching filter per status, tag and project.
+	Facets(ctx context.Context, filter Filter) (*Facets, error)
+	// Search returns the todos whose title or description match query, a
+	// web search style query, most relevant first. limit <= 0 returns every
+	// match.
+	Search(ctx context.Context, query string, limit int) ([]SearchResult, error)
+	// FuzzySearch returns the todos whose title resembles query with a
+	// similarity of at least threshold, between 0 and 1, best match first.
+	// limit <= 0 returns every match.
+	FuzzySearch(ctx context.Context, query string, threshold float64, limit int) ([]FuzzyMatch, error)
+	// Transaction runs fn against a repository bound to a single
+	// transaction, committing if fn returns nil and rolling back otherwise.
+	Transaction(ctx context.Context, fn func(tx TodoRepository) error) error
 }
 
 type TodoUsecase interface {
-	Create(todo *Todo) error
-	Update(todo *Todo) error
-	List(sortBy, search string) ([]Todo, error)
-	Delete(id uuid.UUID) error
+	Create(ctx context.Context, todo *Todo) error
+	Update(ctx context.Context, todo *Todo) error
+	List(ctx context.Context, opts ListOptions) ([]Todo, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	// Search runs a full-text search and returns at most limit results,
+	// most relevant first. limit 0 selects the default.
+	Search(ctx context.Context, query string, limit int) ([]SearchResult, error)
+	// FuzzySearch finds todos by a possibly misspelled title and returns at
+	// most limit matches, best first. threshold and limit 0 select the
+	// defaults.
+	FuzzySearch(ctx context.Context, query string, threshold float64, limit int) ([]FuzzyMatch, error)
+	// Export calls fn for each todo List would return for opts, streaming
+	// them from the repository unless opts.Search is set. Invalid options
+	// are reported before fn is first called.
+	Export(ctx context.Context, opts ListOptions, fn func(Todo) error) error
+	// Import validates records and, unless dryRun, creates the valid ones in
+	// one transaction. It returns records with Err set for the rows that were
+	// skipped and Todo as stored, or as it would be stored, for the others.
+	// An error is only returned when storing fails, and then nothing is
+	// created.
+	Import(ctx context.Context, records []ImportRecord, dryRun bool) ([]ImportRecord, error)
+	// Facets counts the todos matching a filter query, as accepted by List,
+	// per status, tag and project. An empty query counts every todo.
+	Facets(ctx context.Context, query string) (*Facets, error)
+	// Bulk applies ops in order and reports one result per op. In atomic
+	// mode the first failure rolls back the whole batch: the returned error
+	// wraps ErrBulkAborted and the remaining results carry ErrBulkAborted.
+	Bulk(ctx context.Context, ops []BulkOperation, atomic bool) ([]BulkResult, error)
+}
+
+// ListOptions select and order the todos returned by TodoUsecase.List.
+type ListOptions struct {
+	// SortBy is "title", "date", "status" or "due", which puts todos
+	// without a due date last; empty keeps storage order, or relevance
+	// order with Search.
+	SortBy string
+	// Search keeps the todos matching a full-text search.
+	Search string
+	// Query keeps the todos matching a filter query such as
+	// `status:IN_PROGRESS tag:backend due<2026-11-01`.
+	Query string
+}
+
+// Facets are the number of todos per value of the fields a sidebar groups
+// by. Counts are ordered most frequent first, then by value; todos without
+// a project are only counted in Total.
+type Facets struct {
+	Total   int64        `json:"total" example:"12"`
+	Status  []FacetCount `json:"status"`
+	Tag     []FacetCount `json:"tag"`
+	Project []FacetCount `json:"project"`
+}
+
+// FacetCount is the number of todos with one value of a facet.
+type FacetCount struct {
+	Value string `json:"value" example:"backend"`
+	Count int64  `json:"count" example:"4"`
+}
+
+// SearchResult is a todo matched by a full-text search.
+type SearchResult struct {
+	Todo Todo `json:"todo"`
+	// Rank is the relevance of the match; higher is better. Ranks are only
+	// comparable within one search.
+	Rank       float64          `json:"rank" example:"0.6"`
+	Highlights SearchHighlights `json:"highlights"`
+}
+
+// FuzzyMatch is a todo whose title resembles a fuzzy search query.
+type FuzzyMatch struct {
+	Todo Todo `json:"todo"`
+	// Score is the similarity of the title to the query, from 0 to 1.
+	Score float64 `json:"score" example:"0.58"`
+}
+
+// SearchHighlights show where a todo matched as HTML: the text is escaped
+// and matched terms are wrapped in <mark> and </mark>.
+type SearchHighlights struct {
+	Title string `json:"title" example:"Send the <mark>weekly report</mark>"`
+	// Description is an excerpt of about 35 words around the first match.
+	Description string `json:"description" example:"Attach the <mark>weekly report</mark> for the team"`
+}
+
+// ImportRecord is a todo read from one row of an imported file. Err reports
+// why the row could not be read or is not a valid todo.
+type ImportRecord struct {
+	// Row is the 1-based row of a CSV file, counting the header, or the
+	// 1-based index in a JSON array.
+	Row  int
+	Todo Todo
+	Err  error
+}
+
+type BulkOp string
+
+const (
+	BulkCreate BulkOp = "create"
+	BulkUpdate BulkOp = "update"
+	BulkDelete BulkOp = "delete"
+)
+
+// BulkOperation is one entry of a bulk request. ID is required for update
+// and delete, Todo for create and update.
+type BulkOperation struct {
+	Op   BulkOp    `json:"op" example:"update"`
+	ID   uuid.UUID `json:"id,omitempty" swaggertype:"string" format:"uuid"`
+	Todo *Todo     `json:"todo,omitempty"`
+}
+
+// BulkResult is the outcome of one BulkOperation. Todo is the stored todo
+// after a successful create or update; Err is nil on success.
+type BulkResult struct {
+	Op   BulkOp
+	ID   uuid.UUID
+	Todo *Todo
+	Err  error
 }
 
 func (t *Todo) BeforeCreate(tx *gorm.DB) (err error) {
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldViolation describes one rejected input field. Field is the JSON name
+// as seen by API clients, Rule the validation rule that failed and Param the
+// rule's argument, if any. Message is rendered in English and localized
+// before it reaches the client.
+type FieldViolation struct {
+	Field   string `json:"field" example:"title"`
+	Rule    string `json:"rule" example:"max"`
+	Param   string `json:"param,omitempty" example:"100"`
+	Message string `json:"message" example:"title must be at most 100 characters"`
+}
+
+// ValidationError carries the individual field violations of a rejected
+// todo. It matches ErrValidationFailed with errors.Is.
+type ValidationError struct {
+	Violations []FieldViolation
+}
+
+// NewValidationError builds a ValidationError from violations.
+func NewValidationError(violations ...FieldViolation) *ValidationError {
+	return &ValidationError{Violations: violations}
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.Message
+	}
+	return fmt.Sprintf("%s: %s", ErrValidationFailed, strings.Join(msgs, "; "))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidationFailed
+}
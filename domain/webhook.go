@@ -0,0 +1,144 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Webhook subscribes an external URL to todo events. Every event of one of
+// EventTypes is POSTed to URL and signed with Secret. A webhook is disabled
+// automatically after too many consecutive failed attempts; enabling it
+// again resets ConsecutiveFailures. Only its Owner can see and change it.
+type Webhook struct {
+	ID                  uuid.UUID       `json:"id" gorm:"type:uuid;primaryKey"`
+	URL                 string          `json:"url" gorm:"type:text;not null" validate:"required,max=2048,http_url"`
+	Secret              string          `json:"secret,omitempty" gorm:"type:varchar(255);not null" validate:"required,min=16,max=255"`
+	EventTypes          []TodoEventType `json:"event_types" gorm:"type:text;serializer:json" validate:"required,min=1,max=3,dive,oneof=todo.created todo.updated todo.deleted"`
+	Enabled             bool            `json:"enabled" gorm:"not null"`
+	Owner               string          `json:"owner,omitempty" gorm:"type:varchar(255);not null;default:'';index"`
+	ConsecutiveFailures int             `json:"consecutive_failures" gorm:"not null;default:0"`
+	DisabledAt          *time.Time      `json:"disabled_at,omitempty"`
+	CreatedAt           time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt           time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (w *Webhook) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return nil
+}
+
+// Wants reports whether w is subscribed to events of typ.
+func (w *Webhook) Wants(typ TodoEventType) bool {
+	for _, have := range w.EventTypes {
+		if have == typ {
+			return true
+		}
+	}
+	return false
+}
+
+type DeliveryStatus string
+
+const (
+	// DeliveryPending deliveries are retried until they succeed or run out
+	// of attempts.
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// WebhookDelivery is one event queued for one webhook. Payload is the exact
+// body sent on every attempt, so retries carry the same signature input. An
+// event is queued at most once per webhook, however often it is relayed.
+type WebhookDelivery struct {
+	ID            uuid.UUID       `json:"id" gorm:"type:uuid;primaryKey"`
+	WebhookID     uuid.UUID       `json:"webhook_id" gorm:"type:uuid;not null;uniqueIndex:idx_webhook_deliveries_event,priority:1"`
+	EventID       string          `json:"event_id" gorm:"type:varchar(64);not null;uniqueIndex:idx_webhook_deliveries_event,priority:2"`
+	EventType     TodoEventType   `json:"event_type" gorm:"type:varchar(32);not null"`
+	Payload       json.RawMessage `json:"payload" gorm:"type:text;not null;serializer:json" swaggertype:"object"`
+	Status        DeliveryStatus  `json:"status" gorm:"type:varchar(16);not null;index:idx_webhook_deliveries_due,priority:1"`
+	AttemptCount  int             `json:"attempt_count" gorm:"not null;default:0"`
+	NextAttemptAt time.Time       `json:"next_attempt_at" gorm:"not null;index:idx_webhook_deliveries_due,priority:2"`
+	LastError     string          `json:"last_error,omitempty" gorm:"type:text"`
+	CreatedAt     time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+	// Attempts is only loaded by WebhookRepository.Deliveries.
+	Attempts []WebhookAttempt `json:"attempts,omitempty" gorm:"foreignKey:DeliveryID;constraint:OnDelete:CASCADE"`
+}
+
+func (d *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
+
+// WebhookAttempt records one HTTP request made for a delivery. StatusCode is
+// 0 when no response was received.
+type WebhookAttempt struct {
+	ID          uint      `json:"-" gorm:"primaryKey"`
+	DeliveryID  uuid.UUID `json:"-" gorm:"type:uuid;not null;index"`
+	Number      int       `json:"number" gorm:"not null"`
+	StatusCode  int       `json:"status_code"`
+	Error       string    `json:"error,omitempty" gorm:"type:text"`
+	DurationMS  int64     `json:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at" gorm:"not null"`
+}
+
+// Succeeded reports whether the receiver accepted the delivery.
+func (a *WebhookAttempt) Succeeded() bool {
+	return a.Error == "" && a.StatusCode >= 200 && a.StatusCode < 300
+}
+
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *Webhook) error
+	Update(ctx context.Context, webhook *Webhook) error
+	FindByID(ctx context.Context, id uuid.UUID) (*Webhook, error)
+	// FindAll returns every webhook, oldest first, for dispatching events.
+	FindAll(ctx context.Context) ([]Webhook, error)
+	// FindByOwner returns the webhooks of owner, oldest first.
+	FindByOwner(ctx context.Context, owner string) ([]Webhook, error)
+	// Delete removes the webhook together with its deliveries.
+	Delete(ctx context.Context, id uuid.UUID) error
+	// Enqueue stores new pending deliveries, skipping those whose event is
+	// already queued for the webhook.
+	Enqueue(ctx context.Context, deliveries []WebhookDelivery) error
+	// ClaimDue returns up to limit pending deliveries of enabled webhooks
+	// whose next attempt is due at now, oldest first, and postpones them by
+	// lease so that no other worker picks them up while they are sent.
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]WebhookDelivery, error)
+	// RecordAttempt stores attempt and the new state of delivery in one
+	// transaction. It resets the webhook's consecutive failures when the
+	// attempt succeeded and increments them otherwise, disabling the
+	// webhook once they reach disableAfter. disabled reports whether this
+	// attempt disabled it.
+	RecordAttempt(ctx context.Context, delivery *WebhookDelivery, attempt *WebhookAttempt, disableAfter int) (disabled bool, err error)
+	// Deliveries returns the latest limit deliveries of a webhook, newest
+	// first, with their attempts.
+	Deliveries(ctx context.Context, webhookID uuid.UUID, limit int) ([]WebhookDelivery, error)
+}
+
+// WebhookUsecase manages the webhooks of a user. Webhooks owned by someone
+// else are reported as ErrNotFound.
+type WebhookUsecase interface {
+	// Create stores webhook for webhook.Owner.
+	Create(ctx context.Context, webhook *Webhook) error
+	// Update replaces a webhook of webhook.Owner.
+	Update(ctx context.Context, webhook *Webhook) error
+	Get(ctx context.Context, owner string, id uuid.UUID) (*Webhook, error)
+	List(ctx context.Context, owner string) ([]Webhook, error)
+	Delete(ctx context.Context, owner string, id uuid.UUID) error
+	Deliveries(ctx context.Context, owner string, id uuid.UUID) ([]WebhookDelivery, error)
+}
+
+// WebhookSender makes one delivery attempt. It returns the receiver's status
+// code, or an error when no response was received.
+type WebhookSender interface {
+	Send(ctx context.Context, webhook *Webhook, delivery *WebhookDelivery) (statusCode int, err error)
+}
@@ -0,0 +1,138 @@
+// Package eventbus delivers domain events to in-process subscribers.
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"todo-app/domain"
+	"todo-app/logging"
+)
+
+// Bus is the in-process domain.EventBus.
+type Bus struct {
+	buffer int
+	logger *slog.Logger
+	wg     sync.WaitGroup
+
+	mu     sync.RWMutex
+	sync   []subscriber
+	async  []asyncSubscriber
+	closed bool
+}
+
+type subscriber struct {
+	name    string
+	handler domain.EventHandler
+}
+
+type asyncSubscriber struct {
+	subscriber
+	queue chan delivery
+}
+
+type delivery struct {
+	ctx   context.Context
+	event domain.DomainEvent
+}
+
+// New returns a Bus whose asynchronous subscribers may fall buffer events
+// behind before events are dropped for them.
+func New(buffer int, logger *slog.Logger) *Bus {
+	return &Bus{buffer: buffer, logger: logger}
+}
+
+var _ domain.EventBus = (*Bus)(nil)
+
+func (b *Bus) Subscribe(name string, handler domain.EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sync = append(b.sync, subscriber{name: name, handler: handler})
+}
+
+func (b *Bus) SubscribeAsync(name string, handler domain.EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	s := asyncSubscriber{
+		subscriber: subscriber{name: name, handler: handler},
+		queue:      make(chan delivery, b.buffer),
+	}
+	b.async = append(b.async, s)
+	b.wg.Add(1)
+	go b.run(s)
+}
+
+// Publish runs the synchronous subscribers for each event and queues it for
+// the asynchronous ones. Asynchronous handlers get a context that carries
+// ctx's values but is not cancelled with the request.
+func (b *Bus) Publish(ctx context.Context, events ...domain.DomainEvent) {
+	b.mu.RLock()
+	subs := b.sync
+	b.mu.RUnlock()
+
+	for _, event := range events {
+		for _, s := range subs {
+			b.handle(ctx, s, event)
+		}
+		b.enqueue(ctx, event)
+	}
+}
+
+func (b *Bus) enqueue(ctx context.Context, event domain.DomainEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return
+	}
+	d := delivery{ctx: context.WithoutCancel(ctx), event: event}
+	for _, s := range b.async {
+		select {
+		case s.queue <- d:
+		default:
+			b.log(ctx).WarnContext(ctx, "Event dropped for slow subscriber", "subscriber", s.name, "event", event.EventName())
+		}
+	}
+}
+
+// Close stops accepting events and waits until the asynchronous
+// subscribers have handled the ones already queued.
+func (b *Bus) Close() error {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		for _, s := range b.async {
+			close(s.queue)
+		}
+	}
+	b.mu.Unlock()
+	b.wg.Wait()
+	return nil
+}
+
+func (b *Bus) run(s asyncSubscriber) {
+	defer b.wg.Done()
+	for d := range s.queue {
+		b.handle(d.ctx, s.subscriber, d.event)
+	}
+}
+
+// handle runs one handler, logging its error or panic so a faulty
+// subscriber cannot fail the write that caused the event.
+func (b *Bus) handle(ctx context.Context, s subscriber, event domain.DomainEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.log(ctx).ErrorContext(ctx, "Event subscriber panicked", "subscriber", s.name, "event", event.EventName(), "panic", fmt.Sprint(r))
+		}
+	}()
+	if err := s.handler(ctx, event); err != nil {
+		b.log(ctx).ErrorContext(ctx, "Event subscriber failed", "subscriber", s.name, "event", event.EventName(), "error", err)
+	}
+}
+
+func (b *Bus) log(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx, b.logger)
+}
@@ -0,0 +1,88 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"todo-app/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBus_Subscribe(t *testing.T) {
+	bus := New(8, slog.Default())
+	var got []string
+	bus.Subscribe("failing", func(ctx context.Context, event domain.DomainEvent) error {
+		return errors.New("boom")
+	})
+	bus.Subscribe("panicking", func(ctx context.Context, event domain.DomainEvent) error {
+		panic("boom")
+	})
+	bus.Subscribe("names", func(ctx context.Context, event domain.DomainEvent) error {
+		got = append(got, event.EventName())
+		return nil
+	})
+	bus.Subscribe("status", domain.On(func(ctx context.Context, event domain.TodoStatusChangedEvent) error {
+		got = append(got, event.From+"->"+event.To)
+		return nil
+	}))
+
+	bus.Publish(context.Background(),
+		domain.TodoUpdatedEvent{Changed: []string{"status"}},
+		domain.TodoStatusChangedEvent{From: "IN_PROGRESS", To: "COMPLETED"},
+	)
+
+	assert.Equal(t, []string{"todo.updated", "todo.status_changed", "IN_PROGRESS->COMPLETED"}, got,
+		"handlers run in order before Publish returns, despite failing neighbours")
+}
+
+func TestBus_SubscribeAsync(t *testing.T) {
+	bus := New(8, slog.Default())
+	var mu sync.Mutex
+	var got []string
+	release := make(chan struct{})
+	bus.SubscribeAsync("slow", func(ctx context.Context, event domain.DomainEvent) error {
+		<-release
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, event.(domain.TodoCreatedEvent).Todo.Title)
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	for _, title := range []string{"a", "b", "c"} {
+		bus.Publish(ctx, domain.TodoCreatedEvent{Todo: domain.Todo{Title: title}})
+	}
+	cancel()
+	close(release)
+	require.NoError(t, bus.Close())
+
+	assert.Equal(t, []string{"a", "b", "c"}, got, "Close drains the queue in publish order")
+	bus.Publish(context.Background(), domain.TodoCreatedEvent{Todo: domain.Todo{Title: "late"}})
+	assert.Len(t, got, 3, "events after Close are ignored")
+}
+
+func TestBus_SubscribeAsync_DropsWhenFull(t *testing.T) {
+	bus := New(1, slog.Default())
+	started, release := make(chan struct{}), make(chan struct{})
+	var handled int
+	bus.SubscribeAsync("stuck", func(ctx context.Context, event domain.DomainEvent) error {
+		if handled == 0 {
+			close(started)
+			<-release
+		}
+		handled++
+		return nil
+	})
+
+	bus.Publish(context.Background(), domain.TodoDeletedEvent{})
+	<-started
+	bus.Publish(context.Background(), domain.TodoDeletedEvent{}, domain.TodoDeletedEvent{}, domain.TodoDeletedEvent{})
+	close(release)
+	require.NoError(t, bus.Close())
+
+	assert.Equal(t, 2, handled, "one in flight, one queued, the rest dropped")
+}
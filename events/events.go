@@ -0,0 +1,178 @@
+// Package events fans todo changes out to live subscribers and keeps a
+// bounded history so reconnecting clients can resume where they left off.
+package events
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"todo-app/domain"
+
+	"github.com/google/uuid"
+)
+
+// Broker is an in-process domain.TodoEventPublisher. Event IDs have the form
+// "<boot>-<seq>", where boot is unique per Broker, so an ID handed out by a
+// previous process is never mistaken for one of ours.
+type Broker struct {
+	boot             string
+	subscriberBuffer int
+	done             chan struct{}
+
+	mu     sync.Mutex
+	seq    uint64
+	replay []domain.TodoEvent // ring buffer of the most recent events
+	next   int                // index in replay the next event is written to
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+// NewBroker returns a Broker remembering the last replaySize events. Each
+// subscriber may fall subscriberBuffer events behind before it is dropped.
+func NewBroker(replaySize, subscriberBuffer int) *Broker {
+	return &Broker{
+		boot:             uuid.NewString()[:8],
+		subscriberBuffer: subscriberBuffer,
+		done:             make(chan struct{}),
+		replay:           make([]domain.TodoEvent, 0, replaySize),
+		subs:             make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscription receives every event published after it was created. C is
+// closed when the subscriber falls too far behind, is closed or the broker
+// shuts down; a client can then resume from the last event it saw.
+type Subscription struct {
+	C <-chan domain.TodoEvent
+
+	broker *Broker
+	ch     chan domain.TodoEvent
+	once   sync.Once
+}
+
+// Close stops the subscription. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.drop(s)
+}
+
+// Publish assigns event the next ID, records it for replay and sends it to
+// every subscriber. Subscribers whose buffer is full are dropped rather than
+// blocking the writer.
+func (b *Broker) Publish(_ context.Context, event domain.TodoEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+
+	b.seq++
+	event.ID = b.id(b.seq)
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+	b.remember(event)
+
+	for sub := range b.subs {
+		select {
+		case sub.ch <- event:
+		default:
+			b.drop(sub)
+		}
+	}
+}
+
+// Subscribe registers a subscriber and returns the events it missed after
+// lastEventID. An empty lastEventID replays nothing. ok is false when the
+// events after lastEventID are no longer all known, either because the ID
+// comes from another broker or because it has left the replay buffer; the
+// caller should then reload its state.
+func (b *Broker) Subscribe(lastEventID string) (sub *Subscription, missed []domain.TodoEvent, ok bool) {
+	ch := make(chan domain.TodoEvent, b.subscriberBuffer)
+	sub = &Subscription{C: ch, broker: b, ch: ch}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(ch)
+		return sub, nil, true
+	}
+	b.subs[sub] = struct{}{}
+
+	if lastEventID == "" {
+		return sub, nil, true
+	}
+	missed, ok = b.since(lastEventID)
+	return sub, missed, ok
+}
+
+// Close ends every subscription and ignores later events. It lets streaming
+// requests finish before the server waits for in-flight requests to drain.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	close(b.done)
+	for sub := range b.subs {
+		b.drop(sub)
+	}
+}
+
+// Done is closed when the broker is closed.
+func (b *Broker) Done() <-chan struct{} {
+	return b.done
+}
+
+// drop removes sub and closes its channel. b.mu must be held.
+func (b *Broker) drop(sub *Subscription) {
+	delete(b.subs, sub)
+	sub.once.Do(func() { close(sub.ch) })
+}
+
+func (b *Broker) id(seq uint64) string {
+	return fmt.Sprintf("%s-%d", b.boot, seq)
+}
+
+// remember appends event to the replay buffer, overwriting the oldest event
+// once it is full. b.mu must be held.
+func (b *Broker) remember(event domain.TodoEvent) {
+	if cap(b.replay) == 0 {
+		return
+	}
+	if len(b.replay) < cap(b.replay) {
+		b.replay = append(b.replay, event)
+		return
+	}
+	b.replay[b.next] = event
+	b.next = (b.next + 1) % len(b.replay)
+}
+
+// since returns the buffered events after lastEventID, oldest first.
+// b.mu must be held.
+func (b *Broker) since(lastEventID string) ([]domain.TodoEvent, bool) {
+	boot, rawSeq, found := strings.Cut(lastEventID, "-")
+	if !found || boot != b.boot {
+		return nil, false
+	}
+	seq, err := strconv.ParseUint(rawSeq, 10, 64)
+	if err != nil || seq > b.seq {
+		return nil, false
+	}
+
+	missing := b.seq - seq
+	if missing > uint64(len(b.replay)) {
+		return nil, false
+	}
+	events := make([]domain.TodoEvent, 0, missing)
+	for i := len(b.replay) - int(missing); i < len(b.replay); i++ {
+		events = append(events, b.replay[(b.next+i)%len(b.replay)])
+	}
+	return events, true
+}
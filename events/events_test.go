@@ -0,0 +1,136 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"todo-app/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func publish(b *Broker, titles ...string) {
+	for _, title := range titles {
+		b.Publish(context.Background(), domain.TodoEvent{Type: domain.TodoCreated, Todo: domain.Todo{Title: title}})
+	}
+}
+
+func titles(events []domain.TodoEvent) []string {
+	out := make([]string, len(events))
+	for i, e := range events {
+		out[i] = e.Todo.Title
+	}
+	return out
+}
+
+func TestBroker_PublishDeliversToSubscribers(t *testing.T) {
+	b := NewBroker(10, 10)
+	sub, missed, ok := b.Subscribe("")
+	require.True(t, ok)
+	assert.Empty(t, missed)
+
+	publish(b, "first")
+
+	event := <-sub.C
+	assert.Equal(t, "first", event.Todo.Title)
+	assert.Equal(t, b.boot+"-1", event.ID)
+	assert.False(t, event.OccurredAt.IsZero())
+}
+
+func TestBroker_SubscribeReplaysMissedEvents(t *testing.T) {
+	b := NewBroker(3, 10)
+	publish(b, "a", "b", "c", "d", "e")
+
+	t.Run("within buffer", func(t *testing.T) {
+		_, missed, ok := b.Subscribe(b.boot + "-3")
+		require.True(t, ok)
+		assert.Equal(t, []string{"d", "e"}, titles(missed))
+	})
+
+	t.Run("up to date", func(t *testing.T) {
+		_, missed, ok := b.Subscribe(b.boot + "-5")
+		require.True(t, ok)
+		assert.Empty(t, missed)
+	})
+
+	t.Run("oldest buffered", func(t *testing.T) {
+		_, missed, ok := b.Subscribe(b.boot + "-2")
+		require.True(t, ok)
+		assert.Equal(t, []string{"c", "d", "e"}, titles(missed))
+	})
+
+	for name, id := range map[string]string{
+		"evicted":      b.boot + "-1",
+		"future":       b.boot + "-9",
+		"other broker": "deadbeef-4",
+		"malformed":    "garbage",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, missed, ok := b.Subscribe(id)
+			assert.False(t, ok)
+			assert.Empty(t, missed)
+		})
+	}
+}
+
+func TestBroker_DropsSlowSubscribers(t *testing.T) {
+	b := NewBroker(10, 1)
+	slow, _, _ := b.Subscribe("")
+
+	publish(b, "fits", "overflows")
+
+	assert.Equal(t, "fits", (<-slow.C).Todo.Title)
+	_, open := <-slow.C
+	assert.False(t, open, "a subscriber that falls behind is disconnected")
+}
+
+func TestBroker_Close(t *testing.T) {
+	b := NewBroker(10, 10)
+	sub, _, _ := b.Subscribe("")
+
+	b.Close()
+	b.Close()
+	publish(b, "ignored")
+	sub.Close()
+	<-b.Done()
+
+	_, open := <-sub.C
+	assert.False(t, open)
+	late, _, _ := b.Subscribe("")
+	_, open = <-late.C
+	assert.False(t, open)
+}
+
+func TestFilter_Match(t *testing.T) {
+	event := domain.TodoEvent{Todo: domain.Todo{Project: "home", Status: "IN_PROGRESS", Tags: []string{"errand"}}}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"empty", Filter{}, true},
+		{"project", Filter{Projects: []string{"work", "home"}}, true},
+		{"other project", Filter{Projects: []string{"work"}}, false},
+		{"tag", Filter{Tags: []string{"urgent", "errand"}}, true},
+		{"other tag", Filter{Tags: []string{"urgent"}}, false},
+		{"status", Filter{Statuses: []string{"IN_PROGRESS"}}, true},
+		{"all match", Filter{Projects: []string{"home"}, Tags: []string{"errand"}, Statuses: []string{"IN_PROGRESS"}}, true},
+		{"one field misses", Filter{Projects: []string{"home"}, Statuses: []string{"COMPLETED"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Match(event))
+		})
+	}
+
+	t.Run("update leaving the selection", func(t *testing.T) {
+		moved := domain.TodoEvent{
+			Type:     domain.TodoUpdated,
+			Todo:     domain.Todo{Project: "work"},
+			Previous: &domain.Todo{Project: "home"},
+		}
+		assert.True(t, Filter{Projects: []string{"home"}}.Match(moved))
+		assert.False(t, Filter{Projects: []string{"gym"}}.Match(moved))
+	})
+}
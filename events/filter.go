@@ -0,0 +1,37 @@
+package events
+
+import (
+	"slices"
+	"todo-app/domain"
+)
+
+// Filter selects events by the todo they concern. A todo matches when it
+// matches at least one value of every non-empty field; an empty Filter
+// matches everything. An update matches if the todo matched before or after
+// it, so subscribers also learn when a todo leaves their selection.
+type Filter struct {
+	Projects []string
+	Tags     []string
+	Statuses []string
+}
+
+// Match reports whether event passes f.
+func (f Filter) Match(event domain.TodoEvent) bool {
+	if event.Previous != nil && f.matchTodo(event.Previous) {
+		return true
+	}
+	return f.matchTodo(&event.Todo)
+}
+
+func (f Filter) matchTodo(todo *domain.Todo) bool {
+	if len(f.Projects) > 0 && !slices.Contains(f.Projects, todo.Project) {
+		return false
+	}
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, todo.Status) {
+		return false
+	}
+	if len(f.Tags) > 0 && !slices.ContainsFunc(f.Tags, todo.HasTag) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,325 @@
+// Package export writes todos in the file formats of GET /todos/export.
+// Writers encode each todo as it is written and emit nothing before the
+// first one, so a caller can still answer with an error until then.
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+	"todo-app/domain"
+	"todo-app/todotxt"
+)
+
+// Format is a file format todos can be exported in.
+type Format struct {
+	Name        string
+	ContentType string
+	Extension   string
+	newWriter   func(w io.Writer, columns []string) Writer
+}
+
+// Writer encodes todos to an underlying io.Writer.
+type Writer interface {
+	Write(todo domain.Todo) error
+	// Close writes what follows the last todo and flushes. It must be
+	// called even if no todo was written.
+	Close() error
+}
+
+// Formats are the supported formats; the first is the default.
+var Formats = []Format{
+	{Name: "csv", ContentType: "text/csv; charset=utf-8", Extension: "csv", newWriter: newCSVWriter},
+	{Name: "json", ContentType: "application/json; charset=utf-8", Extension: "json", newWriter: newJSONWriter},
+	{Name: "ndjson", ContentType: "application/x-ndjson", Extension: "ndjson", newWriter: newNDJSONWriter},
+	{Name: "markdown", ContentType: "text/markdown; charset=utf-8", Extension: "md", newWriter: newMarkdownWriter},
+	{Name: "todotxt", ContentType: "text/plain; charset=utf-8", Extension: "txt", newWriter: newTodoTxtWriter},
+}
+
+// Lookup returns the format called name, or the default for an empty name.
+func Lookup(name string) (Format, error) {
+	if name == "" {
+		return Formats[0], nil
+	}
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		if f.Name == name {
+			return f, nil
+		}
+		names[i] = f.Name
+	}
+	return Format{}, domain.NewValidationError(domain.FieldViolation{Field: "format", Rule: "oneof", Param: strings.Join(names, " ")})
+}
+
+// NewWriter returns a Writer encoding to w. columns select and order the
+// fields of CSV rows; the other formats ignore them.
+func (f Format) NewWriter(w io.Writer, columns []string) Writer {
+	return f.newWriter(w, columns)
+}
+
+// Columns are the CSV columns, named like the JSON fields of a todo. The
+// default columns are all but image, which holds base64 data.
+var Columns = []string{"id", "title", "description", "status", "project", "tags", "due_date", "created_at", "updated_at", "completed_at", "image"}
+
+// DefaultColumns are the CSV columns exported when none are selected.
+var DefaultColumns = Columns[:len(Columns)-1]
+
+// ParseColumns parses a comma separated list of Columns, returning
+// DefaultColumns for an empty list.
+func ParseColumns(list string) ([]string, error) {
+	if strings.TrimSpace(list) == "" {
+		return DefaultColumns, nil
+	}
+	var columns []string
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if !isColumn(name) {
+			return nil, domain.NewValidationError(domain.FieldViolation{Field: "columns", Rule: "oneof", Param: strings.Join(Columns, " ")})
+		}
+		columns = append(columns, name)
+	}
+	return columns, nil
+}
+
+func isColumn(name string) bool {
+	for _, column := range Columns {
+		if column == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Column returns the CSV cell of todo for column. Tags are joined with
+// commas, times are RFC 3339 in UTC and a missing due or completion date is
+// empty. Cells are escaped with EscapeCell.
+func Column(todo domain.Todo, column string) string {
+	return EscapeCell(cell(todo, column))
+}
+
+func cell(todo domain.Todo, column string) string {
+	switch column {
+	case "id":
+		return todo.ID.String()
+	case "title":
+		return todo.Title
+	case "description":
+		return todo.Description
+	case "status":
+		return todo.Status
+	case "project":
+		return todo.Project
+	case "tags":
+		return strings.Join(todo.Tags, ",")
+	case "due_date":
+		if todo.DueDate == nil {
+			return ""
+		}
+		return formatTime(*todo.DueDate)
+	case "created_at":
+		return formatTime(todo.CreatedAt)
+	case "updated_at":
+		return formatTime(todo.UpdatedAt)
+	case "completed_at":
+		if todo.CompletedAt == nil {
+			return ""
+		}
+		return formatTime(*todo.CompletedAt)
+	case "image":
+		return todo.Image
+	}
+	return ""
+}
+
+// formulaPrefixes start a cell that spreadsheets evaluate as a formula.
+const formulaPrefixes = "=+-@\t\r"
+
+// EscapeCell prefixes cell with a quote if a spreadsheet would otherwise
+// run it as a formula, so a title such as =HYPERLINK(...) stays text when
+// an export is opened. Cells that already look escaped get another quote,
+// so UnescapeCell restores every cell exactly.
+func EscapeCell(cell string) string {
+	if needsEscape(cell) {
+		return "'" + cell
+	}
+	return cell
+}
+
+// UnescapeCell reverses EscapeCell.
+func UnescapeCell(cell string) string {
+	if strings.HasPrefix(cell, "'") && needsEscape(cell[1:]) {
+		return cell[1:]
+	}
+	return cell
+}
+
+func needsEscape(cell string) bool {
+	cell = strings.TrimLeft(cell, "'")
+	return cell != "" && strings.IndexByte(formulaPrefixes, cell[0]) >= 0
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+type csvWriter struct {
+	w       *csv.Writer
+	columns []string
+	started bool
+}
+
+func newCSVWriter(w io.Writer, columns []string) Writer {
+	if len(columns) == 0 {
+		columns = DefaultColumns
+	}
+	return &csvWriter{w: csv.NewWriter(w), columns: columns}
+}
+
+func (c *csvWriter) start() error {
+	if c.started {
+		return nil
+	}
+	c.started = true
+	return c.w.Write(c.columns)
+}
+
+func (c *csvWriter) Write(todo domain.Todo) error {
+	if err := c.start(); err != nil {
+		return err
+	}
+	record := make([]string, len(c.columns))
+	for i, column := range c.columns {
+		record[i] = Column(todo, column)
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) Close() error {
+	if err := c.start(); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonWriter writes a JSON array with one todo per line.
+type jsonWriter struct {
+	w     *bufio.Writer
+	count int
+}
+
+func newJSONWriter(w io.Writer, _ []string) Writer {
+	return &jsonWriter{w: bufio.NewWriter(w)}
+}
+
+func (j *jsonWriter) Write(todo domain.Todo) error {
+	data, err := json.Marshal(todo)
+	if err != nil {
+		return err
+	}
+	sep := ",\n"
+	if j.count == 0 {
+		sep = "[\n"
+	}
+	j.count++
+	if _, err := j.w.WriteString(sep); err != nil {
+		return err
+	}
+	_, err = j.w.Write(data)
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	end := "\n]\n"
+	if j.count == 0 {
+		end = "[]\n"
+	}
+	if _, err := j.w.WriteString(end); err != nil {
+		return err
+	}
+	return j.w.Flush()
+}
+
+type ndjsonWriter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func newNDJSONWriter(w io.Writer, _ []string) Writer {
+	buf := bufio.NewWriter(w)
+	return &ndjsonWriter{w: buf, enc: json.NewEncoder(buf)}
+}
+
+func (n *ndjsonWriter) Write(todo domain.Todo) error {
+	return n.enc.Encode(todo)
+}
+
+func (n *ndjsonWriter) Close() error {
+	return n.w.Flush()
+}
+
+// markdownWriter writes a table of the fields people read; IDs and
+// timestamps other than the due date are left out.
+type markdownWriter struct {
+	w       *bufio.Writer
+	started bool
+}
+
+func newMarkdownWriter(w io.Writer, _ []string) Writer {
+	return &markdownWriter{w: bufio.NewWriter(w)}
+}
+
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "|", `\|`, "\r\n", " ", "\n", " ", "\r", " ")
+
+func (m *markdownWriter) start() error {
+	if m.started {
+		return nil
+	}
+	m.started = true
+	_, err := m.w.WriteString("| Title | Status | Project | Tags | Due |\n| --- | --- | --- | --- | --- |\n")
+	return err
+}
+
+func (m *markdownWriter) Write(todo domain.Todo) error {
+	if err := m.start(); err != nil {
+		return err
+	}
+	due := ""
+	if todo.DueDate != nil {
+		due = todo.DueDate.UTC().Format(time.DateOnly)
+	}
+	cells := []string{todo.Title, todo.Status, todo.Project, strings.Join(todo.Tags, ", "), due}
+	for i, cell := range cells {
+		cells[i] = markdownEscaper.Replace(cell)
+	}
+	_, err := m.w.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	return err
+}
+
+func (m *markdownWriter) Close() error {
+	if err := m.start(); err != nil {
+		return err
+	}
+	return m.w.Flush()
+}
+
+// todoTxtWriter writes one todo.txt line per todo, see todotxt.FromTodo.
+type todoTxtWriter struct {
+	w *bufio.Writer
+}
+
+func newTodoTxtWriter(w io.Writer, _ []string) Writer {
+	return &todoTxtWriter{w: bufio.NewWriter(w)}
+}
+
+func (t *todoTxtWriter) Write(todo domain.Todo) error {
+	_, err := t.w.WriteString(todotxt.FromTodo(todo).String() + "\n")
+	return err
+}
+
+func (t *todoTxtWriter) Close() error {
+	return t.w.Flush()
+}
@@ -0,0 +1,150 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+	"todo-app/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleTodos() []domain.Todo {
+	due := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	created := time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC)
+	return []domain.Todo{
+		{
+			ID:        uuid.MustParse("123e4567-e89b-12d3-a456-426614174000"),
+			Title:     "Ship release",
+			Status:    "IN_PROGRESS",
+			Project:   "launch",
+			Tags:      []string{"backend", "urgent"},
+			DueDate:   &due,
+			CreatedAt: created,
+			UpdatedAt: created,
+		},
+		{
+			ID:          uuid.MustParse("123e4567-e89b-12d3-a456-426614174001"),
+			Title:       "Compare a | b",
+			Description: "Line one\nline two, with \"quotes\"",
+			Status:      "COMPLETED",
+			CreatedAt:   created,
+			UpdatedAt:   created,
+			CompletedAt: &created,
+		},
+	}
+}
+
+func write(t *testing.T, format string, columns []string, todos []domain.Todo) string {
+	t.Helper()
+	f, err := Lookup(format)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	w := f.NewWriter(&buf, columns)
+	for _, todo := range todos {
+		require.NoError(t, w.Write(todo))
+	}
+	require.NoError(t, w.Close())
+	return buf.String()
+}
+
+func TestLookup(t *testing.T) {
+	f, err := Lookup("")
+	require.NoError(t, err)
+	assert.Equal(t, "csv", f.Name)
+
+	_, err = Lookup("xlsx")
+	var validationErr *domain.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []domain.FieldViolation{{Field: "format", Rule: "oneof", Param: "csv json ndjson markdown todotxt"}}, validationErr.Violations)
+}
+
+func TestParseColumns(t *testing.T) {
+	columns, err := ParseColumns("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultColumns, columns)
+	assert.NotContains(t, columns, "image")
+
+	columns, err = ParseColumns(" title, status,image")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"title", "status", "image"}, columns)
+
+	_, err = ParseColumns("title,priority")
+	var validationErr *domain.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "columns", validationErr.Violations[0].Field)
+}
+
+func TestCSV(t *testing.T) {
+	got := write(t, "csv", []string{"title", "tags", "due_date", "description"}, sampleTodos())
+
+	assert.Equal(t, "title,tags,due_date,description\n"+
+		"Ship release,\"backend,urgent\",2026-11-01T00:00:00Z,\n"+
+		"Compare a | b,,,\"Line one\nline two, with \"\"quotes\"\"\"\n", got)
+	assert.Equal(t, "id,title,description,status,project,tags,due_date,created_at,updated_at,completed_at\n", write(t, "csv", nil, nil))
+	assert.Equal(t, "title,completed_at\nShip release,\nCompare a | b,2026-10-01T09:30:00Z\n",
+		write(t, "csv", []string{"title", "completed_at"}, sampleTodos()))
+}
+
+func TestCSVEscapesFormulas(t *testing.T) {
+	todos := []domain.Todo{
+		{Title: `=HYPERLINK("https://example.com","x")`, Tags: []string{"@ops"}},
+		{Title: "+cmd|' /C calc'!A0", Description: "-1"},
+		{Title: "\tTabbed", Description: "\rReturn"},
+		{Title: "a = b", Description: "'quoted"},
+	}
+
+	got := write(t, "csv", []string{"title", "tags", "description"}, todos)
+
+	assert.Equal(t, "title,tags,description\n"+
+		"\"'=HYPERLINK(\"\"https://example.com\"\",\"\"x\"\")\",'@ops,\n"+
+		"'+cmd|' /C calc'!A0,,'-1\n"+
+		"'\tTabbed,,\"'\rReturn\"\n"+
+		"a = b,,'quoted\n", got)
+}
+
+func TestEscapeCell(t *testing.T) {
+	for _, cell := range []string{"", "=1+1", "+1", "-1", "@a", "\tx", "\rx", "'=x", "plain", "'quoted"} {
+		assert.Equal(t, cell, UnescapeCell(EscapeCell(cell)), cell)
+	}
+	assert.Equal(t, "'=1+1", EscapeCell("=1+1"))
+	assert.Equal(t, "plain", EscapeCell("plain"))
+}
+
+func TestJSON(t *testing.T) {
+	todos := sampleTodos()
+	var got []domain.Todo
+	require.NoError(t, json.Unmarshal([]byte(write(t, "json", nil, todos)), &got))
+	assert.Equal(t, todos, got)
+	assert.Equal(t, "[]\n", write(t, "json", nil, nil))
+}
+
+func TestNDJSON(t *testing.T) {
+	got := write(t, "ndjson", nil, sampleTodos())
+
+	lines := bytes.Split(bytes.TrimSpace([]byte(got)), []byte("\n"))
+	require.Len(t, lines, 2)
+	var todo domain.Todo
+	require.NoError(t, json.Unmarshal(lines[1], &todo))
+	assert.Equal(t, "Compare a | b", todo.Title)
+	assert.Empty(t, write(t, "ndjson", nil, nil))
+}
+
+func TestMarkdown(t *testing.T) {
+	got := write(t, "markdown", nil, sampleTodos())
+
+	assert.Equal(t, "| Title | Status | Project | Tags | Due |\n"+
+		"| --- | --- | --- | --- | --- |\n"+
+		"| Ship release | IN_PROGRESS | launch | backend, urgent | 2026-11-01 |\n"+
+		"| Compare a \\| b | COMPLETED |  |  |  |\n", got)
+}
+
+func TestTodoTxt(t *testing.T) {
+	got := write(t, "todotxt", nil, sampleTodos())
+
+	assert.Equal(t, "2026-10-01 Ship release +launch @backend @urgent due:2026-11-01\n"+
+		"x 2026-10-01 2026-10-01 Compare a | b\n", got)
+}
@@ -0,0 +1,130 @@
+// Package health aggregates dependency checks into liveness and readiness
+// reports for orchestrator probes.
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+
+	StatusReady        = "ready"
+	StatusNotReady     = "not_ready"
+	StatusShuttingDown = "shutting_down"
+)
+
+// CheckFunc reports a dependency as healthy by returning nil. It must honour
+// ctx cancellation.
+type CheckFunc func(ctx context.Context) error
+
+type CheckResult struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+type Report struct {
+	Status    string                 `json:"status"`
+	CheckedAt time.Time              `json:"checked_at"`
+	Checks    map[string]CheckResult `json:"checks"`
+}
+
+// Ready reports whether every check passed and the process is not shutting
+// down.
+func (r Report) Ready() bool {
+	return r.Status == StatusReady
+}
+
+type check struct {
+	name string
+	fn   CheckFunc
+}
+
+// Registry holds the readiness checks and the shutdown flag.
+type Registry struct {
+	timeout      time.Duration
+	mu           sync.RWMutex
+	checks       []check
+	shuttingDown atomic.Bool
+}
+
+// NewRegistry returns a registry whose checks each get timeout to complete.
+func NewRegistry(timeout time.Duration) *Registry {
+	return &Registry{timeout: timeout}
+}
+
+// Register adds a named readiness check.
+func (r *Registry) Register(name string, fn CheckFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, check{name: name, fn: fn})
+}
+
+// SetShuttingDown marks the process as draining so readiness fails and load
+// balancers stop routing new traffic to it.
+func (r *Registry) SetShuttingDown() {
+	r.shuttingDown.Store(true)
+}
+
+// Check runs every registered check concurrently and aggregates the results.
+func (r *Registry) Check(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := append([]check(nil), r.checks...)
+	r.mu.RUnlock()
+
+	report := Report{
+		Status:    StatusReady,
+		CheckedAt: time.Now().UTC(),
+		Checks:    make(map[string]CheckResult, len(checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range checks {
+		wg.Add(1)
+		go func(c check) {
+			defer wg.Done()
+			result := r.run(ctx, c)
+			mu.Lock()
+			report.Checks[c.name] = result
+			if result.Status != StatusUp {
+				report.Status = StatusNotReady
+			}
+			mu.Unlock()
+		}(c)
+	}
+	wg.Wait()
+
+	if r.shuttingDown.Load() {
+		report.Status = StatusShuttingDown
+	}
+	return report
+}
+
+func (r *Registry) run(ctx context.Context, c check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	errCh := make(chan error, 1)
+	go func() { errCh <- c.fn(ctx) }()
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := CheckResult{Status: StatusUp, DurationMS: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
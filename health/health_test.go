@@ -0,0 +1,61 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Check(t *testing.T) {
+	t.Run("all checks up", func(t *testing.T) {
+		r := NewRegistry(time.Second)
+		r.Register("database", func(ctx context.Context) error { return nil })
+
+		report := r.Check(context.Background())
+
+		assert.True(t, report.Ready())
+		assert.Equal(t, StatusUp, report.Checks["database"].Status)
+	})
+
+	t.Run("failing check", func(t *testing.T) {
+		r := NewRegistry(time.Second)
+		r.Register("database", func(ctx context.Context) error { return nil })
+		r.Register("cache", func(ctx context.Context) error { return errors.New("connection refused") })
+
+		report := r.Check(context.Background())
+
+		assert.False(t, report.Ready())
+		assert.Equal(t, StatusNotReady, report.Status)
+		assert.Equal(t, StatusDown, report.Checks["cache"].Status)
+		assert.Equal(t, "connection refused", report.Checks["cache"].Error)
+	})
+
+	t.Run("slow check times out", func(t *testing.T) {
+		r := NewRegistry(20 * time.Millisecond)
+		r.Register("slow", func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		})
+
+		start := time.Now()
+		report := r.Check(context.Background())
+
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+		assert.Equal(t, StatusDown, report.Checks["slow"].Status)
+		assert.Contains(t, report.Checks["slow"].Error, "deadline exceeded")
+	})
+
+	t.Run("shutting down", func(t *testing.T) {
+		r := NewRegistry(time.Second)
+		r.Register("database", func(ctx context.Context) error { return nil })
+		r.SetShuttingDown()
+
+		report := r.Check(context.Background())
+
+		assert.False(t, report.Ready())
+		assert.Equal(t, StatusShuttingDown, report.Status)
+	})
+}
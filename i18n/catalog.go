@@ -0,0 +1,138 @@
+package i18n
+
+// catalogs maps locale to message key to text. {0}, {1}, ... are replaced
+// by the parameters passed to T. Rule messages receive the JSON field name
+// and the rule parameter; problem keys follow problem codes and realtime
+// keys the error codes of the WebSocket protocol.
+var catalogs = map[string]map[string]string{
+	"en": {
+		"rule.required":   "{0} is required",
+		"rule.max":        "{0} must be at most {1} characters",
+		"rule.min":        "{0} must be at least {1} characters",
+		"rule.max_items":  "{0} must have at most {1} items",
+		"rule.min_items":  "{0} must have at least {1} items",
+		"rule.oneof":      "{0} must be one of: {1}",
+		"rule.base64":     "{0} must be base64 encoded",
+		"rule.uuid":       "{0} must be a valid UUID",
+		"rule.printascii": "{0} must contain only printable ASCII characters",
+		"rule.http_url":   "{0} must be an http or https URL",
+		"rule.number":     "{0} must be a whole number",
+		"rule.numeric":    "{0} must be a number",
+		"rule.boolean":    "{0} must be true or false",
+		"rule.datetime":   "{0} must be a date such as 2026-11-01 or an RFC 3339 time",
+		"rule.timezone":   "{0} must be an IANA time zone such as Asia/Bangkok",
+		"rule.type":       "{0} must be a JSON {1}",
+		"rule.gte":        "{0} must be at least {1}",
+		"rule.lte":        "{0} must be at most {1}",
+		"rule.default":    "{0} is invalid ({1})",
+
+		"problem.validation_error.title":                 "Validation failed",
+		"problem.validation_error.detail":                "The request contains invalid fields.",
+		"problem.invalid_request_body.title":             "Invalid request body",
+		"problem.invalid_request_body.detail":            "The request body is not valid JSON for this resource.",
+		"problem.request_body_too_large.title":           "Request body too large",
+		"problem.request_body_too_large.detail":          "The request body exceeds the allowed size.",
+		"problem.invalid_id.title":                       "Invalid ID",
+		"problem.invalid_id.detail":                      "The ID in the path is not a valid UUID.",
+		"problem.not_found.title":                        "Not found",
+		"problem.not_found.detail":                       "The requested resource was not found.",
+		"problem.internal_error.title":                   "Internal server error",
+		"problem.internal_error.detail":                  "An unexpected error occurred. Please try again later.",
+		"problem.bulk_aborted.title":                     "Operation not applied",
+		"problem.bulk_aborted.detail":                    "Another operation in the atomic batch failed, so this one was rolled back.",
+		"problem.too_many_operations.title":              "Too many operations",
+		"problem.too_many_operations.detail":             "A bulk request may contain at most {0} operations.",
+		"problem.idempotency_key_reused.title":           "Idempotency key reused",
+		"problem.idempotency_key_reused.detail":          "The Idempotency-Key was already used for a different request.",
+		"problem.idempotency_request_in_progress.title":  "Request in progress",
+		"problem.idempotency_request_in_progress.detail": "A request with this Idempotency-Key is still being processed. Retry later.",
+		"problem.rate_limited.title":                     "Too many requests",
+		"problem.rate_limited.detail":                    "The rate limit was exceeded. Retry after the number of seconds in the Retry-After header.",
+		"problem.unauthorized.title":                     "Unauthorized",
+		"problem.unauthorized.detail":                    "This endpoint requires an authenticated user.",
+		"problem.invalid_query.title":                    "Invalid query",
+		"problem.invalid_query.detail":                   "The filter query could not be parsed.",
+		"problem.read_only.title":                        "Read-only resource",
+		"problem.read_only.detail":                       "Built-in smart lists cannot be changed or deleted.",
+
+		"query.unexpected_token":   "At position {0}: unexpected \"{1}\".",
+		"query.unexpected_end":     "At position {0}: the query ends where a term is expected.",
+		"query.unterminated_quote": "At position {0}: the quote is never closed.",
+		"query.unclosed_paren":     "At position {0}: the parenthesis is never closed.",
+		"query.missing_value":      "At position {0}: \"{1}\" needs a value.",
+		"query.unknown_field":      "At position {0}: unknown field \"{1}\". Use status, project, tag, title, description, due, created, updated or completed.",
+		"query.invalid_operator":   "At position {0}: the operator \"{1}\" cannot be used with this field or value.",
+		"query.invalid_value":      "At position {0}: invalid status \"{1}\". Use IN_PROGRESS or COMPLETED.",
+		"query.invalid_date":       "At position {0}: invalid date \"{1}\". Use YYYY-MM-DD, today, yesterday, tomorrow, today+Nd, today-Nd or startofweek.",
+
+		"realtime.invalid_message":        "The message is not valid JSON for the realtime protocol.",
+		"realtime.unknown_type":           "Unknown message type {0}.",
+		"realtime.invalid_list":           "list must be between 1 and {0} characters",
+		"realtime.too_many_subscriptions": "A connection may follow at most {0} lists.",
+	},
+	"th": {
+		"rule.required":   "จำเป็นต้องระบุ {0}",
+		"rule.max":        "{0} ต้องมีความยาวไม่เกิน {1} ตัวอักษร",
+		"rule.min":        "{0} ต้องมีความยาวอย่างน้อย {1} ตัวอักษร",
+		"rule.max_items":  "{0} ต้องมีไม่เกิน {1} รายการ",
+		"rule.min_items":  "{0} ต้องมีอย่างน้อย {1} รายการ",
+		"rule.oneof":      "{0} ต้องเป็นค่าใดค่าหนึ่งต่อไปนี้: {1}",
+		"rule.base64":     "{0} ต้องเข้ารหัสแบบ base64",
+		"rule.uuid":       "{0} ต้องเป็น UUID ที่ถูกต้อง",
+		"rule.printascii": "{0} ต้องประกอบด้วยอักขระ ASCII ที่พิมพ์ได้เท่านั้น",
+		"rule.http_url":   "{0} ต้องเป็น URL แบบ http หรือ https",
+		"rule.number":     "{0} ต้องเป็นจำนวนเต็ม",
+		"rule.numeric":    "{0} ต้องเป็นตัวเลข",
+		"rule.boolean":    "{0} ต้องเป็น true หรือ false",
+		"rule.datetime":   "{0} ต้องเป็นวันที่ เช่น 2026-11-01 หรือเวลาในรูปแบบ RFC 3339",
+		"rule.timezone":   "{0} ต้องเป็นเขตเวลา IANA เช่น Asia/Bangkok",
+		"rule.type":       "{0} ต้องเป็น JSON ชนิด {1}",
+		"rule.gte":        "{0} ต้องมีค่าอย่างน้อย {1}",
+		"rule.lte":        "{0} ต้องมีค่าไม่เกิน {1}",
+		"rule.default":    "{0} ไม่ถูกต้อง ({1})",
+
+		"problem.validation_error.title":                 "ข้อมูลไม่ถูกต้อง",
+		"problem.validation_error.detail":                "คำขอมีฟิลด์ที่ไม่ถูกต้อง",
+		"problem.invalid_request_body.title":             "เนื้อหาคำขอไม่ถูกต้อง",
+		"problem.invalid_request_body.detail":            "เนื้อหาคำขอไม่ใช่ JSON ที่ถูกต้องสำหรับข้อมูลนี้",
+		"problem.request_body_too_large.title":           "เนื้อหาคำขอมีขนาดใหญ่เกินไป",
+		"problem.request_body_too_large.detail":          "เนื้อหาคำขอมีขนาดเกินกว่าที่อนุญาต",
+		"problem.invalid_id.title":                       "รหัสไม่ถูกต้อง",
+		"problem.invalid_id.detail":                      "รหัสใน path ไม่ใช่ UUID ที่ถูกต้อง",
+		"problem.not_found.title":                        "ไม่พบข้อมูล",
+		"problem.not_found.detail":                       "ไม่พบข้อมูลที่ร้องขอ",
+		"problem.internal_error.title":                   "เกิดข้อผิดพลาดภายในเซิร์ฟเวอร์",
+		"problem.internal_error.detail":                  "เกิดข้อผิดพลาดที่ไม่คาดคิด กรุณาลองใหม่อีกครั้งภายหลัง",
+		"problem.bulk_aborted.title":                     "ไม่ได้ดำเนินการ",
+		"problem.bulk_aborted.detail":                    "มีการดำเนินการอื่นในชุดแบบ atomic ล้มเหลว จึงยกเลิกการดำเนินการนี้",
+		"problem.too_many_operations.title":              "จำนวนการดำเนินการมากเกินไป",
+		"problem.too_many_operations.detail":             "คำขอแบบ bulk มีการดำเนินการได้ไม่เกิน {0} รายการ",
+		"problem.idempotency_key_reused.title":           "Idempotency-Key ถูกใช้ซ้ำ",
+		"problem.idempotency_key_reused.detail":          "Idempotency-Key นี้ถูกใช้กับคำขออื่นไปแล้ว",
+		"problem.idempotency_request_in_progress.title":  "คำขอกำลังดำเนินการ",
+		"problem.idempotency_request_in_progress.detail": "คำขอที่ใช้ Idempotency-Key นี้ยังดำเนินการไม่เสร็จ กรุณาลองใหม่ภายหลัง",
+		"problem.rate_limited.title":                     "มีคำขอมากเกินไป",
+		"problem.rate_limited.detail":                    "เกินขีดจำกัดจำนวนคำขอ กรุณาลองใหม่หลังจากจำนวนวินาทีที่ระบุใน header Retry-After",
+		"problem.unauthorized.title":                     "ไม่ได้รับอนุญาต",
+		"problem.unauthorized.detail":                    "endpoint นี้ต้องใช้ผู้ใช้ที่ยืนยันตัวตนแล้ว",
+		"problem.invalid_query.title":                    "คำค้นไม่ถูกต้อง",
+		"problem.invalid_query.detail":                   "ไม่สามารถแยกวิเคราะห์คำค้นสำหรับกรองได้",
+		"problem.read_only.title":                        "ข้อมูลอ่านได้อย่างเดียว",
+		"problem.read_only.detail":                       "ไม่สามารถแก้ไขหรือลบรายการอัจฉริยะที่มีมาในระบบได้",
+
+		"query.unexpected_token":   "ตำแหน่ง {0}: พบ \"{1}\" ที่ไม่คาดคิด",
+		"query.unexpected_end":     "ตำแหน่ง {0}: คำค้นจบก่อนกำหนด ยังขาดเงื่อนไข",
+		"query.unterminated_quote": "ตำแหน่ง {0}: เครื่องหมายคำพูดไม่ได้ปิด",
+		"query.unclosed_paren":     "ตำแหน่ง {0}: วงเล็บไม่ได้ปิด",
+		"query.missing_value":      "ตำแหน่ง {0}: \"{1}\" ต้องระบุค่า",
+		"query.unknown_field":      "ตำแหน่ง {0}: ไม่รู้จักฟิลด์ \"{1}\" ใช้ status, project, tag, title, description, due, created, updated หรือ completed",
+		"query.invalid_operator":   "ตำแหน่ง {0}: ใช้ตัวดำเนินการ \"{1}\" กับฟิลด์หรือค่านี้ไม่ได้",
+		"query.invalid_value":      "ตำแหน่ง {0}: status \"{1}\" ไม่ถูกต้อง ใช้ IN_PROGRESS หรือ COMPLETED",
+		"query.invalid_date":       "ตำแหน่ง {0}: วันที่ \"{1}\" ไม่ถูกต้อง ใช้ YYYY-MM-DD, today, yesterday, tomorrow, today+Nd, today-Nd หรือ startofweek",
+
+		"realtime.invalid_message":        "ข้อความไม่ใช่ JSON ที่ถูกต้องตามโปรโตคอล realtime",
+		"realtime.unknown_type":           "ไม่รู้จักประเภทข้อความ {0}",
+		"realtime.invalid_list":           "list ต้องมีความยาว 1 ถึง {0} ตัวอักษร",
+		"realtime.too_many_subscriptions": "การเชื่อมต่อหนึ่งติดตามได้ไม่เกิน {0} รายการ",
+	},
+}
@@ -0,0 +1,122 @@
+// Package i18n translates validation and error messages with
+// go-playground's universal-translator. The catalogs live in catalog.go;
+// English is the fallback for unsupported languages and missing keys.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"todo-app/domain"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/th"
+	ut "github.com/go-playground/universal-translator"
+	"golang.org/x/text/language"
+)
+
+// Fallback is the locale used when no requested language is supported.
+const Fallback = "en"
+
+var (
+	universal = newUniversal()
+	fallback  = mustTranslator(Fallback)
+)
+
+func newUniversal() *ut.UniversalTranslator {
+	uni := ut.New(en.New(), en.New(), th.New())
+	for locale, messages := range catalogs {
+		trans, ok := uni.GetTranslator(locale)
+		if !ok {
+			panic(fmt.Sprintf("i18n: no locale for catalog %q", locale))
+		}
+		for key, text := range messages {
+			if err := trans.Add(key, text, false); err != nil {
+				panic(fmt.Sprintf("i18n: %s: %s: %v", locale, key, err))
+			}
+		}
+	}
+	return uni
+}
+
+func mustTranslator(locale string) ut.Translator {
+	trans, ok := universal.GetTranslator(locale)
+	if !ok {
+		panic(fmt.Sprintf("i18n: unknown locale %q", locale))
+	}
+	return trans
+}
+
+// Default returns the fallback English translator.
+func Default() ut.Translator {
+	return fallback
+}
+
+// Match returns the best supported translator for an Accept-Language header
+// value, honouring q-values, or the English fallback.
+func Match(acceptLanguage string) ut.Translator {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil {
+		return fallback
+	}
+	for _, tag := range tags {
+		base, _ := tag.Base()
+		if trans, ok := universal.GetTranslator(base.String()); ok {
+			return trans
+		}
+	}
+	return fallback
+}
+
+// T translates key with params, falling back to the English catalog and
+// finally to the key itself.
+func T(trans ut.Translator, key string, params ...string) string {
+	if text, err := trans.T(key, params...); err == nil {
+		return text
+	}
+	if text, err := fallback.T(key, params...); err == nil {
+		return text
+	}
+	return key
+}
+
+// Violation renders the message for a field violation. Rules without a
+// catalog entry get a generic message naming the rule.
+func Violation(trans ut.Translator, field, rule, param string) string {
+	if rule == "oneof" {
+		param = strings.ReplaceAll(param, " ", ", ")
+	}
+	key := "rule." + rule
+	if _, err := fallback.T(key, field, param); err != nil {
+		return T(trans, "rule.default", field, rule)
+	}
+	return T(trans, key, field, param)
+}
+
+// Translate rewrites the messages of violations in the language of trans.
+func Translate(trans ut.Translator, violations []domain.FieldViolation) []domain.FieldViolation {
+	if len(violations) == 0 {
+		return violations
+	}
+	out := make([]domain.FieldViolation, len(violations))
+	for i, v := range violations {
+		v.Message = Violation(trans, v.Field, v.Rule, v.Param)
+		out[i] = v
+	}
+	return out
+}
+
+type ctxKey struct{}
+
+// NewContext returns a copy of ctx carrying trans.
+func NewContext(ctx context.Context, trans ut.Translator) context.Context {
+	return context.WithValue(ctx, ctxKey{}, trans)
+}
+
+// FromContext returns the translator stored in ctx, or the English fallback.
+func FromContext(ctx context.Context) ut.Translator {
+	if trans, ok := ctx.Value(ctxKey{}).(ut.Translator); ok {
+		return trans
+	}
+	return fallback
+}
@@ -0,0 +1,92 @@
+package i18n
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"todo-app/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	tests := map[string]string{
+		"":                         "en",
+		"th":                       "th",
+		"th-TH,th;q=0.9,en;q=0.8":  "th",
+		"fr-FR,en;q=0.5,th;q=0.7":  "th",
+		"de":                       "en",
+		"not a language header!!!": "en",
+	}
+	for header, want := range tests {
+		assert.Equal(t, want, Match(header).Locale(), header)
+	}
+}
+
+func TestCatalogsComplete(t *testing.T) {
+	for locale, messages := range catalogs {
+		for key := range catalogs[Fallback] {
+			assert.Contains(t, messages, key, "%s is missing %s", locale, key)
+		}
+	}
+}
+
+// universal-translator panics on messages whose placeholders are out of
+// order, such as "{1} at {0}".
+func TestCatalogsPlaceholdersInOrder(t *testing.T) {
+	placeholder := regexp.MustCompile(`\{(\d+)\}`)
+	for locale, messages := range catalogs {
+		for key, text := range messages {
+			last := -1
+			for _, m := range placeholder.FindAllStringSubmatch(text, -1) {
+				n, _ := strconv.Atoi(m[1])
+				assert.GreaterOrEqual(t, n, last, "%s %s: placeholders out of order", locale, key)
+				last = n
+			}
+		}
+	}
+}
+
+func TestQueryMessages(t *testing.T) {
+	for _, locale := range []string{"en", "th"} {
+		trans := Match(locale)
+		for key, text := range catalogs[locale] {
+			if !strings.HasPrefix(key, "query.") {
+				continue
+			}
+			var got string
+			var err error
+			require.NotPanics(t, func() { got, err = trans.T(key, "7", "due") }, "%s %s", locale, key)
+			require.NoError(t, err, "%s %s", locale, key)
+			assert.Contains(t, got, "7", "%s %s: position missing", locale, key)
+			if strings.Contains(text, "{1}") {
+				assert.Contains(t, got, `"due"`, "%s %s: token missing", locale, key)
+			}
+		}
+	}
+}
+
+func TestViolation(t *testing.T) {
+	th := Match("th")
+
+	assert.Equal(t, "title must be at most 100 characters", Violation(Default(), "title", "max", "100"))
+	assert.Equal(t, "status ต้องเป็นค่าใดค่าหนึ่งต่อไปนี้: IN_PROGRESS, COMPLETED", Violation(th, "status", "oneof", "IN_PROGRESS COMPLETED"))
+	assert.Equal(t, "image ไม่ถูกต้อง (email)", Violation(th, "image", "email", ""))
+}
+
+func TestTranslate(t *testing.T) {
+	violations := []domain.FieldViolation{{Field: "title", Rule: "required", Message: "title is required"}}
+
+	got := Translate(Match("th"), violations)
+
+	assert.Equal(t, "จำเป็นต้องระบุ title", got[0].Message)
+	assert.Equal(t, "title is required", violations[0].Message, "input is not modified")
+}
+
+func TestContext(t *testing.T) {
+	assert.Equal(t, "en", FromContext(context.Background()).Locale())
+	assert.Equal(t, "th", FromContext(NewContext(context.Background(), Match("th"))).Locale())
+}
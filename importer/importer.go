@@ -0,0 +1,267 @@
+// Package importer reads todos from the CSV, JSON and todo.txt files
+// accepted by POST /todos/import. Columns, or the keys of JSON objects, are matched to
+// todo fields by name or through a Mapping; rows that cannot be read are
+// reported in their record instead of failing the whole file.
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+	"todo-app/domain"
+	"todo-app/export"
+	"todo-app/todotxt"
+)
+
+// ErrMalformed is returned for files that cannot be parsed at all.
+var ErrMalformed = errors.New("malformed import file")
+
+// Fields are the todo fields an imported file can set, named like the JSON
+// fields of a todo. IDs and timestamps are always assigned on import.
+var Fields = []string{"title", "description", "status", "project", "tags", "due_date", "image"}
+
+// Mapping maps column names, or JSON keys, to Fields. Columns that are not
+// mapped match the field of the same name, ignoring case and treating
+// spaces and hyphens as underscores, so "Due Date" sets due_date.
+type Mapping map[string]string
+
+// Validate reports mappings to names that are not Fields.
+func (m Mapping) Validate() error {
+	var violations []domain.FieldViolation
+	for _, column := range slices.Sorted(maps.Keys(m)) {
+		if !slices.Contains(Fields, m[column]) {
+			violations = append(violations, domain.FieldViolation{
+				Field: "map[" + column + "]", Rule: "oneof", Param: strings.Join(Fields, " "),
+			})
+		}
+	}
+	if len(violations) > 0 {
+		return domain.NewValidationError(violations...)
+	}
+	return nil
+}
+
+// field returns the field column sets, or "" if it sets none.
+func (m Mapping) field(column string) string {
+	name := normalize(column)
+	for from, to := range m {
+		if normalize(from) == name {
+			return to
+		}
+	}
+	if slices.Contains(Fields, name) {
+		return name
+	}
+	return ""
+}
+
+var separators = strings.NewReplacer(" ", "_", "-", "_")
+
+func normalize(name string) string {
+	return separators.Replace(strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))))
+}
+
+// File is the content of an imported file.
+type File struct {
+	Records []domain.ImportRecord
+	// Ignored are the columns or keys that set no field, in the order they
+	// were first seen.
+	Ignored []string
+}
+
+func (f *File) ignore(column string) {
+	if !slices.Contains(f.Ignored, column) {
+		f.Ignored = append(f.Ignored, column)
+	}
+}
+
+// ReadCSV reads a CSV file whose first row names the columns. Tags are
+// separated by commas within their cell. Cells escaped against formula
+// injection by an export are unescaped.
+func ReadCSV(r io.Reader, m Mapping) (*File, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return &File{Records: []domain.ImportRecord{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+
+	file := &File{Records: []domain.ImportRecord{}}
+	fields := make([]string, len(header))
+	for i, column := range header {
+		if fields[i] = m.field(column); fields[i] == "" {
+			file.ignore(strings.TrimPrefix(column, "\ufeff"))
+		}
+	}
+	for row := 2; ; row++ {
+		cells, err := reader.Read()
+		if err == io.EOF {
+			return file, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrMalformed, err)
+		}
+		var v values
+		for i, cell := range cells {
+			if i < len(fields) {
+				v.set(fields[i], export.UnescapeCell(cell))
+			}
+		}
+		todo, err := v.todo()
+		file.Records = append(file.Records, domain.ImportRecord{Row: row, Todo: todo, Err: err})
+	}
+}
+
+// ReadJSON reads a JSON array of objects, such as an export in the json
+// format.
+func ReadJSON(r io.Reader, m Mapping) (*File, error) {
+	dec := json.NewDecoder(r)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return nil, fmt.Errorf("%w: expected a JSON array", ErrMalformed)
+	}
+
+	file := &File{Records: []domain.ImportRecord{}}
+	for row := 1; dec.More(); row++ {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrMalformed, err)
+		}
+		todo, err := file.readObject(raw, m)
+		file.Records = append(file.Records, domain.ImportRecord{Row: row, Todo: todo, Err: err})
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+	return file, nil
+}
+
+func (f *File) readObject(raw json.RawMessage, m Mapping) (domain.Todo, error) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &object); err != nil || object == nil {
+		return domain.Todo{}, domain.NewValidationError(domain.FieldViolation{Field: "row", Rule: "type", Param: "object"})
+	}
+	mapped := make(map[string]json.RawMessage, len(object))
+	for _, key := range slices.Sorted(maps.Keys(object)) {
+		if field := m.field(key); field != "" {
+			mapped[field] = object[key]
+		} else {
+			f.ignore(key)
+		}
+	}
+
+	data, _ := json.Marshal(mapped)
+	var fields struct {
+		Title       string   `json:"title"`
+		Description string   `json:"description"`
+		Status      string   `json:"status"`
+		Project     string   `json:"project"`
+		Tags        []string `json:"tags"`
+		DueDate     string   `json:"due_date"`
+		Image       string   `json:"image"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return domain.Todo{}, domain.NewValidationError(domain.FieldViolation{Field: typeErr.Field, Rule: "type", Param: jsonType(typeErr.Type)})
+		}
+		return domain.Todo{}, err
+	}
+	v := values{
+		title:       fields.Title,
+		description: fields.Description,
+		status:      fields.Status,
+		project:     fields.Project,
+		tags:        fields.Tags,
+		due:         fields.DueDate,
+		image:       fields.Image,
+	}
+	return v.todo()
+}
+
+// ReadTodoTxt reads a todo.txt file, see todotxt.ToTodo. Rows are line
+// numbers; blank lines are skipped.
+func ReadTodoTxt(r io.Reader) (*File, error) {
+	lines, err := todotxt.Read(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+	file := &File{Records: make([]domain.ImportRecord, len(lines)), Ignored: []string{}}
+	for i, line := range lines {
+		file.Records[i] = domain.ImportRecord{Row: line.Number, Todo: todotxt.ToTodo(line.Task)}
+	}
+	return file, nil
+}
+
+// jsonType names the JSON type that decodes into t.
+func jsonType(t reflect.Type) string {
+	if t.Kind() == reflect.Slice {
+		return "array"
+	}
+	return "string"
+}
+
+// values are the fields of one row before they are checked.
+type values struct {
+	title, description, status, project, due, image string
+	tags                                            []string
+}
+
+func (v *values) set(field, cell string) {
+	switch field {
+	case "title":
+		v.title = cell
+	case "description":
+		v.description = cell
+	case "status":
+		v.status = cell
+	case "project":
+		v.project = cell
+	case "tags":
+		v.tags = strings.Split(cell, ",")
+	case "due_date":
+		v.due = cell
+	case "image":
+		v.image = cell
+	}
+}
+
+// todo builds the todo v describes. Blank tags are dropped, the status is
+// upper cased and defaults to IN_PROGRESS, and the due date is a date, taken
+// as midnight UTC, or an RFC 3339 time. Validation is left to the usecase.
+func (v values) todo() (domain.Todo, error) {
+	todo := domain.Todo{
+		Title:       strings.TrimSpace(v.title),
+		Description: v.description,
+		Status:      strings.ToUpper(strings.TrimSpace(v.status)),
+		Project:     strings.TrimSpace(v.project),
+		Image:       strings.TrimSpace(v.image),
+	}
+	if todo.Status == "" {
+		todo.Status = "IN_PROGRESS"
+	}
+	for _, tag := range v.tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			todo.Tags = append(todo.Tags, tag)
+		}
+	}
+	if due := strings.TrimSpace(v.due); due != "" {
+		t, err := time.Parse(time.DateOnly, due)
+		if err != nil {
+			if t, err = time.Parse(time.RFC3339, due); err != nil {
+				return todo, domain.NewValidationError(domain.FieldViolation{Field: "due_date", Rule: "datetime"})
+			}
+		}
+		todo.DueDate = &t
+	}
+	return todo, nil
+}
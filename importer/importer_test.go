@@ -0,0 +1,103 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"todo-app/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func violations(t *testing.T, err error) []domain.FieldViolation {
+	t.Helper()
+	var validationErr *domain.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	return validationErr.Violations
+}
+
+func TestReadCSV(t *testing.T) {
+	input := "\ufeffTask,Status,Tags,Due Date,Owner\n" +
+		"Ship release,completed,\"backend, urgent,\",2026-11-01,ann\n" +
+		"\"Write\nnotes\",,,2026-11-01T09:00:00+07:00\n" +
+		"Plan,IN_PROGRESS,,next week,bob\n"
+
+	file, err := ReadCSV(strings.NewReader(input), Mapping{"task": "title"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Owner"}, file.Ignored)
+	require.Len(t, file.Records, 3)
+
+	first := file.Records[0]
+	assert.Equal(t, 2, first.Row)
+	require.NoError(t, first.Err)
+	assert.Equal(t, "Ship release", first.Todo.Title)
+	assert.Equal(t, "COMPLETED", first.Todo.Status)
+	assert.Equal(t, []string{"backend", "urgent"}, first.Todo.Tags)
+	assert.Equal(t, time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), *first.Todo.DueDate)
+
+	second := file.Records[1]
+	require.NoError(t, second.Err)
+	assert.Equal(t, "Write\nnotes", second.Todo.Title)
+	assert.Equal(t, "IN_PROGRESS", second.Todo.Status, "status defaults to in progress")
+	assert.True(t, time.Date(2026, 11, 1, 2, 0, 0, 0, time.UTC).Equal(*second.Todo.DueDate))
+
+	third := file.Records[2]
+	assert.Equal(t, 4, third.Row, "rows count the header and ignore line breaks in cells")
+	assert.Equal(t, []domain.FieldViolation{{Field: "due_date", Rule: "datetime"}}, violations(t, third.Err))
+
+	t.Run("unescapes formulas", func(t *testing.T) {
+		file, err := ReadCSV(strings.NewReader("title,tags\n'=1+1,\"'@ops,x\"\n"), nil)
+
+		require.NoError(t, err)
+		require.NoError(t, file.Records[0].Err)
+		assert.Equal(t, "=1+1", file.Records[0].Todo.Title)
+		assert.Equal(t, []string{"@ops", "x"}, file.Records[0].Todo.Tags)
+	})
+}
+
+func TestReadCSV_Malformed(t *testing.T) {
+	_, err := ReadCSV(strings.NewReader("title\n\"unterminated\n"), nil)
+	assert.ErrorIs(t, err, ErrMalformed)
+
+	file, err := ReadCSV(strings.NewReader(""), nil)
+	require.NoError(t, err)
+	assert.Empty(t, file.Records)
+}
+
+func TestReadJSON(t *testing.T) {
+	input := `[
+		{"name": "Ship release", "tags": ["backend"], "due_date": "2026-11-01", "id": "ignored"},
+		{"name": "Plan", "tags": "backend"},
+		"not an object",
+		{"name": "Notes", "due_date": null}
+	]`
+
+	file, err := ReadJSON(strings.NewReader(input), Mapping{"Name": "title"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id"}, file.Ignored)
+	require.Len(t, file.Records, 4)
+	require.NoError(t, file.Records[0].Err)
+	assert.Equal(t, "Ship release", file.Records[0].Todo.Title)
+	assert.Equal(t, []string{"backend"}, file.Records[0].Todo.Tags)
+	assert.Equal(t, []domain.FieldViolation{{Field: "tags", Rule: "type", Param: "array"}}, violations(t, file.Records[1].Err))
+	assert.Equal(t, []domain.FieldViolation{{Field: "row", Rule: "type", Param: "object"}}, violations(t, file.Records[2].Err))
+	assert.Equal(t, 4, file.Records[3].Row)
+	assert.Nil(t, file.Records[3].Todo.DueDate)
+}
+
+func TestReadJSON_Malformed(t *testing.T) {
+	for _, input := range []string{`{"title": "x"}`, `[{"title": "x"}`, `[{"title": }]`} {
+		_, err := ReadJSON(strings.NewReader(input), nil)
+		assert.ErrorIs(t, err, ErrMalformed, input)
+	}
+}
+
+func TestMapping_Validate(t *testing.T) {
+	assert.NoError(t, Mapping{"Task": "title", "When": "due_date"}.Validate())
+	assert.Equal(t, []domain.FieldViolation{
+		{Field: "map[Owner]", Rule: "oneof", Param: "title description status project tags due_date image"},
+	}, violations(t, Mapping{"Task": "title", "Owner": "assignee"}.Validate()))
+}
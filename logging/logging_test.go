@@ -0,0 +1,23 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromContext(t *testing.T) {
+	var buf bytes.Buffer
+	fallback := slog.Default()
+	scoped := slog.New(slog.NewJSONHandler(&buf, nil)).With("request_id", "abc")
+
+	assert.Same(t, fallback, FromContext(context.Background(), fallback))
+
+	ctx := NewContext(context.Background(), scoped)
+	FromContext(ctx, fallback).Info("hello")
+
+	assert.Contains(t, buf.String(), `"request_id":"abc"`)
+}
@@ -1,73 +1,257 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 	"todo-app/api/route"
+	"todo-app/config"
 	"todo-app/docs"
 	"todo-app/domain"
+	"todo-app/eventbus"
+	"todo-app/events"
+	"todo-app/health"
+	"todo-app/metrics"
+	"todo-app/outbox"
+	"todo-app/ratelimit"
+	"todo-app/realtime"
+	"todo-app/repository"
+	"todo-app/server"
+	"todo-app/tracing"
+	"todo-app/usecase"
+	"todo-app/webhook"
 
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
-	"gorm.io/driver/postgres"
-	"gorm.io/gorm"
 )
 
+const usage = `Usage: todo-app [flags] [command]
+
+Commands:
+  serve    start the HTTP server (default)
+  config   print the effective configuration with secrets redacted
+  todotxt  import or export todo.txt files, see todo-app todotxt
+
+Flags:
+`
+
 func main() {
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML or TOML config file")
+	envFile := flag.String("env-file", ".env", "path to a dotenv file")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	cfg, err := config.Load(config.Options{File: *configFile, EnvFile: *envFile})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	switch cmd := flag.Arg(0); cmd {
+	case "", "serve":
+		serve(cfg)
+	case "config":
+		if err := cfg.Print(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	case "todotxt":
+		if err := runTodoTxt(cfg, flag.Args()[1:]); err != nil {
+			if errors.Is(err, errTodoTxtUsage) {
+				fmt.Fprint(os.Stderr, todoTxtUsage)
+				os.Exit(2)
+			}
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", cmd)
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+func serve(cfg *config.Config) {
 	// Initialize logger
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
-	}))
+	logger := newLogger(cfg.Log)
 	slog.SetDefault(logger)
 
-	// Load environment variables
-	if err := godotenv.Load(); err != nil {
-		logger.Warn("Error loading .env file, using system environment variables", "error", err)
+	if cfg.App.Env == "production" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	// Tracing
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		logger.Error("Failed to set up tracing", "exporter", cfg.Tracing.Exporter, "error", err)
+		panic("failed to set up tracing: " + err.Error())
 	}
 
-	// Database connection
-	dsn := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=UTC",
-		getEnv("DB_HOST", "postgres"),
-		getEnv("DB_USER", "postgres"),
-		getEnv("DB_PASSWORD", "postgres"),
-		getEnv("DB_NAME", "todo"),
-		getEnv("DB_PORT", "5432"),
-		getEnv("DB_SSLMODE", "disable"),
-	)
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	// Storage backend
+	backend, err := repository.Open(cfg.Database, logger)
 	if err != nil {
-		logger.Error("Failed to connect to database", "error", err)
-		panic("failed to connect database: " + err.Error())
+		logger.Error("Failed to open storage backend", "driver", cfg.Database.Driver, "error", err)
+		panic("failed to open storage backend: " + err.Error())
 	}
-	logger.Info("Database connected successfully")
+	logger.Info("Storage backend ready", "driver", cfg.Database.Driver)
+
+	registry := health.NewRegistry(cfg.Health.CheckTimeout)
+	registry.Register("database", backend.Ping)
+	registry.Register("migrations", backend.MigrationStatus)
 
-	if err := db.AutoMigrate(&domain.Todo{}); err != nil {
-		logger.Error("Failed to migrate database", "error", err)
-		panic("failed to migrate database: " + err.Error())
+	bus := eventbus.New(cfg.EventBus.AsyncBuffer, logger)
+
+	var m *metrics.Metrics
+	if cfg.Metrics.Enabled {
+		m = metrics.New()
+		m.SubscribeEvents(bus)
+		m.RegisterTodoCounts(backend.Todos, cfg.Metrics.ScrapeTimeout)
+		if backend.DB != nil {
+			sqlDB, err := backend.DB.DB()
+			if err != nil {
+				logger.Error("Failed to access database pool", "error", err)
+				panic("failed to access database pool: " + err.Error())
+			}
+			m.RegisterDBStats(sqlDB, cfg.Database.Name)
+		}
 	}
 
-	gin := gin.Default()
+	gin := gin.New()
+	if err := gin.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		logger.Error("Invalid trusted proxies", "trusted_proxies", cfg.Server.TrustedProxies, "error", err)
+		panic("invalid trusted proxies: " + err.Error())
+	}
 	docs.SwaggerInfo.BasePath = ""
 
-	route.Setup(gin, db, logger)
+	broker := events.NewBroker(cfg.Events.ReplayBufferSize, cfg.Events.SubscriberBuffer)
+	hub := realtime.NewHub(broker, cfg.Realtime.SendBuffer, cfg.Realtime.MaxSubscriptions, logger)
+
+	route.Setup(gin, route.Deps{
+		Config:         cfg,
+		Backend:        backend,
+		Health:         registry,
+		Metrics:        m,
+		Events:         broker,
+		Realtime:       hub,
+		Bus:            bus,
+		RateLimitStore: ratelimit.NewMemoryStore(),
+		Logger:         logger,
+	})
 
 	gin.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	port := getEnv("APP_PORT", "8080")
-	logger.Info("Starting server", "port", port)
-	if err := gin.Run(":" + port); err != nil {
-		logger.Error("Failed to start server", "error", err)
-		panic("failed to start server: " + err.Error())
+	srv := server.New(&http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.Server.Port),
+		Handler:           gin,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}, server.Timeouts{
+		Delay:   cfg.Server.ShutdownDelay,
+		Drain:   cfg.Server.ShutdownTimeout,
+		Workers: cfg.Server.WorkerShutdownTimeout,
+	}, logger)
+	srv.OnShutdown(registry.SetShuttingDown)
+	// Event streams never finish on their own, so end them before waiting
+	// for in-flight requests to drain.
+	srv.OnDrain(broker.Close)
+	// WebSockets are hijacked, so Shutdown would not wait for them at all.
+	srv.OnDrain(hub.Close)
+	srv.AddWorker("realtime hub", hub.Run)
+	relay := outbox.NewRelay(backend.Outbox, outbox.Options{
+		PollInterval:     cfg.Outbox.PollInterval,
+		BatchSize:        cfg.Outbox.BatchSize,
+		RetryInterval:    cfg.Outbox.RetryInterval,
+		MaxRetryInterval: cfg.Outbox.MaxRetryInterval,
+		Retention:        cfg.Outbox.Retention,
+		CleanupInterval:  cfg.Outbox.CleanupInterval,
+	}, logger)
+	relay.Register("change stream", outbox.PublisherSink(broker))
+	if path := cfg.Outbox.LogFile; path != "" {
+		sink, err := outbox.NewFileSink(path)
+		if err != nil {
+			logger.Error("Failed to open event log", "path", path, "error", err)
+			panic("failed to open event log: " + err.Error())
+		}
+		relay.Register("event log", sink)
+		srv.AddCloser("event log", sink.Close)
+	}
+	if wh := cfg.Webhooks; wh.Enabled {
+		dispatcher := usecase.NewWebhookDispatcher(backend.Webhooks, webhook.NewSender(wh.RequestTimeout, wh.AllowPrivateNetworks), usecase.WebhookPolicy{
+			MaxAttempts:    wh.MaxAttempts,
+			InitialBackoff: wh.InitialBackoff,
+			MaxBackoff:     wh.MaxBackoff,
+			DisableAfter:   wh.DisableAfterFailures,
+			BatchSize:      wh.BatchSize,
+			// Long enough for a request to time out and its result to be
+			// recorded before another replica may retry it.
+			Lease: 2 * wh.RequestTimeout,
+		}, logger)
+		relay.Register("webhooks", outbox.SinkFunc(dispatcher.Enqueue))
+		srv.AddWorker("webhook delivery", webhook.NewWorker(dispatcher, wh.PollInterval).Run)
+	}
+	srv.AddWorker("outbox relay", relay.Run)
+	srv.AddWorker("idempotency cleanup", func(ctx context.Context) {
+		purgeIdempotencyKeys(ctx, backend.Idempotency, cfg.Idempotency.CleanupInterval, logger)
+	})
+	srv.AddCloser("tracing", shutdownTracing)
+	srv.AddCloser("storage backend", backend.Close)
+	// Asynchronous subscribers may still use the backend, so they are
+	// drained before it is closed.
+	srv.AddCloser("event bus", bus.Close)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err = srv.Run(ctx)
+	stop()
+	if err != nil {
+		logger.Error("Server stopped with error", "error", err)
+		os.Exit(1)
+	}
+}
+
+// purgeIdempotencyKeys deletes expired idempotency records every interval
+// until ctx is done.
+func purgeIdempotencyKeys(ctx context.Context, store domain.IdempotencyRepository, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			n, err := store.DeleteExpired(ctx, now)
+			if err != nil {
+				logger.Error("Failed to purge expired idempotency keys", "error", err)
+				continue
+			}
+			if n > 0 {
+				logger.Info("Purged expired idempotency keys", "count", n)
+			}
+		}
 	}
 }
 
-func getEnv(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+func newLogger(cfg config.Log) *slog.Logger {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		level = slog.LevelInfo
+	}
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler = slog.NewJSONHandler(os.Stdout, opts)
+	if cfg.Format == "text" {
+		handler = slog.NewTextHandler(os.Stdout, opts)
 	}
-	return defaultValue
+	return slog.New(tracing.NewLogHandler(handler))
 }
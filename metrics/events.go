@@ -0,0 +1,21 @@
+package metrics
+
+import (
+	"context"
+	"todo-app/domain"
+)
+
+// SubscribeEvents counts the domain events published on bus in
+// todo_domain_events_total and status transitions in
+// todo_status_changes_total.
+func (m *Metrics) SubscribeEvents(bus domain.EventBus) {
+	bus.Subscribe("metrics", m.observeEvent)
+}
+
+func (m *Metrics) observeEvent(_ context.Context, event domain.DomainEvent) error {
+	m.domainEvents.WithLabelValues(event.EventName()).Inc()
+	if e, ok := event.(domain.TodoStatusChangedEvent); ok {
+		m.statusChanges.WithLabelValues(e.From, e.To).Inc()
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+// Package metrics exposes Prometheus collectors for the HTTP, usecase and
+// repository layers together with database pool and business gauges.
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+	"todo-app/domain"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "todo"
+
+// Outcome labels shared by the usecase and repository collectors.
+const (
+	OutcomeSuccess         = "success"
+	OutcomeValidationError = "validation_error"
+	OutcomeNotFound        = "not_found"
+	OutcomeError           = "error"
+)
+
+// Metrics owns a dedicated registry so tests can create independent
+// instances without clashing on the global default registerer.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	httpInFlight prometheus.Gauge
+
+	usecaseDuration *prometheus.HistogramVec
+	repoDuration    *prometheus.HistogramVec
+
+	domainEvents  *prometheus.CounterVec
+	statusChanges *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests by method, route template and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by method, route template and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		httpInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_in_flight",
+			Help:      "HTTP requests currently being served.",
+		}),
+		usecaseDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "usecase",
+			Name:      "operation_duration_seconds",
+			Help:      "Usecase operation latency by operation and outcome.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation", "outcome"}),
+		repoDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "repository",
+			Name:      "operation_duration_seconds",
+			Help:      "Repository operation latency by operation and outcome.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"operation", "outcome"}),
+		domainEvents: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "domain_events_total",
+			Help:      "Domain events published by event name.",
+		}, []string{"event"}),
+		statusChanges: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "status_changes_total",
+			Help:      "Todo status transitions by previous and new status.",
+		}, []string{"from", "to"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.httpInFlight,
+		m.usecaseDuration,
+		m.repoDuration,
+		m.domainEvents,
+		m.statusChanges,
+	)
+	return m
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Registry exposes the underlying registry for additional collectors.
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// RegisterDBStats exports connection pool statistics from sql.DB.Stats.
+func (m *Metrics) RegisterDBStats(db *sql.DB, name string) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// ObserveHTTP records a finished request. route is the gin route template,
+// never the raw path, to keep label cardinality bounded.
+func (m *Metrics) ObserveHTTP(method, route, status string, elapsed time.Duration) {
+	m.httpRequests.WithLabelValues(method, route, status).Inc()
+	m.httpDuration.WithLabelValues(method, route, status).Observe(elapsed.Seconds())
+}
+
+// TrackInFlight increments the in-flight gauge and returns the decrement.
+func (m *Metrics) TrackInFlight() func() {
+	m.httpInFlight.Inc()
+	return m.httpInFlight.Dec
+}
+
+// Outcome classifies err into one of the Outcome labels.
+func Outcome(err error) string {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case errors.Is(err, domain.ErrValidationFailed):
+		return OutcomeValidationError
+	case errors.Is(err, domain.ErrNotFound):
+		return OutcomeNotFound
+	default:
+		return OutcomeError
+	}
+}
+
+// RegisterTodoCounts exports a gauge of todos per status, queried from repo
+// at scrape time with the given timeout.
+func (m *Metrics) RegisterTodoCounts(repo domain.TodoRepository, timeout time.Duration) {
+	m.registry.MustRegister(&todoCountCollector{repo: repo, timeout: timeout})
+}
+
+var todoCountDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "todos"),
+	"Number of stored todos by status.",
+	[]string{"status"}, nil,
+)
+
+var todoCountErrorsDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "todos_scrape_errors"),
+	"1 if counting todos failed during the last scrape, 0 otherwise.",
+	nil, nil,
+)
+
+type todoCountCollector struct {
+	repo    domain.TodoRepository
+	timeout time.Duration
+}
+
+func (c *todoCountCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- todoCountDesc
+	ch <- todoCountErrorsDesc
+}
+
+func (c *todoCountCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	counts, err := c.repo.CountByStatus(ctx)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(todoCountErrorsDesc, prometheus.GaugeValue, 1)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(todoCountErrorsDesc, prometheus.GaugeValue, 0)
+	for status, n := range counts {
+		ch <- prometheus.MustNewConstMetric(todoCountDesc, prometheus.GaugeValue, float64(n), status)
+	}
+}
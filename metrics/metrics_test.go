@@ -0,0 +1,117 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"todo-app/domain"
+	"todo-app/domain/mocks"
+	"todo-app/eventbus"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func scrape(t *testing.T, m *Metrics) string {
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, 200, w.Code)
+	return w.Body.String()
+}
+
+func TestOutcome(t *testing.T) {
+	assert.Equal(t, OutcomeSuccess, Outcome(nil))
+	assert.Equal(t, OutcomeValidationError, Outcome(fmt.Errorf("%w: title", domain.ErrValidationFailed)))
+	assert.Equal(t, OutcomeNotFound, Outcome(domain.ErrNotFound))
+	assert.Equal(t, OutcomeError, Outcome(errors.New("boom")))
+}
+
+func TestMetrics_ObserveHTTP(t *testing.T) {
+	m := New()
+
+	m.ObserveHTTP("GET", "/todos/:id", "200", 15*time.Millisecond)
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `todo_http_requests_total{method="GET",route="/todos/:id",status="200"} 1`)
+	assert.Contains(t, body, `todo_http_request_duration_seconds_count{method="GET",route="/todos/:id",status="200"} 1`)
+}
+
+func TestInstrumentTodoRepository(t *testing.T) {
+	m := New()
+	mockRepo := new(mocks.MockTodoRepository)
+	repo := m.InstrumentTodoRepository(mockRepo)
+	id := uuid.New()
+
+	mockRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+	mockRepo.On("FindByID", mock.Anything, id).Return(nil, domain.ErrNotFound).Once()
+
+	assert.NoError(t, repo.Create(context.Background(), &domain.Todo{}))
+	_, err := repo.FindByID(context.Background(), id)
+
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	body := scrape(t, m)
+	assert.Contains(t, body, `todo_repository_operation_duration_seconds_count{operation="create",outcome="success"} 1`)
+	assert.Contains(t, body, `todo_repository_operation_duration_seconds_count{operation="find_by_id",outcome="not_found"} 1`)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestInstrumentTodoUsecase(t *testing.T) {
+	m := New()
+	mockUsecase := new(mocks.MockTodoUsecase)
+	usecase := m.InstrumentTodoUsecase(mockUsecase)
+
+	mockUsecase.On("List", mock.Anything, domain.ListOptions{}).Return([]domain.Todo{}, nil).Once()
+
+	_, err := usecase.List(context.Background(), domain.ListOptions{})
+
+	assert.NoError(t, err)
+	assert.Contains(t, scrape(t, m), `todo_usecase_operation_duration_seconds_count{operation="list",outcome="success"} 1`)
+}
+
+func TestSubscribeEvents(t *testing.T) {
+	m := New()
+	bus := eventbus.New(1, slog.Default())
+	m.SubscribeEvents(bus)
+
+	bus.Publish(context.Background(),
+		domain.TodoUpdatedEvent{Changed: []string{"status"}},
+		domain.TodoStatusChangedEvent{From: "IN_PROGRESS", To: "COMPLETED"},
+	)
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `todo_domain_events_total{event="todo.updated"} 1`)
+	assert.Contains(t, body, `todo_domain_events_total{event="todo.status_changed"} 1`)
+	assert.Contains(t, body, `todo_status_changes_total{from="IN_PROGRESS",to="COMPLETED"} 1`)
+}
+
+func TestRegisterTodoCounts(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		m := New()
+		mockRepo := new(mocks.MockTodoRepository)
+		mockRepo.On("CountByStatus", mock.Anything).Return(map[string]int64{"IN_PROGRESS": 3, "COMPLETED": 1}, nil)
+		m.RegisterTodoCounts(mockRepo, time.Second)
+
+		body := scrape(t, m)
+
+		assert.Contains(t, body, `todo_todos{status="IN_PROGRESS"} 3`)
+		assert.Contains(t, body, `todo_todos{status="COMPLETED"} 1`)
+		assert.Contains(t, body, "todo_todos_scrape_errors 0")
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		m := New()
+		mockRepo := new(mocks.MockTodoRepository)
+		mockRepo.On("CountByStatus", mock.Anything).Return(nil, domain.ErrDatabaseOperation)
+		m.RegisterTodoCounts(mockRepo, time.Second)
+
+		body := scrape(t, m)
+
+		assert.Contains(t, body, "todo_todos_scrape_errors 1")
+		assert.NotContains(t, body, "todo_todos{")
+	})
+}
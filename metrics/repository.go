@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"context"
+	"time"
+	"todo-app/domain"
+
+	"github.com/google/uuid"
+)
+
+// todoRepository times every call to the wrapped repository.
+type todoRepository struct {
+	next    domain.TodoRepository
+	metrics *Metrics
+}
+
+// InstrumentTodoRepository wraps repo so each operation is recorded in
+// todo_repository_operation_duration_seconds.
+func (m *Metrics) InstrumentTodoRepository(repo domain.TodoRepository) domain.TodoRepository {
+	return &todoRepository{next: repo, metrics: m}
+}
+
+func (r *todoRepository) observe(operation string, start time.Time, err error) {
+	r.metrics.repoDuration.WithLabelValues(operation, Outcome(err)).Observe(time.Since(start).Seconds())
+}
+
+func (r *todoRepository) Create(ctx context.Context, todo *domain.Todo) error {
+	start := time.Now()
+	err := r.next.Create(ctx, todo)
+	r.observe("create", start, err)
+	return err
+}
+
+func (r *todoRepository) CreateBatch(ctx context.Context, todos []domain.Todo) error {
+	start := time.Now()
+	err := r.next.CreateBatch(ctx, todos)
+	r.observe("create_batch", start, err)
+	return err
+}
+
+func (r *todoRepository) Update(ctx context.Context, todo *domain.Todo) error {
+	start := time.Now()
+	err := r.next.Update(ctx, todo)
+	r.observe("update", start, err)
+	return err
+}
+
+func (r *todoRepository) FindAll(ctx context.Context) ([]domain.Todo, error) {
+	start := time.Now()
+	todos, err := r.next.FindAll(ctx)
+	r.observe("find_all", start, err)
+	return todos, err
+}
+
+func (r *todoRepository) FindByFilter(ctx context.Context, filter domain.Filter) ([]domain.Todo, error) {
+	start := time.Now()
+	todos, err := r.next.FindByFilter(ctx, filter)
+	r.observe("find_by_filter", start, err)
+	return todos, err
+}
+
+func (r *todoRepository) Facets(ctx context.Context, filter domain.Filter) (*domain.Facets, error) {
+	start := time.Now()
+	facets, err := r.next.Facets(ctx, filter)
+	r.observe("facets", start, err)
+	return facets, err
+}
+
+func (r *todoRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Todo, error) {
+	start := time.Now()
+	todo, err := r.next.FindByID(ctx, id)
+	r.observe("find_by_id", start, err)
+	return todo, err
+}
+
+func (r *todoRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	start := time.Now()
+	err := r.next.Delete(ctx, id)
+	r.observe("delete", start, err)
+	return err
+}
+
+func (r *todoRepository) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	start := time.Now()
+	counts, err := r.next.CountByStatus(ctx)
+	r.observe("count_by_status", start, err)
+	return counts, err
+}
+
+func (r *todoRepository) Search(ctx context.Context, query string, limit int) ([]domain.SearchResult, error) {
+	start := time.Now()
+	results, err := r.next.Search(ctx, query, limit)
+	r.observe("search", start, err)
+	return results, err
+}
+
+// Stream includes the time fn takes to consume each todo.
+func (r *todoRepository) Stream(ctx context.Context, filter domain.Filter, sortBy string, fn func(domain.Todo) error) error {
+	start := time.Now()
+	err := r.next.Stream(ctx, filter, sortBy, fn)
+	r.observe("stream", start, err)
+	return err
+}
+
+func (r *todoRepository) FuzzySearch(ctx context.Context, query string, threshold float64, limit int) ([]domain.FuzzyMatch, error) {
+	start := time.Now()
+	matches, err := r.next.FuzzySearch(ctx, query, threshold, limit)
+	r.observe("fuzzy_search", start, err)
+	return matches, err
+}
+
+// Transaction times the whole transaction and instruments the repository
+// handed to fn, so operations inside it are recorded too.
+func (r *todoRepository) Transaction(ctx context.Context, fn func(tx domain.TodoRepository) error) error {
+	start := time.Now()
+	err := r.next.Transaction(ctx, func(tx domain.TodoRepository) error {
+		return fn(r.metrics.InstrumentTodoRepository(tx))
+	})
+	r.observe("transaction", start, err)
+	return err
+}
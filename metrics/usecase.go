@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"context"
+	"time"
+	"todo-app/domain"
+
+	"github.com/google/uuid"
+)
+
+// todoUsecase times every call to the wrapped usecase.
+type todoUsecase struct {
+	next    domain.TodoUsecase
+	metrics *Metrics
+}
+
+// InstrumentTodoUsecase wraps usecase so each operation is recorded in
+// todo_usecase_operation_duration_seconds.
+func (m *Metrics) InstrumentTodoUsecase(usecase domain.TodoUsecase) domain.TodoUsecase {
+	return &todoUsecase{next: usecase, metrics: m}
+}
+
+func (u *todoUsecase) observe(operation string, start time.Time, err error) {
+	u.metrics.usecaseDuration.WithLabelValues(operation, Outcome(err)).Observe(time.Since(start).Seconds())
+}
+
+func (u *todoUsecase) Create(ctx context.Context, todo *domain.Todo) error {
+	start := time.Now()
+	err := u.next.Create(ctx, todo)
+	u.observe("create", start, err)
+	return err
+}
+
+func (u *todoUsecase) Update(ctx context.Context, todo *domain.Todo) error {
+	start := time.Now()
+	err := u.next.Update(ctx, todo)
+	u.observe("update", start, err)
+	return err
+}
+
+func (u *todoUsecase) List(ctx context.Context, opts domain.ListOptions) ([]domain.Todo, error) {
+	start := time.Now()
+	todos, err := u.next.List(ctx, opts)
+	u.observe("list", start, err)
+	return todos, err
+}
+
+func (u *todoUsecase) Facets(ctx context.Context, query string) (*domain.Facets, error) {
+	start := time.Now()
+	facets, err := u.next.Facets(ctx, query)
+	u.observe("facets", start, err)
+	return facets, err
+}
+
+func (u *todoUsecase) Delete(ctx context.Context, id uuid.UUID) error {
+	start := time.Now()
+	err := u.next.Delete(ctx, id)
+	u.observe("delete", start, err)
+	return err
+}
+
+func (u *todoUsecase) Search(ctx context.Context, query string, limit int) ([]domain.SearchResult, error) {
+	start := time.Now()
+	results, err := u.next.Search(ctx, query, limit)
+	u.observe("search", start, err)
+	return results, err
+}
+
+func (u *todoUsecase) Export(ctx context.Context, opts domain.ListOptions, fn func(domain.Todo) error) error {
+	start := time.Now()
+	err := u.next.Export(ctx, opts, fn)
+	u.observe("export", start, err)
+	return err
+}
+
+func (u *todoUsecase) Import(ctx context.Context, records []domain.ImportRecord, dryRun bool) ([]domain.ImportRecord, error) {
+	start := time.Now()
+	results, err := u.next.Import(ctx, records, dryRun)
+	u.observe("import", start, err)
+	return results, err
+}
+
+func (u *todoUsecase) FuzzySearch(ctx context.Context, query string, threshold float64, limit int) ([]domain.FuzzyMatch, error) {
+	start := time.Now()
+	matches, err := u.next.FuzzySearch(ctx, query, threshold, limit)
+	u.observe("fuzzy_search", start, err)
+	return matches, err
+}
+
+func (u *todoUsecase) Bulk(ctx context.Context, ops []domain.BulkOperation, atomic bool) ([]domain.BulkResult, error) {
+	start := time.Now()
+	results, err := u.next.Bulk(ctx, ops, atomic)
+	u.observe("bulk", start, err)
+	return results, err
+}
@@ -0,0 +1,182 @@
+// Package outbox relays the todo events recorded in the outbox table to the
+// rest of the system. Events are stored in the same transaction as the write
+// that caused them and handed to every sink at least once: an event is only
+// marked delivered after all sinks accepted it, and a retry only goes to the
+// sinks that have not. Sinks must still tolerate duplicates, as a crash can
+// come between a sink accepting an event and the relay recording it.
+//
+// With several replicas, each event is claimed by the relay of one of them,
+// so sinks that live in process memory, such as the change stream broker,
+// only see the events their own replica claimed.
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+	"todo-app/domain"
+)
+
+// lease hides claimed events from other relays while they are dispatched.
+// Sinks are expected to return well within it.
+const lease = time.Minute
+
+// Sink receives relayed events. An error makes the relay retry the event
+// with this sink, and any other that failed, after a backoff.
+type Sink interface {
+	Handle(ctx context.Context, event domain.TodoEvent) error
+}
+
+// SinkFunc adapts a function to Sink.
+type SinkFunc func(ctx context.Context, event domain.TodoEvent) error
+
+func (f SinkFunc) Handle(ctx context.Context, event domain.TodoEvent) error {
+	return f(ctx, event)
+}
+
+// PublisherSink hands events to an in-process publisher such as the
+// events.Broker behind the change stream.
+func PublisherSink(publisher domain.TodoEventPublisher) Sink {
+	return SinkFunc(func(ctx context.Context, event domain.TodoEvent) error {
+		publisher.Publish(ctx, event)
+		return nil
+	})
+}
+
+// Options tune a Relay.
+type Options struct {
+	// PollInterval is how often pending events are looked for.
+	PollInterval time.Duration
+	BatchSize    int
+	// RetryInterval is the wait after the first failed attempt. It doubles
+	// after every further failure, up to MaxRetryInterval.
+	RetryInterval    time.Duration
+	MaxRetryInterval time.Duration
+	// Retention is how long delivered events are kept, checked every
+	// CleanupInterval.
+	Retention       time.Duration
+	CleanupInterval time.Duration
+}
+
+type namedSink struct {
+	name string
+	sink Sink
+}
+
+// Relay dispatches pending outbox events to its sinks and purges delivered
+// ones.
+type Relay struct {
+	repo   domain.OutboxRepository
+	opts   Options
+	sinks  []namedSink
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+func NewRelay(repo domain.OutboxRepository, opts Options, logger *slog.Logger) *Relay {
+	return &Relay{
+		repo:   repo,
+		opts:   opts,
+		logger: logger,
+		now:    func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Register adds a sink. It must be called before Run. The name records
+// which sinks accepted an event, so it must be unique and stay the same
+// across restarts.
+func (r *Relay) Register(name string, sink Sink) {
+	r.sinks = append(r.sinks, namedSink{name: name, sink: sink})
+}
+
+// Run relays every PollInterval and purges every CleanupInterval until ctx
+// is done.
+func (r *Relay) Run(ctx context.Context) {
+	poll := time.NewTicker(r.opts.PollInterval)
+	defer poll.Stop()
+	cleanup := time.NewTicker(r.opts.CleanupInterval)
+	defer cleanup.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-poll.C:
+			// Keep going while full batches come back, so a backlog drains
+			// without waiting a poll interval per batch.
+			for ctx.Err() == nil {
+				n, err := r.RelayPending(ctx)
+				if err != nil || n < r.opts.BatchSize {
+					break
+				}
+			}
+		case now := <-cleanup.C:
+			n, err := r.repo.DeleteDelivered(ctx, now.Add(-r.opts.Retention))
+			if err != nil {
+				continue // Error already logged in repository
+			}
+			if n > 0 {
+				r.logger.Info("Purged delivered outbox events", "count", n)
+			}
+		}
+	}
+}
+
+// RelayPending dispatches one batch of pending events, oldest first, and
+// returns how many were claimed.
+func (r *Relay) RelayPending(ctx context.Context) (int, error) {
+	events, err := r.repo.ClaimPending(ctx, r.now(), lease, r.opts.BatchSize)
+	if err != nil {
+		return 0, err // Error already logged in repository
+	}
+
+	var delivered []uint64
+	for i := range events {
+		e := &events[i]
+		accepted, err := r.dispatch(ctx, e.TodoEvent(), e.DeliveredTo)
+		if err != nil {
+			next := r.now().Add(r.backoff(e.Attempts + 1))
+			r.logger.Warn("Failed to relay outbox event, will retry", "outbox_id", e.ID, "attempt", e.Attempts+1, "next_attempt_at", next, "error", err)
+			// If this fails too, the lease runs out and the event is retried,
+			// with the sinks that accepted it this time too.
+			_ = r.repo.Retry(ctx, e.ID, next, err.Error(), accepted)
+			continue
+		}
+		delivered = append(delivered, e.ID)
+	}
+	if err := r.repo.MarkDelivered(ctx, delivered, r.now()); err != nil {
+		return len(events), err
+	}
+	return len(events), nil
+}
+
+// dispatch hands event to every sink not in delivered, trying all of them
+// even after one fails so a broken sink does not hold back the others. It
+// returns the sinks that have accepted the event by now.
+func (r *Relay) dispatch(ctx context.Context, event domain.TodoEvent, delivered []string) ([]string, error) {
+	accepted := slices.Clone(delivered)
+	var failed error
+	for _, s := range r.sinks {
+		if slices.Contains(delivered, s.name) {
+			continue
+		}
+		if err := s.sink.Handle(ctx, event); err != nil {
+			if failed == nil {
+				failed = fmt.Errorf("%s: %w", s.name, err)
+			}
+			continue
+		}
+		accepted = append(accepted, s.name)
+	}
+	return accepted, failed
+}
+
+// backoff returns the wait after attempt failed.
+func (r *Relay) backoff(attempt int) time.Duration {
+	backoff := r.opts.RetryInterval
+	for i := 1; i < attempt && backoff < r.opts.MaxRetryInterval; i++ {
+		backoff *= 2
+	}
+	return min(backoff, r.opts.MaxRetryInterval)
+}
@@ -0,0 +1,128 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"todo-app/domain"
+	"todo-app/events"
+	"todo-app/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testOptions = Options{
+	PollInterval:     10 * time.Millisecond,
+	BatchSize:        10,
+	RetryInterval:    time.Second,
+	MaxRetryInterval: 4 * time.Second,
+	Retention:        time.Hour,
+	CleanupInterval:  time.Hour,
+}
+
+func TestRelay(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("retries failed sinks until all accept", func(t *testing.T) {
+		todos := repository.NewMemoryTodoRepo(slog.Default())
+		relay := NewRelay(todos.Outbox(), testOptions, slog.Default())
+
+		var seen []string
+		relay.Register("recorder", SinkFunc(func(_ context.Context, event domain.TodoEvent) error {
+			seen = append(seen, event.ID)
+			return nil
+		}))
+		failures := 2
+		relay.Register("flaky", SinkFunc(func(context.Context, domain.TodoEvent) error {
+			if failures > 0 {
+				failures--
+				return errors.New("unavailable")
+			}
+			return nil
+		}))
+
+		require.NoError(t, todos.Create(ctx, &domain.Todo{Title: "Buy milk", Status: "IN_PROGRESS"}))
+		now := time.Now().UTC()
+		relay.now = func() time.Time { return now }
+		n, err := relay.RelayPending(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		n, err = relay.RelayPending(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, n, "the retry waits for its backoff")
+
+		now = now.Add(time.Second)
+		_, err = relay.RelayPending(ctx)
+		require.NoError(t, err)
+		now = now.Add(2 * time.Second)
+		_, err = relay.RelayPending(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"1"}, seen, "sinks that accepted the event do not get it again")
+		assert.Zero(t, failures)
+		now = now.Add(time.Hour)
+		n, err = relay.RelayPending(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, n, "delivered events are not relayed again")
+	})
+
+	t.Run("backoff doubles up to the maximum", func(t *testing.T) {
+		relay := NewRelay(nil, testOptions, slog.Default())
+
+		assert.Equal(t, time.Second, relay.backoff(1))
+		assert.Equal(t, 2*time.Second, relay.backoff(2))
+		assert.Equal(t, 4*time.Second, relay.backoff(3))
+		assert.Equal(t, 4*time.Second, relay.backoff(30))
+	})
+
+	t.Run("run publishes to the change stream and event log", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		todos := repository.NewMemoryTodoRepo(slog.Default())
+		broker := events.NewBroker(10, 10)
+		sub, _, _ := broker.Subscribe("")
+		path := filepath.Join(t.TempDir(), "events.jsonl")
+		file, err := NewFileSink(path)
+		require.NoError(t, err)
+
+		relay := NewRelay(todos.Outbox(), testOptions, slog.Default())
+		relay.Register("change stream", PublisherSink(broker))
+		relay.Register("event log", file)
+		stopped := make(chan struct{})
+		go func() {
+			relay.Run(ctx)
+			close(stopped)
+		}()
+
+		require.NoError(t, todos.Create(ctx, &domain.Todo{Title: "Buy milk", Status: "IN_PROGRESS"}))
+		select {
+		case event := <-sub.C:
+			assert.Equal(t, domain.TodoCreated, event.Type)
+			assert.Equal(t, "Buy milk", event.Todo.Title)
+		case <-time.After(5 * time.Second):
+			t.Fatal("event not relayed")
+		}
+		cancel()
+		<-stopped
+		require.NoError(t, file.Close())
+
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		defer f.Close()
+		lines := bufio.NewScanner(f)
+		require.True(t, lines.Scan())
+		var logged domain.TodoEvent
+		require.NoError(t, json.Unmarshal(lines.Bytes(), &logged))
+		assert.Equal(t, "1", logged.ID)
+		assert.Equal(t, "Buy milk", logged.Todo.Title)
+		assert.False(t, lines.Scan())
+	})
+}
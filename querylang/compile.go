@@ -0,0 +1,200 @@
+package querylang
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"todo-app/domain"
+	"unicode/utf8"
+)
+
+// dateLayout is the layout of absolute dates in queries.
+const dateLayout = "2006-01-02"
+
+// textFields are the fields compared as text, with the filter operation
+// their ":" and "=" operators map to.
+var textFields = map[string]struct {
+	field domain.FilterField
+	op    domain.FilterOp
+}{
+	"status":      {domain.FilterStatus, domain.OpEq},
+	"project":     {domain.FilterProject, domain.OpEq},
+	"tag":         {domain.FilterTag, domain.OpEq},
+	"title":       {domain.FilterTitle, domain.OpContains},
+	"description": {domain.FilterDescription, domain.OpContains},
+}
+
+var dateFields = map[string]domain.FilterField{
+	"due":       domain.FilterDue,
+	"created":   domain.FilterCreated,
+	"updated":   domain.FilterUpdated,
+	"completed": domain.FilterCompleted,
+}
+
+// ParseFilter parses query and compiles it with Compile.
+func ParseFilter(query string, now time.Time) (domain.Filter, error) {
+	node, err := Parse(query)
+	if err != nil {
+		return nil, err
+	}
+	return Compile(node, now)
+}
+
+// Compile translates node into a repository filter. Field names are case
+// insensitive:
+//
+//   - status, project and tag match exactly with ":" or "=", and tag matches
+//     todos having the tag. status must be IN_PROGRESS or COMPLETED.
+//   - title and description match text containing the value.
+//   - due, created, updated and completed compare dates, as YYYY-MM-DD or
+//     one of today, yesterday, tomorrow, today+Nd, today-Nd and startofweek.
+//     ":" and "=" match the whole day; "<", "<=", ">" and ">=" compare with
+//     it. due:none matches todos without a due date, and completed only
+//     matches completed todos.
+//
+// "!=" negates ":". Dates are days in the location of now, relative ones
+// are resolved against now. Errors are *domain.QueryError.
+func Compile(node Node, now time.Time) (domain.Filter, error) {
+	switch n := node.(type) {
+	case *And:
+		filters, err := compileAll(n.Operands, now)
+		if err != nil {
+			return nil, err
+		}
+		if len(filters) == 1 {
+			return filters[0], nil
+		}
+		return domain.AndFilter(filters), nil
+	case *Or:
+		filters, err := compileAll(n.Operands, now)
+		if err != nil {
+			return nil, err
+		}
+		return domain.OrFilter(filters), nil
+	case *Not:
+		filter, err := Compile(n.Operand, now)
+		if err != nil {
+			return nil, err
+		}
+		return domain.NotFilter{Filter: filter}, nil
+	case *Term:
+		return compileTerm(n, now)
+	default:
+		return nil, fmt.Errorf("querylang: unknown node %T", node)
+	}
+}
+
+func compileAll(nodes []Node, now time.Time) ([]domain.Filter, error) {
+	filters := make([]domain.Filter, 0, len(nodes))
+	for _, node := range nodes {
+		filter, err := Compile(node, now)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+func compileTerm(t *Term, now time.Time) (domain.Filter, error) {
+	if t.Field == "" {
+		return domain.FieldFilter{Field: domain.FilterText, Op: domain.OpContains, Value: strings.ToLower(t.Value)}, nil
+	}
+	name := strings.ToLower(t.Field)
+	if field, ok := textFields[name]; ok {
+		if t.Op != ":" && t.Op != "=" && t.Op != "!=" {
+			return nil, invalidOperator(t)
+		}
+		filter := domain.FieldFilter{Field: field.field, Op: field.op, Value: t.Value}
+		switch {
+		case field.field == domain.FilterStatus:
+			status := strings.ToUpper(t.Value)
+			if status != "IN_PROGRESS" && status != "COMPLETED" {
+				return nil, &domain.QueryError{Position: t.ValuePos, Code: domain.QueryInvalidValue, Param: t.Value}
+			}
+			filter.Value = status
+		case field.op == domain.OpContains:
+			filter.Value = strings.ToLower(t.Value)
+		}
+		return negateIf(t.Op == "!=", filter), nil
+	}
+	if field, ok := dateFields[name]; ok {
+		return compileDate(t, field, now)
+	}
+	return nil, &domain.QueryError{Position: t.Pos, Code: domain.QueryUnknownField, Param: t.Field}
+}
+
+func compileDate(t *Term, field domain.FilterField, now time.Time) (domain.Filter, error) {
+	if field == domain.FilterDue && strings.EqualFold(t.Value, "none") {
+		if t.Op != ":" && t.Op != "=" && t.Op != "!=" {
+			return nil, invalidOperator(t)
+		}
+		return negateIf(t.Op == "!=", domain.FieldFilter{Field: field, Op: domain.OpUnset}), nil
+	}
+	day, ok := parseDate(t.Value, now)
+	if !ok {
+		return nil, &domain.QueryError{Position: t.ValuePos, Code: domain.QueryInvalidDate, Param: t.Value}
+	}
+	next := day.AddDate(0, 0, 1)
+	before := func(v time.Time) domain.Filter {
+		return domain.FieldFilter{Field: field, Op: domain.OpBefore, Value: v}
+	}
+	notBefore := func(v time.Time) domain.Filter {
+		return domain.FieldFilter{Field: field, Op: domain.OpNotBefore, Value: v}
+	}
+	switch t.Op {
+	case ":", "=":
+		return domain.AndFilter{notBefore(day), before(next)}, nil
+	case "!=":
+		return domain.NotFilter{Filter: domain.AndFilter{notBefore(day), before(next)}}, nil
+	case "<":
+		return before(day), nil
+	case "<=":
+		return before(next), nil
+	case ">":
+		return notBefore(next), nil
+	case ">=":
+		return notBefore(day), nil
+	}
+	return nil, invalidOperator(t)
+}
+
+// parseDate resolves value to the start of a day in the location of now.
+func parseDate(value string, now time.Time) (time.Time, bool) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch v := strings.ToLower(value); {
+	case v == "today":
+		return today, true
+	case v == "tomorrow":
+		return today.AddDate(0, 0, 1), true
+	case v == "yesterday":
+		return today.AddDate(0, 0, -1), true
+	case v == "startofweek":
+		// Weeks start on Monday.
+		return today.AddDate(0, 0, -(int(today.Weekday())+6)%7), true
+	case strings.HasPrefix(v, "today") && strings.HasSuffix(v, "d"):
+		offset := strings.TrimSuffix(strings.TrimPrefix(v, "today"), "d")
+		if len(offset) < 2 || (offset[0] != '+' && offset[0] != '-') {
+			return time.Time{}, false
+		}
+		days, err := strconv.Atoi(offset)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return today.AddDate(0, 0, days), true
+	}
+	day, err := time.ParseInLocation(dateLayout, value, now.Location())
+	return day, err == nil
+}
+
+func negateIf(negate bool, filter domain.Filter) domain.Filter {
+	if negate {
+		return domain.NotFilter{Filter: filter}
+	}
+	return filter
+}
+
+func invalidOperator(t *Term) error {
+	return &domain.QueryError{Position: t.Pos + utf8.RuneCountInString(t.Field), Code: domain.QueryInvalidOperator, Param: t.Op}
+}
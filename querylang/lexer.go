@@ -0,0 +1,164 @@
+package querylang
+
+import (
+	"strings"
+	"todo-app/domain"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokPhrase
+	tokField
+	tokLParen
+	tokRParen
+	tokNot
+	tokAnd
+	tokOr
+)
+
+// token is a lexed piece of a query. Positions are 1-based rune offsets.
+type token struct {
+	kind tokenKind
+	pos  int
+	// text is the word or phrase, or the field name of a tokField.
+	text string
+	// op, value, valuePos and quoted describe the comparison of a tokField.
+	op       string
+	value    string
+	valuePos int
+	quoted   bool
+}
+
+// operators lists the comparisons, longest first so "<=" wins over "<".
+var operators = []string{"!=", "<=", ">=", ":", "=", "<", ">"}
+
+func isOperatorStart(r rune) bool {
+	return strings.ContainsRune(":=!<>", r)
+}
+
+// isDelimiter reports whether r ends a word.
+func isDelimiter(r rune) bool {
+	return unicode.IsSpace(r) || r == '(' || r == ')' || r == '"'
+}
+
+type lexer struct {
+	src []rune
+	i   int
+}
+
+// lex splits query into tokens, ending with a tokEOF.
+func lex(query string) ([]token, error) {
+	l := &lexer{src: []rune(query)}
+	var tokens []token
+	for {
+		t, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+		if t.kind == tokEOF {
+			return tokens, nil
+		}
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	for l.i < len(l.src) && unicode.IsSpace(l.src[l.i]) {
+		l.i++
+	}
+	pos := l.i + 1
+	if l.i == len(l.src) {
+		return token{kind: tokEOF, pos: pos}, nil
+	}
+
+	switch r := l.src[l.i]; {
+	case r == '(':
+		l.i++
+		return token{kind: tokLParen, pos: pos, text: "("}, nil
+	case r == ')':
+		l.i++
+		return token{kind: tokRParen, pos: pos, text: ")"}, nil
+	case r == '"':
+		phrase, err := l.phrase()
+		return token{kind: tokPhrase, pos: pos, text: phrase}, err
+	case r == '-' && l.i+1 < len(l.src) && !isDelimiter(l.src[l.i+1]):
+		l.i++
+		return token{kind: tokNot, pos: pos, text: "-"}, nil
+	case isOperatorStart(r):
+		return token{}, &domain.QueryError{Position: pos, Code: domain.QueryUnexpectedToken, Param: string(r)}
+	}
+
+	start := l.i
+	for l.i < len(l.src) && !isDelimiter(l.src[l.i]) && !isOperatorStart(l.src[l.i]) {
+		l.i++
+	}
+	word := string(l.src[start:l.i])
+	if l.i < len(l.src) && isOperatorStart(l.src[l.i]) {
+		return l.field(word, pos)
+	}
+	switch word {
+	case "OR":
+		return token{kind: tokOr, pos: pos, text: word}, nil
+	case "AND":
+		return token{kind: tokAnd, pos: pos, text: word}, nil
+	case "NOT":
+		return token{kind: tokNot, pos: pos, text: word}, nil
+	}
+	return token{kind: tokWord, pos: pos, text: word}, nil
+}
+
+// field lexes the operator and value following the field name.
+func (l *lexer) field(name string, pos int) (token, error) {
+	t := token{kind: tokField, pos: pos, text: name}
+	rest := string(l.src[l.i:])
+	for _, op := range operators {
+		if strings.HasPrefix(rest, op) {
+			t.op = op
+			break
+		}
+	}
+	if t.op == "" {
+		return token{}, &domain.QueryError{Position: l.i + 1, Code: domain.QueryUnexpectedToken, Param: string(l.src[l.i])}
+	}
+	l.i += len([]rune(t.op))
+
+	t.valuePos = l.i + 1
+	if l.i < len(l.src) && l.src[l.i] == '"' {
+		value, err := l.phrase()
+		if err != nil {
+			return token{}, err
+		}
+		t.value, t.quoted = value, true
+		return t, nil
+	}
+	start := l.i
+	for l.i < len(l.src) && !isDelimiter(l.src[l.i]) {
+		l.i++
+	}
+	t.value = string(l.src[start:l.i])
+	if t.value == "" {
+		return token{}, &domain.QueryError{Position: t.valuePos, Code: domain.QueryMissingValue, Param: name + t.op}
+	}
+	return t, nil
+}
+
+// phrase lexes a quoted phrase starting at the opening quote and returns it
+// without the quotes.
+func (l *lexer) phrase() (string, error) {
+	open := l.i
+	l.i++
+	end := l.i
+	for end < len(l.src) && l.src[end] != '"' {
+		end++
+	}
+	if end == len(l.src) {
+		return "", &domain.QueryError{Position: open + 1, Code: domain.QueryUnterminatedQuote}
+	}
+	phrase := string(l.src[l.i:end])
+	l.i = end + 1
+	return phrase, nil
+}
@@ -0,0 +1,183 @@
+// Package querylang parses the filter queries accepted by GET /todos?q=,
+// such as
+//
+//	status:IN_PROGRESS tag:backend due<2026-11-01 "release notes" -archived
+//
+// Terms separated by spaces must all match; OR separates alternatives and
+// binds looser than the implicit AND; a leading - or NOT negates a term or a
+// parenthesized group. A bare word or "quoted phrase" matches the title or
+// description. field:value compares a field, see Compile for the fields and
+// operators.
+package querylang
+
+import (
+	"todo-app/domain"
+	"unicode/utf8"
+)
+
+// Node is a node of a parsed query.
+type Node interface {
+	// Position is the 1-based rune offset of the node in the query.
+	Position() int
+}
+
+// And matches when every operand matches.
+type And struct {
+	Operands []Node
+	Pos      int
+}
+
+// Or matches when any operand matches.
+type Or struct {
+	Operands []Node
+	Pos      int
+}
+
+// Not matches when its operand does not.
+type Not struct {
+	Operand Node
+	Pos     int
+}
+
+// Term is a bare word or phrase when Field is empty, and the comparison
+// Field Op Value otherwise.
+type Term struct {
+	Field    string
+	Op       string
+	Value    string
+	Quoted   bool
+	Pos      int
+	ValuePos int
+}
+
+func (n *And) Position() int  { return n.Pos }
+func (n *Or) Position() int   { return n.Pos }
+func (n *Not) Position() int  { return n.Pos }
+func (n *Term) Position() int { return n.Pos }
+
+// Parse parses query. An empty query parses to an And without operands,
+// which matches every todo. Syntax errors are *domain.QueryError.
+func Parse(query string) (Node, error) {
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, end: utf8.RuneCountInString(query) + 1}
+	if p.peek().kind == tokEOF {
+		return &And{Pos: 1}, nil
+	}
+	node, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.unexpected(t)
+	}
+	return node, nil
+}
+
+type parser struct {
+	tokens []token
+	i      int
+	// end is the position just after the query, reported for errors at
+	// its end.
+	end int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+// or parses and { "OR" and }.
+func (p *parser) or() (Node, error) {
+	first, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	operands := []Node{first}
+	for p.peek().kind == tokOr {
+		p.next()
+		operand, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+	}
+	if len(operands) == 1 {
+		return first, nil
+	}
+	return &Or{Operands: operands, Pos: first.Position()}, nil
+}
+
+// and parses unary { ["AND"] unary }.
+func (p *parser) and() (Node, error) {
+	var operands []Node
+	for {
+		switch t := p.peek(); t.kind {
+		case tokEOF, tokRParen, tokOr:
+			if len(operands) == 0 {
+				return nil, p.unexpected(t)
+			}
+			if len(operands) == 1 {
+				return operands[0], nil
+			}
+			return &And{Operands: operands, Pos: operands[0].Position()}, nil
+		case tokAnd:
+			if len(operands) == 0 {
+				return nil, p.unexpected(t)
+			}
+			p.next()
+			if next := p.peek(); next.kind == tokEOF || next.kind == tokRParen || next.kind == tokOr || next.kind == tokAnd {
+				return nil, p.unexpected(next)
+			}
+		}
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+	}
+}
+
+// unary parses ("-" | "NOT") unary | "(" or ")" | term.
+func (p *parser) unary() (Node, error) {
+	switch t := p.next(); t.kind {
+	case tokNot:
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Operand: operand, Pos: t.pos}, nil
+	case tokLParen:
+		node, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokRParen {
+			return nil, &domain.QueryError{Position: t.pos, Code: domain.QueryUnclosedParen}
+		}
+		p.next()
+		return node, nil
+	case tokWord, tokPhrase:
+		return &Term{Value: t.text, Quoted: t.kind == tokPhrase, Pos: t.pos, ValuePos: t.pos}, nil
+	case tokField:
+		return &Term{Field: t.text, Op: t.op, Value: t.value, Quoted: t.quoted, Pos: t.pos, ValuePos: t.valuePos}, nil
+	default:
+		return nil, p.unexpected(t)
+	}
+}
+
+func (p *parser) unexpected(t token) error {
+	if t.kind == tokEOF {
+		return &domain.QueryError{Position: p.end, Code: domain.QueryUnexpectedEnd}
+	}
+	return &domain.QueryError{Position: t.pos, Code: domain.QueryUnexpectedToken, Param: t.text}
+}
@@ -0,0 +1,165 @@
+package querylang
+
+import (
+	"testing"
+	"time"
+	"todo-app/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// now is a Wednesday.
+var now = time.Date(2026, 10, 14, 15, 30, 0, 0, time.UTC)
+
+func day(s string) time.Time {
+	d, err := time.Parse(dateLayout, s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func text(v string) domain.Filter {
+	return domain.FieldFilter{Field: domain.FilterText, Op: domain.OpContains, Value: v}
+}
+
+func TestParse(t *testing.T) {
+	node, err := Parse(`status:IN_PROGRESS (tag:"api docs" OR -due<=today) NOT draft`)
+	require.NoError(t, err)
+
+	want := &And{Pos: 1, Operands: []Node{
+		&Term{Field: "status", Op: ":", Value: "IN_PROGRESS", Pos: 1, ValuePos: 8},
+		&Or{Pos: 21, Operands: []Node{
+			&Term{Field: "tag", Op: ":", Value: "api docs", Quoted: true, Pos: 21, ValuePos: 25},
+			&Not{Pos: 39, Operand: &Term{Field: "due", Op: "<=", Value: "today", Pos: 40, ValuePos: 45}},
+		}},
+		&Not{Pos: 52, Operand: &Term{Value: "draft", Pos: 56, ValuePos: 56}},
+	}}
+	assert.Equal(t, want, node)
+}
+
+func TestParse_Empty(t *testing.T) {
+	node, err := Parse("   ")
+	require.NoError(t, err)
+
+	filter, err := Compile(node, now)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AndFilter{}, filter)
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		query string
+		want  domain.QueryError
+	}{
+		{`"release notes`, domain.QueryError{Position: 1, Code: domain.QueryUnterminatedQuote}},
+		{`tag:"api`, domain.QueryError{Position: 5, Code: domain.QueryUnterminatedQuote}},
+		{"(a OR b", domain.QueryError{Position: 1, Code: domain.QueryUnclosedParen}},
+		{"a )", domain.QueryError{Position: 3, Code: domain.QueryUnexpectedToken, Param: ")"}},
+		{"a OR", domain.QueryError{Position: 5, Code: domain.QueryUnexpectedEnd}},
+		{"OR a", domain.QueryError{Position: 1, Code: domain.QueryUnexpectedToken, Param: "OR"}},
+		{"a AND OR b", domain.QueryError{Position: 7, Code: domain.QueryUnexpectedToken, Param: "OR"}},
+		{"NOT", domain.QueryError{Position: 4, Code: domain.QueryUnexpectedEnd}},
+		{"()", domain.QueryError{Position: 2, Code: domain.QueryUnexpectedToken, Param: ")"}},
+		{"tag: backend", domain.QueryError{Position: 5, Code: domain.QueryMissingValue, Param: "tag:"}},
+		{"a :b", domain.QueryError{Position: 3, Code: domain.QueryUnexpectedToken, Param: ":"}},
+		{"tag!b", domain.QueryError{Position: 4, Code: domain.QueryUnexpectedToken, Param: "!"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := Parse(tt.query)
+
+			var qe *domain.QueryError
+			require.ErrorAs(t, err, &qe)
+			assert.Equal(t, tt.want, *qe)
+			assert.ErrorIs(t, err, domain.ErrInvalidQuery)
+		})
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		query string
+		want  domain.Filter
+	}{
+		{"Milk", text("milk")},
+		{`"Release Notes" -archived`, domain.AndFilter{
+			text("release notes"),
+			domain.NotFilter{Filter: text("archived")},
+		}},
+		{"status:in_progress", domain.FieldFilter{Field: domain.FilterStatus, Op: domain.OpEq, Value: "IN_PROGRESS"}},
+		{"Tag!=Backend", domain.NotFilter{Filter: domain.FieldFilter{Field: domain.FilterTag, Op: domain.OpEq, Value: "Backend"}}},
+		{"project=Home title:Call", domain.AndFilter{
+			domain.FieldFilter{Field: domain.FilterProject, Op: domain.OpEq, Value: "Home"},
+			domain.FieldFilter{Field: domain.FilterTitle, Op: domain.OpContains, Value: "call"},
+		}},
+		{"a OR b c", domain.OrFilter{text("a"), domain.AndFilter{text("b"), text("c")}}},
+		{"due:none", domain.FieldFilter{Field: domain.FilterDue, Op: domain.OpUnset}},
+		{"due!=none", domain.NotFilter{Filter: domain.FieldFilter{Field: domain.FilterDue, Op: domain.OpUnset}}},
+		{"due<2026-11-01", domain.FieldFilter{Field: domain.FilterDue, Op: domain.OpBefore, Value: day("2026-11-01")}},
+		{"due<=2026-11-01", domain.FieldFilter{Field: domain.FilterDue, Op: domain.OpBefore, Value: day("2026-11-02")}},
+		{"created>yesterday", domain.FieldFilter{Field: domain.FilterCreated, Op: domain.OpNotBefore, Value: day("2026-10-14")}},
+		{"updated>=startofweek", domain.FieldFilter{Field: domain.FilterUpdated, Op: domain.OpNotBefore, Value: day("2026-10-12")}},
+		{"completed<today", domain.FieldFilter{Field: domain.FilterCompleted, Op: domain.OpBefore, Value: day("2026-10-14")}},
+		{"due:today+7d", domain.AndFilter{
+			domain.FieldFilter{Field: domain.FilterDue, Op: domain.OpNotBefore, Value: day("2026-10-21")},
+			domain.FieldFilter{Field: domain.FilterDue, Op: domain.OpBefore, Value: day("2026-10-22")},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			filter, err := ParseFilter(tt.query, now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, filter)
+		})
+	}
+}
+
+func TestParseFilter_TimeZone(t *testing.T) {
+	bangkok, err := time.LoadLocation("Asia/Bangkok")
+	require.NoError(t, err)
+	// Still Wednesday in UTC, already Thursday in Bangkok.
+	now := time.Date(2026, 10, 14, 20, 0, 0, 0, time.UTC).In(bangkok)
+	thursday := time.Date(2026, 10, 15, 0, 0, 0, 0, bangkok)
+
+	tests := []struct {
+		query string
+		want  domain.Filter
+	}{
+		{"due<today", domain.FieldFilter{Field: domain.FilterDue, Op: domain.OpBefore, Value: thursday}},
+		{"due<2026-10-15", domain.FieldFilter{Field: domain.FilterDue, Op: domain.OpBefore, Value: thursday}},
+		{"completed>=startofweek", domain.FieldFilter{Field: domain.FilterCompleted, Op: domain.OpNotBefore, Value: thursday.AddDate(0, 0, -3)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			filter, err := ParseFilter(tt.query, now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, filter)
+		})
+	}
+}
+
+func TestParseFilter_Errors(t *testing.T) {
+	tests := []struct {
+		query string
+		want  domain.QueryError
+	}{
+		{"a color:red", domain.QueryError{Position: 3, Code: domain.QueryUnknownField, Param: "color"}},
+		{"status:DONE", domain.QueryError{Position: 8, Code: domain.QueryInvalidValue, Param: "DONE"}},
+		{"tag<b", domain.QueryError{Position: 4, Code: domain.QueryInvalidOperator, Param: "<"}},
+		{"due<none", domain.QueryError{Position: 4, Code: domain.QueryInvalidOperator, Param: "<"}},
+		{"due:2026-13-01", domain.QueryError{Position: 5, Code: domain.QueryInvalidDate, Param: "2026-13-01"}},
+		{"created:none", domain.QueryError{Position: 9, Code: domain.QueryInvalidDate, Param: "none"}},
+		{"due>today+d", domain.QueryError{Position: 5, Code: domain.QueryInvalidDate, Param: "today+d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := ParseFilter(tt.query, now)
+
+			var qe *domain.QueryError
+			require.ErrorAs(t, err, &qe)
+			assert.Equal(t, tt.want, *qe)
+		})
+	}
+}
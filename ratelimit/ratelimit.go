@@ -0,0 +1,120 @@
+// Package ratelimit implements token-bucket rate limiting behind a Store
+// interface, so the in-memory store can be swapped for a shared one when
+// several replicas serve the API.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is a token bucket: Burst requests may be made at once and the
+// bucket refills at PerMinute requests per minute.
+type Limit struct {
+	PerMinute int
+	Burst     int
+}
+
+func (l Limit) rate() float64 {
+	return float64(l.PerMinute) / 60
+}
+
+// Policy renders l in the RateLimit-Policy header format.
+func (l Limit) Policy() string {
+	return fmt.Sprintf("%d;w=60;burst=%d", l.PerMinute, l.Burst)
+}
+
+// Result describes the bucket after a Take.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed. It is zero
+	// when Allowed is true.
+	RetryAfter time.Duration
+}
+
+// Store takes one token for key from a bucket shaped by limit.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// full is when the bucket will have refilled completely.
+	full time.Time
+}
+
+// take refills b up to now and takes a token if one is available.
+func (b *bucket) take(limit Limit, now time.Time) Result {
+	rate := limit.rate()
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(limit.Burst), b.tokens+elapsed*rate)
+		b.last = now
+	}
+
+	res := Result{Limit: limit.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	toFull := (float64(limit.Burst) - b.tokens) / rate
+	b.full = now.Add(time.Duration(toFull * float64(time.Second)))
+	res.Remaining = int(b.tokens)
+	res.Reset = seconds(toFull)
+	return res
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s)) * time.Second
+}
+
+// MemoryStore keeps buckets in process memory. Buckets that have refilled
+// completely are dropped periodically, so memory is bounded by the number
+// of recently active clients.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket)}
+}
+
+const sweepInterval = time.Minute
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		s.sweep(now)
+	}
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		s.buckets[key] = b
+	}
+	return b.take(limit, now), nil
+}
+
+// sweep drops buckets that have refilled completely; recreating them later
+// yields the same full bucket.
+func (s *MemoryStore) sweep(now time.Time) {
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	limit := Limit{PerMinute: 60, Burst: 2}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	first, err := store.Take(ctx, "alice", limit, now)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Allowed: true, Limit: 2, Remaining: 1, Reset: time.Second}, first)
+
+	second, _ := store.Take(ctx, "alice", limit, now)
+	assert.True(t, second.Allowed)
+	assert.Equal(t, 0, second.Remaining)
+	assert.Equal(t, 2*time.Second, second.Reset)
+
+	denied, _ := store.Take(ctx, "alice", limit, now)
+	assert.False(t, denied.Allowed)
+	assert.Equal(t, time.Second, denied.RetryAfter)
+
+	other, _ := store.Take(ctx, "bob", limit, now)
+	assert.True(t, other.Allowed, "buckets are per key")
+
+	refilled, _ := store.Take(ctx, "alice", limit, now.Add(time.Second))
+	assert.True(t, refilled.Allowed, "one token refills per second at 60/min")
+}
+
+func TestMemoryStore_Sweep(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	store.Take(ctx, "fast", Limit{PerMinute: 60, Burst: 1}, now)
+	for i := 0; i < 3; i++ {
+		store.Take(ctx, "slow", Limit{PerMinute: 1, Burst: 5}, now)
+	}
+	store.Take(ctx, "trigger", Limit{PerMinute: 60, Burst: 1}, now.Add(2*sweepInterval/3))
+	store.Take(ctx, "trigger", Limit{PerMinute: 60, Burst: 1}, now.Add(sweepInterval+time.Second))
+
+	assert.NotContains(t, store.buckets, "fast", "refilled buckets are dropped")
+	assert.Contains(t, store.buckets, "slow", "buckets still refilling are kept")
+}
+
+func TestLimit_Policy(t *testing.T) {
+	assert.Equal(t, "120;w=60;burst=30", Limit{PerMinute: 120, Burst: 30}.Policy())
+}
@@ -0,0 +1,315 @@
+// Package realtime runs the collaboration hub behind the WebSocket endpoint:
+// clients subscribe to todo lists, receive the changes made to them and see
+// who else is viewing the same lists.
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"todo-app/domain"
+	"todo-app/events"
+)
+
+var (
+	// ErrTooManySubscriptions is returned by Client.Subscribe when the
+	// client already follows the maximum number of lists.
+	ErrTooManySubscriptions = errors.New("too many subscriptions")
+	// ErrSlowClient is why a client whose send buffer filled up was
+	// disconnected.
+	ErrSlowClient = errors.New("client too slow")
+	// ErrHubClosed is why clients are disconnected on shutdown.
+	ErrHubClosed = errors.New("hub closed")
+)
+
+// Hub fans the events of a broker out to the clients subscribed to the
+// todo lists they concern and tracks who is viewing each list. Sending
+// never blocks: a client whose buffer is full is disconnected.
+type Hub struct {
+	broker           *events.Broker
+	sub              *events.Subscription
+	sendBuffer       int
+	maxSubscriptions int
+	logger           *slog.Logger
+
+	mu      sync.Mutex
+	clients map[*Client]struct{}
+	lists   map[string]map[*Client]struct{}
+	stale   map[string]struct{} // lists whose presence changed
+	closed  bool
+}
+
+// NewHub returns a Hub relaying the events broker publishes from now on
+// once Run is started. Each client may fall sendBuffer messages behind and
+// follow up to maxSubscriptions lists.
+func NewHub(broker *events.Broker, sendBuffer, maxSubscriptions int, logger *slog.Logger) *Hub {
+	sub, _, _ := broker.Subscribe("")
+	return &Hub{
+		broker:           broker,
+		sub:              sub,
+		sendBuffer:       sendBuffer,
+		maxSubscriptions: maxSubscriptions,
+		logger:           logger,
+		clients:          make(map[*Client]struct{}),
+		lists:            make(map[string]map[*Client]struct{}),
+		stale:            make(map[string]struct{}),
+	}
+}
+
+// Client is one connection to the hub. C receives the messages to send; it
+// is closed when the client is closed, disconnected for being too slow or
+// the hub shuts down, and Err then tells which.
+type Client struct {
+	C    <-chan Message
+	User string
+
+	hub   *Hub
+	send  chan Message
+	lists map[string]struct{} // guarded by hub.mu
+	err   error               // guarded by hub.mu
+}
+
+// Connect registers a client for user.
+func (h *Hub) Connect(user string) *Client {
+	send := make(chan Message, h.sendBuffer)
+	c := &Client{C: send, User: user, hub: h, send: send, lists: make(map[string]struct{})}
+
+	h.mu.Lock()
+	defer h.unlock()
+	if h.closed {
+		c.err = ErrHubClosed
+		close(send)
+		return c
+	}
+	h.clients[c] = struct{}{}
+	return c
+}
+
+// Subscribe adds list to the client's lists and sends the list's presence
+// to everyone viewing it, the client included.
+func (c *Client) Subscribe(list string) error {
+	h := c.hub
+	h.mu.Lock()
+	defer h.unlock()
+	if _, ok := h.clients[c]; !ok {
+		return nil
+	}
+	if _, ok := c.lists[list]; ok {
+		h.enqueue(c, h.presence(list))
+		return nil
+	}
+	if len(c.lists) >= h.maxSubscriptions {
+		return ErrTooManySubscriptions
+	}
+
+	c.lists[list] = struct{}{}
+	if h.lists[list] == nil {
+		h.lists[list] = make(map[*Client]struct{})
+	}
+	h.lists[list][c] = struct{}{}
+	h.stale[list] = struct{}{}
+	return nil
+}
+
+// Unsubscribe removes list from the client's lists and tells the remaining
+// viewers.
+func (c *Client) Unsubscribe(list string) {
+	h := c.hub
+	h.mu.Lock()
+	defer h.unlock()
+	if _, ok := c.lists[list]; !ok {
+		return
+	}
+	h.leave(c, list)
+}
+
+// Send queues msg for the client.
+func (c *Client) Send(msg Message) {
+	c.hub.mu.Lock()
+	defer c.hub.unlock()
+	c.hub.enqueue(c, msg)
+}
+
+// Close disconnects the client. It is safe to call more than once.
+func (c *Client) Close() {
+	c.hub.mu.Lock()
+	defer c.hub.unlock()
+	c.hub.remove(c, nil)
+}
+
+// Err returns why C was closed: nil after Close, ErrSlowClient or
+// ErrHubClosed.
+func (c *Client) Err() error {
+	c.hub.mu.Lock()
+	defer c.hub.mu.Unlock()
+	return c.err
+}
+
+// Run relays broker events until ctx is done or the broker is closed. If the
+// hub falls behind the broker it resubscribes from the last event it saw,
+// and tells every client to reload when events were lost.
+func (h *Hub) Run(ctx context.Context) {
+	sub, last := h.sub, ""
+	for {
+		last = h.follow(ctx, sub, last)
+		sub.Close()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-h.broker.Done():
+			return
+		default:
+			h.logger.Warn("Realtime hub fell behind the event broker, resubscribing", "last_event_id", last)
+		}
+
+		var missed []domain.TodoEvent
+		var complete bool
+		sub, missed, complete = h.broker.Subscribe(last)
+		if !complete {
+			h.logger.Warn("Realtime hub missed events, resetting clients", "last_event_id", last)
+			h.reset()
+		}
+		for _, event := range missed {
+			h.dispatch(event)
+			last = event.ID
+		}
+	}
+}
+
+// follow dispatches the events of sub until it ends or ctx is done and
+// returns the ID of the last one.
+func (h *Hub) follow(ctx context.Context, sub *events.Subscription, last string) string {
+	for {
+		select {
+		case <-ctx.Done():
+			return last
+		case event, ok := <-sub.C:
+			if !ok {
+				return last
+			}
+			h.dispatch(event)
+			last = event.ID
+		}
+	}
+}
+
+// Close disconnects every client with ErrHubClosed and refuses new ones.
+// Hijacked connections are not drained by http.Server.Shutdown, so this
+// must run when shutdown starts.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.unlock()
+	h.closed = true
+	for c := range h.clients {
+		h.remove(c, ErrHubClosed)
+	}
+}
+
+// dispatch sends event to the subscribers of the list the todo is in and,
+// when an update moved it, of the list it left.
+func (h *Hub) dispatch(event domain.TodoEvent) {
+	lists := []string{event.Todo.Project}
+	var patch map[string]json.RawMessage
+	if event.Previous != nil {
+		if event.Previous.Project != event.Todo.Project {
+			lists = append(lists, event.Previous.Project)
+		}
+		var err error
+		if patch, err = mergePatch(event.Previous, event.Todo); err != nil {
+			h.logger.Error("Failed to build todo patch", "event_id", event.ID, "error", err)
+		}
+	}
+
+	h.mu.Lock()
+	defer h.unlock()
+	for _, list := range lists {
+		msg := Message{Type: TypeEvent, List: list, Event: &event, Patch: patch}
+		for c := range h.lists[list] {
+			h.enqueue(c, msg)
+		}
+	}
+}
+
+func (h *Hub) reset() {
+	h.mu.Lock()
+	defer h.unlock()
+	for c := range h.clients {
+		h.enqueue(c, Message{Type: TypeReset})
+	}
+}
+
+// enqueue sends msg to c without blocking, disconnecting c if its buffer is
+// full. h.mu must be held.
+func (h *Hub) enqueue(c *Client, msg Message) {
+	if _, ok := h.clients[c]; !ok {
+		return
+	}
+	select {
+	case c.send <- msg:
+	default:
+		h.logger.Info("Disconnecting slow realtime client", "user", c.User)
+		h.remove(c, ErrSlowClient)
+	}
+}
+
+// remove unregisters c, closes its channel and updates the presence of the
+// lists it was viewing. h.mu must be held.
+func (h *Hub) remove(c *Client, err error) {
+	if _, ok := h.clients[c]; !ok {
+		return
+	}
+	delete(h.clients, c)
+	c.err = err
+	close(c.send)
+
+	for list := range c.lists {
+		h.leave(c, list)
+	}
+}
+
+// leave removes c from list and marks the list's presence stale. h.mu must
+// be held.
+func (h *Hub) leave(c *Client, list string) {
+	delete(c.lists, list)
+	delete(h.lists[list], c)
+	if len(h.lists[list]) == 0 {
+		delete(h.lists, list)
+	}
+	h.stale[list] = struct{}{}
+}
+
+// unlock sends the presence of every stale list to its viewers, then
+// releases h.mu. Presence is sent last so that a client dropped while
+// messages were being sent is never reported as still viewing.
+func (h *Hub) unlock() {
+	defer h.mu.Unlock()
+	for len(h.stale) > 0 {
+		for list := range h.stale {
+			delete(h.stale, list)
+			if h.closed {
+				continue
+			}
+			msg := h.presence(list)
+			for c := range h.lists[list] {
+				h.enqueue(c, msg)
+			}
+		}
+	}
+}
+
+// presence lists the users viewing list, each once however many connections
+// they have open. h.mu must be held.
+func (h *Hub) presence(list string) Message {
+	users := make([]string, 0, len(h.lists[list]))
+	for c := range h.lists[list] {
+		if !slices.Contains(users, c.User) {
+			users = append(users, c.User)
+		}
+	}
+	slices.Sort(users)
+	return Message{Type: TypePresence, List: list, Users: users}
+}
@@ -0,0 +1,145 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+	"todo-app/domain"
+	"todo-app/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newHub(t *testing.T, sendBuffer int) (*Hub, *events.Broker) {
+	broker := events.NewBroker(10, 10)
+	hub := NewHub(broker, sendBuffer, 2, slog.Default())
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		hub.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+		hub.Close()
+	})
+	return hub, broker
+}
+
+func receive(t *testing.T, c *Client) Message {
+	t.Helper()
+	select {
+	case msg, ok := <-c.C:
+		require.True(t, ok, "client closed")
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("no message")
+		return Message{}
+	}
+}
+
+func TestHub_Presence(t *testing.T) {
+	hub, _ := newHub(t, 10)
+	alice := hub.Connect("alice")
+	bob := hub.Connect("bob")
+	bobAgain := hub.Connect("bob")
+
+	require.NoError(t, alice.Subscribe("home"))
+	assert.Equal(t, Message{Type: TypePresence, List: "home", Users: []string{"alice"}}, receive(t, alice))
+
+	require.NoError(t, bob.Subscribe("home"))
+	require.NoError(t, bobAgain.Subscribe("home"))
+	assert.Equal(t, []string{"alice", "bob"}, receive(t, alice).Users)
+	assert.Equal(t, []string{"alice", "bob"}, receive(t, alice).Users, "one entry per user")
+
+	bob.Close()
+	assert.Equal(t, []string{"alice", "bob"}, receive(t, alice).Users, "bob is still connected once")
+	bobAgain.Unsubscribe("home")
+	assert.Equal(t, []string{"alice"}, receive(t, alice).Users)
+}
+
+func TestHub_Subscribe_Limit(t *testing.T) {
+	hub, _ := newHub(t, 10)
+	c := hub.Connect("alice")
+
+	require.NoError(t, c.Subscribe("a"))
+	require.NoError(t, c.Subscribe("b"))
+	assert.NoError(t, c.Subscribe("a"), "resubscribing does not count")
+	assert.ErrorIs(t, c.Subscribe("c"), ErrTooManySubscriptions)
+}
+
+func TestHub_DispatchesEventsToLists(t *testing.T) {
+	hub, broker := newHub(t, 10)
+	home := hub.Connect("alice")
+	work := hub.Connect("bob")
+	require.NoError(t, home.Subscribe("home"))
+	require.NoError(t, work.Subscribe("work"))
+	receive(t, home)
+	receive(t, work)
+
+	previous := domain.Todo{Title: "Taxes", Project: "home", Status: "IN_PROGRESS"}
+	broker.Publish(context.Background(), domain.TodoEvent{Type: domain.TodoCreated, Todo: domain.Todo{Title: "Gym", Project: "gym"}})
+	broker.Publish(context.Background(), domain.TodoEvent{
+		Type:     domain.TodoUpdated,
+		Todo:     domain.Todo{Title: "Taxes", Project: "work", Status: "IN_PROGRESS"},
+		Previous: &previous,
+	})
+
+	for list, c := range map[string]*Client{"home": home, "work": work} {
+		msg := receive(t, c)
+		assert.Equal(t, TypeEvent, msg.Type)
+		assert.Equal(t, list, msg.List)
+		assert.Equal(t, "Taxes", msg.Event.Todo.Title)
+		assert.Equal(t, map[string]json.RawMessage{"project": json.RawMessage(`"work"`)}, msg.Patch)
+	}
+}
+
+func TestHub_DisconnectsSlowClients(t *testing.T) {
+	hub, broker := newHub(t, 2)
+	slow := hub.Connect("slow")
+	watcher := hub.Connect("watcher")
+	require.NoError(t, slow.Subscribe("home"))
+	require.NoError(t, watcher.Subscribe("home"))
+
+	// Two presence messages have filled slow's buffer.
+	broker.Publish(context.Background(), domain.TodoEvent{Type: domain.TodoCreated, Todo: domain.Todo{Project: "home"}})
+
+	for {
+		msg := receive(t, watcher)
+		if msg.Type == TypePresence && len(msg.Users) == 1 {
+			assert.Equal(t, []string{"watcher"}, msg.Users)
+			break
+		}
+	}
+	assert.ErrorIs(t, slow.Err(), ErrSlowClient)
+}
+
+func TestHub_Close(t *testing.T) {
+	hub, _ := newHub(t, 10)
+	c := hub.Connect("alice")
+
+	hub.Close()
+
+	_, open := <-c.C
+	assert.False(t, open)
+	assert.ErrorIs(t, c.Err(), ErrHubClosed)
+	_, open = <-hub.Connect("bob").C
+	assert.False(t, open, "no clients after close")
+}
+
+func TestMergePatch(t *testing.T) {
+	patch, err := mergePatch(
+		map[string]any{"title": "a", "tags": []string{"x"}, "project": "home"},
+		map[string]any{"title": "b", "tags": []string{"x"}},
+	)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]json.RawMessage{
+		"title":   json.RawMessage(`"b"`),
+		"project": json.RawMessage("null"),
+	}, patch)
+}
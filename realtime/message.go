@@ -0,0 +1,72 @@
+package realtime
+
+import (
+	"encoding/json"
+	"todo-app/domain"
+)
+
+// Message types of the WebSocket protocol. Clients send subscribe and
+// unsubscribe; the server sends the others.
+const (
+	TypeSubscribe   = "subscribe"
+	TypeUnsubscribe = "unsubscribe"
+	TypeEvent       = "event"
+	TypePresence    = "presence"
+	TypeReset       = "reset"
+	TypeError       = "error"
+)
+
+// Message is one JSON frame in either direction. List names the todo list,
+// that is the project, a message is about.
+//
+// An event message carries the TodoEvent and, for updates, Patch: a JSON
+// merge patch (RFC 7386) from the previous to the new state of the todo. A
+// todo moved between lists is sent to the subscribers of both. A presence
+// message lists the users viewing List. A reset means events were lost and
+// the client should reload its lists. An error message answers a client
+// message that was rejected.
+type Message struct {
+	Type    string                     `json:"type"`
+	List    string                     `json:"list,omitempty"`
+	Event   *domain.TodoEvent          `json:"event,omitempty"`
+	Patch   map[string]json.RawMessage `json:"patch,omitempty" swaggertype:"object"`
+	Users   []string                   `json:"users,omitempty"`
+	Code    string                     `json:"code,omitempty"`
+	Message string                     `json:"message,omitempty"`
+}
+
+// mergePatch returns the top-level fields of after that differ from before,
+// with null for fields before had and after lacks.
+func mergePatch(before, after any) (map[string]json.RawMessage, error) {
+	old, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	patch := make(map[string]json.RawMessage)
+	for name, value := range cur {
+		if prev, ok := old[name]; !ok || string(prev) != string(value) {
+			patch[name] = value
+		}
+	}
+	for name := range old {
+		if _, ok := cur[name]; !ok {
+			patch[name] = json.RawMessage("null")
+		}
+	}
+	return patch, nil
+}
+
+func fields(v any) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]json.RawMessage
+	err = json.Unmarshal(data, &m)
+	return m, err
+}
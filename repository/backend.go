@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"todo-app/config"
+	"todo-app/domain"
+	"todo-app/tracing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Backend bundles the repositories for the storage driver selected in the
+// configuration. DB is nil for the in-memory driver.
+type Backend struct {
+	Driver      string
+	Todos       domain.TodoRepository
+	Idempotency domain.IdempotencyRepository
+	Webhooks    domain.WebhookRepository
+	Filters     domain.SavedFilterRepository
+	Outbox      domain.OutboxRepository
+	DB          *gorm.DB
+}
+
+// Open connects to the configured storage driver, applies pool settings and
+// migrates the schema.
+func Open(cfg config.Database, logger *slog.Logger) (*Backend, error) {
+	var dialector gorm.Dialector
+	switch cfg.Driver {
+	case config.DriverMemory:
+		todos := NewMemoryTodoRepo(logger)
+		return &Backend{
+			Driver:      cfg.Driver,
+			Todos:       todos,
+			Idempotency: NewMemoryIdempotencyRepo(),
+			Webhooks:    NewMemoryWebhookRepo(),
+			Filters:     NewMemorySavedFilterRepo(),
+			Outbox:      todos.Outbox(),
+		}, nil
+	case config.DriverPostgres:
+		dialector = postgres.Open(cfg.DSN())
+	case config.DriverSQLite:
+		dialector = sqlite.Open(cfg.SQLitePath)
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("connecting to %s: %w", cfg.Driver, err)
+	}
+	if err := db.Use(tracing.GormPlugin()); err != nil {
+		return nil, fmt.Errorf("registering tracing plugin: %w", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("accessing %s pool: %w", cfg.Driver, err)
+	}
+	if cfg.Driver == config.DriverSQLite {
+		// SQLite serialises writers; a single connection avoids "database is
+		// locked" errors and keeps ":memory:" databases from splitting. That
+		// connection is never recycled, as closing it would drop a
+		// ":memory:" database along with it.
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+	} else {
+		sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+		sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+		sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	}
+
+	if err := Migrate(db); err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("migrating %s: %w", cfg.Driver, err)
+	}
+
+	return &Backend{
+		Driver:      cfg.Driver,
+		Todos:       NewTodoRepo(db, logger),
+		Idempotency: NewIdempotencyRepo(db, logger),
+		Webhooks:    NewWebhookRepo(db, logger),
+		Filters:     NewSavedFilterRepo(db, logger),
+		Outbox:      NewOutboxRepo(db, logger),
+		DB:          db,
+	}, nil
+}
+
+// models lists every table managed by the gorm repositories.
+var models = []any{
+	&domain.Todo{},
+	&domain.IdempotencyRecord{},
+	&domain.Webhook{},
+	&domain.WebhookDelivery{},
+	&domain.WebhookAttempt{},
+	&domain.OutboxEvent{},
+	&domain.SavedFilter{},
+}
+
+// Migrate creates or updates the tables used by the gorm repositories. On
+// Postgres it also sets up full-text and trigram search, which gorm cannot
+// express.
+func Migrate(db *gorm.DB) error {
+	if err := db.AutoMigrate(models...); err != nil {
+		return err
+	}
+	// Todos completed before completed_at existed take their last update as
+	// their completion time.
+	if err := db.Model(&domain.Todo{}).
+		Where("status = ? AND completed_at IS NULL", "COMPLETED").
+		UpdateColumn("completed_at", gorm.Expr("updated_at")).Error; err != nil {
+		return err
+	}
+	if db.Dialector.Name() == "postgres" {
+		if err := migrateSearch(db); err != nil {
+			return err
+		}
+		return migrateFuzzy(db)
+	}
+	return nil
+}
+
+// Ping checks that the database accepts connections. It always succeeds for
+// the in-memory driver.
+func (b *Backend) Ping(ctx context.Context) error {
+	if b.DB == nil {
+		return nil
+	}
+	sqlDB, err := b.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// MigrationStatus reports an error naming the first model whose table is
+// missing from the database.
+func (b *Backend) MigrationStatus(ctx context.Context) error {
+	if b.DB == nil {
+		return nil
+	}
+	migrator := b.DB.WithContext(ctx).Migrator()
+	for _, model := range models {
+		if !migrator.HasTable(model) {
+			stmt := &gorm.Statement{DB: b.DB}
+			if err := stmt.Parse(model); err != nil {
+				return err
+			}
+			return fmt.Errorf("table %s has not been migrated", stmt.Schema.Table)
+		}
+	}
+	return nil
+}
+
+// Close releases the database connection pool, if any.
+func (b *Backend) Close() error {
+	if b.DB == nil {
+		return nil
+	}
+	sqlDB, err := b.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
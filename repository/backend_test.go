@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+	"todo-app/config"
+	"todo-app/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpen(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		backend, err := Open(config.Database{Driver: config.DriverMemory}, slog.Default())
+
+		require.NoError(t, err)
+		assert.IsType(t, &MemoryTodoRepo{}, backend.Todos)
+		assert.Nil(t, backend.DB)
+		assert.NoError(t, backend.Close())
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		cfg := config.Database{
+			Driver:     config.DriverSQLite,
+			SQLitePath: filepath.Join(t.TempDir(), "todo.db"),
+		}
+
+		backend, err := Open(cfg, slog.Default())
+
+		require.NoError(t, err)
+		assert.IsType(t, &TodoRepo{}, backend.Todos)
+		assert.True(t, backend.DB.Migrator().HasTable("todos"))
+		assert.NoError(t, backend.Close())
+	})
+
+	t.Run("sqlite in memory keeps its connection", func(t *testing.T) {
+		cfg := config.Database{
+			Driver:          config.DriverSQLite,
+			SQLitePath:      ":memory:",
+			ConnMaxLifetime: time.Millisecond,
+		}
+		backend, err := Open(cfg, slog.Default())
+		require.NoError(t, err)
+		defer backend.Close()
+		ctx := context.Background()
+		require.NoError(t, backend.Todos.Create(ctx, &domain.Todo{Title: "Buy milk", Status: "IN_PROGRESS"}))
+
+		time.Sleep(10 * time.Millisecond)
+
+		todos, err := backend.Todos.FindAll(ctx)
+		require.NoError(t, err)
+		assert.Len(t, todos, 1, "the database outlives conn_max_lifetime")
+	})
+
+	t.Run("unsupported driver", func(t *testing.T) {
+		_, err := Open(config.Database{Driver: "oracle"}, slog.Default())
+
+		assert.ErrorContains(t, err, "oracle")
+	})
+}
+
+func TestMigrate_BackfillsCompletedAt(t *testing.T) {
+	cfg := config.Database{Driver: config.DriverSQLite, SQLitePath: filepath.Join(t.TempDir(), "todo.db")}
+	backend, err := Open(cfg, slog.Default())
+	require.NoError(t, err)
+	defer backend.Close()
+	ctx := context.Background()
+	done := &domain.Todo{Title: "Ship release", Status: "COMPLETED"}
+	open := &domain.Todo{Title: "Write notes", Status: "IN_PROGRESS"}
+	for _, todo := range []*domain.Todo{done, open} {
+		require.NoError(t, backend.Todos.Create(ctx, todo))
+	}
+
+	require.NoError(t, Migrate(backend.DB))
+
+	stored, err := backend.Todos.FindByID(ctx, done.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.CompletedAt)
+	assert.True(t, stored.UpdatedAt.Equal(*stored.CompletedAt))
+	stored, err = backend.Todos.FindByID(ctx, open.ID)
+	require.NoError(t, err)
+	assert.Nil(t, stored.CompletedAt)
+}
+
+func TestBackend_HealthChecks(t *testing.T) {
+	cfg := config.Database{
+		Driver:     config.DriverSQLite,
+		SQLitePath: filepath.Join(t.TempDir(), "todo.db"),
+	}
+	backend, err := Open(cfg, slog.Default())
+	require.NoError(t, err)
+	defer backend.Close()
+
+	assert.NoError(t, backend.Ping(context.Background()))
+	assert.NoError(t, backend.MigrationStatus(context.Background()))
+
+	require.NoError(t, backend.DB.Migrator().DropTable("todos"))
+	assert.ErrorContains(t, backend.MigrationStatus(context.Background()), "todos")
+
+	require.NoError(t, backend.Close())
+	assert.Error(t, backend.Ping(context.Background()))
+}
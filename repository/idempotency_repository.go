@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"todo-app/domain"
+	"todo-app/logging"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IdempotencyRepo struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewIdempotencyRepo(db *gorm.DB, logger *slog.Logger) *IdempotencyRepo {
+	return &IdempotencyRepo{db: db, logger: logger}
+}
+
+func (r *IdempotencyRepo) log(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx, r.logger)
+}
+
+func (r *IdempotencyRepo) Reserve(ctx context.Context, rec *domain.IdempotencyRecord) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("scope = ? AND idempotency_key = ? AND expires_at <= ?", rec.Scope, rec.Key, time.Now()).
+			Delete(&domain.IdempotencyRecord{}).Error
+		if err != nil {
+			return r.fail(ctx, "Failed to purge expired idempotency key", err)
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(rec)
+		if result.Error != nil {
+			return r.fail(ctx, "Failed to reserve idempotency key", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrIdempotencyKeyExists
+		}
+		return nil
+	})
+}
+
+func (r *IdempotencyRepo) Find(ctx context.Context, scope, key string) (*domain.IdempotencyRecord, error) {
+	var rec domain.IdempotencyRecord
+	err := r.db.WithContext(ctx).
+		Where("scope = ? AND idempotency_key = ? AND expires_at > ?", scope, key, time.Now()).
+		First(&rec).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, r.fail(ctx, "Failed to find idempotency key", err)
+	}
+	return &rec, nil
+}
+
+func (r *IdempotencyRepo) Complete(ctx context.Context, rec *domain.IdempotencyRecord) error {
+	err := r.db.WithContext(ctx).Model(&domain.IdempotencyRecord{}).
+		Where("scope = ? AND idempotency_key = ?", rec.Scope, rec.Key).
+		Updates(map[string]any{"status": rec.Status, "content_type": rec.ContentType, "body": rec.Body}).Error
+	if err != nil {
+		return r.fail(ctx, "Failed to store idempotent response", err)
+	}
+	return nil
+}
+
+func (r *IdempotencyRepo) Release(ctx context.Context, scope, key string) error {
+	err := r.db.WithContext(ctx).
+		Where("scope = ? AND idempotency_key = ?", scope, key).
+		Delete(&domain.IdempotencyRecord{}).Error
+	if err != nil {
+		return r.fail(ctx, "Failed to release idempotency key", err)
+	}
+	return nil
+}
+
+func (r *IdempotencyRepo) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&domain.IdempotencyRecord{})
+	if result.Error != nil {
+		return 0, r.fail(ctx, "Failed to delete expired idempotency keys", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+func (r *IdempotencyRepo) fail(ctx context.Context, msg string, err error) error {
+	r.log(ctx).ErrorContext(ctx, msg, "error", err)
+	return fmt.Errorf("%w: %v", domain.ErrDatabaseOperation, err)
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+	"todo-app/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyRepositories(t *testing.T) {
+	stores := map[string]func(t *testing.T) domain.IdempotencyRepository{
+		"gorm": func(t *testing.T) domain.IdempotencyRepository {
+			return NewIdempotencyRepo(setupTestDB(t), slog.Default())
+		},
+		"memory": func(t *testing.T) domain.IdempotencyRepository { return NewMemoryIdempotencyRepo() },
+	}
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newStore(t)
+			rec := func(key string, ttl time.Duration) *domain.IdempotencyRecord {
+				return &domain.IdempotencyRecord{Scope: "alice", Key: key, Fingerprint: "fp", ExpiresAt: time.Now().Add(ttl)}
+			}
+
+			require.NoError(t, store.Reserve(ctx, rec("k1", time.Hour)))
+			assert.ErrorIs(t, store.Reserve(ctx, rec("k1", time.Hour)), domain.ErrIdempotencyKeyExists)
+			assert.NoError(t, store.Reserve(ctx, &domain.IdempotencyRecord{Scope: "bob", Key: "k1", Fingerprint: "fp", ExpiresAt: time.Now().Add(time.Hour)}),
+				"keys are scoped per caller")
+
+			reserved, err := store.Find(ctx, "alice", "k1")
+			require.NoError(t, err)
+			assert.False(t, reserved.Completed())
+
+			done := rec("k1", time.Hour)
+			done.Status, done.ContentType, done.Body = 201, "application/json", []byte(`{"id":1}`)
+			require.NoError(t, store.Complete(ctx, done))
+			found, err := store.Find(ctx, "alice", "k1")
+			require.NoError(t, err)
+			assert.Equal(t, 201, found.Status)
+			assert.Equal(t, "application/json", found.ContentType)
+			assert.Equal(t, []byte(`{"id":1}`), found.Body)
+			assert.Equal(t, "fp", found.Fingerprint)
+
+			require.NoError(t, store.Release(ctx, "alice", "k1"))
+			_, err = store.Find(ctx, "alice", "k1")
+			assert.ErrorIs(t, err, domain.ErrNotFound)
+
+			require.NoError(t, store.Reserve(ctx, rec("expired", -time.Second)))
+			_, err = store.Find(ctx, "alice", "expired")
+			assert.ErrorIs(t, err, domain.ErrNotFound, "expired records are not returned")
+			assert.NoError(t, store.Reserve(ctx, rec("expired", time.Hour)), "expired records are replaced")
+
+			require.NoError(t, store.Reserve(ctx, rec("stale", -time.Second)))
+			n, err := store.DeleteExpired(ctx, time.Now())
+			require.NoError(t, err)
+			assert.EqualValues(t, 1, n)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+	"todo-app/domain"
+)
+
+// MemoryIdempotencyRepo is the in-memory counterpart of IdempotencyRepo.
+type MemoryIdempotencyRepo struct {
+	mu      sync.Mutex
+	records map[[2]string]domain.IdempotencyRecord
+}
+
+func NewMemoryIdempotencyRepo() *MemoryIdempotencyRepo {
+	return &MemoryIdempotencyRepo{records: make(map[[2]string]domain.IdempotencyRecord)}
+}
+
+func (r *MemoryIdempotencyRepo) Reserve(ctx context.Context, rec *domain.IdempotencyRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := [2]string{rec.Scope, rec.Key}
+	if existing, ok := r.records[k]; ok && existing.ExpiresAt.After(time.Now()) {
+		return domain.ErrIdempotencyKeyExists
+	}
+	if rec.CreatedAt.IsZero() {
+		rec.CreatedAt = time.Now()
+	}
+	r.records[k] = *rec
+	return nil
+}
+
+func (r *MemoryIdempotencyRepo) Find(ctx context.Context, scope, key string) (*domain.IdempotencyRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rec, ok := r.records[[2]string{scope, key}]
+	if !ok || !rec.ExpiresAt.After(time.Now()) {
+		return nil, domain.ErrNotFound
+	}
+	return &rec, nil
+}
+
+func (r *MemoryIdempotencyRepo) Complete(ctx context.Context, rec *domain.IdempotencyRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := [2]string{rec.Scope, rec.Key}
+	stored, ok := r.records[k]
+	if !ok {
+		return nil
+	}
+	stored.Status, stored.ContentType, stored.Body = rec.Status, rec.ContentType, rec.Body
+	r.records[k] = stored
+	return nil
+}
+
+func (r *MemoryIdempotencyRepo) Release(ctx context.Context, scope, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.records, [2]string{scope, key})
+	return nil
+}
+
+func (r *MemoryIdempotencyRepo) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var n int64
+	for k, rec := range r.records {
+		if !rec.ExpiresAt.After(now) {
+			delete(r.records, k)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+	"todo-app/domain"
+)
+
+// MemoryOutboxRepo is the in-memory counterpart of OutboxRepo. Events are
+// added by the MemoryTodoRepo it belongs to.
+type MemoryOutboxRepo struct {
+	mu     sync.Mutex
+	events []domain.OutboxEvent
+	nextID uint64
+}
+
+func NewMemoryOutboxRepo() *MemoryOutboxRepo {
+	return &MemoryOutboxRepo{}
+}
+
+func (r *MemoryOutboxRepo) add(events ...domain.OutboxEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, event := range events {
+		r.nextID++
+		event.ID, event.CreatedAt = r.nextID, time.Now()
+		r.events = append(r.events, event)
+	}
+}
+
+func (r *MemoryOutboxRepo) ClaimPending(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.OutboxEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var claimed []domain.OutboxEvent
+	for i := range r.events {
+		if len(claimed) == limit {
+			break
+		}
+		e := &r.events[i]
+		if e.DeliveredAt == nil && !e.NextAttemptAt.After(now) {
+			claimed = append(claimed, *e)
+			e.NextAttemptAt = now.Add(lease)
+		}
+	}
+	return claimed, nil
+}
+
+func (r *MemoryOutboxRepo) MarkDelivered(ctx context.Context, ids []uint64, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.events {
+		if slices.Contains(ids, r.events[i].ID) {
+			r.events[i].DeliveredAt, r.events[i].LastError = &at, ""
+		}
+	}
+	return nil
+}
+
+func (r *MemoryOutboxRepo) Retry(ctx context.Context, id uint64, next time.Time, lastError string, deliveredTo []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.events {
+		if e := &r.events[i]; e.ID == id {
+			e.Attempts++
+			e.NextAttemptAt, e.LastError = next, lastError
+			e.DeliveredTo = slices.Clone(deliveredTo)
+		}
+	}
+	return nil
+}
+
+func (r *MemoryOutboxRepo) DeleteDelivered(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := len(r.events)
+	r.events = slices.DeleteFunc(r.events, func(e domain.OutboxEvent) bool {
+		return e.DeliveredAt != nil && e.DeliveredAt.Before(before)
+	})
+	return int64(n - len(r.events)), nil
+}
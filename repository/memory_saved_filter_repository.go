@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+	"todo-app/domain"
+
+	"github.com/google/uuid"
+)
+
+// MemorySavedFilterRepo is the in-memory counterpart of SavedFilterRepo.
+type MemorySavedFilterRepo struct {
+	mu      sync.Mutex
+	filters map[uuid.UUID]domain.SavedFilter
+}
+
+func NewMemorySavedFilterRepo() *MemorySavedFilterRepo {
+	return &MemorySavedFilterRepo{filters: make(map[uuid.UUID]domain.SavedFilter)}
+}
+
+func (r *MemorySavedFilterRepo) Create(ctx context.Context, filter *domain.SavedFilter) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if filter.ID == uuid.Nil {
+		filter.ID = uuid.New()
+	}
+	if _, exists := r.filters[filter.ID]; exists {
+		return domain.ErrDatabaseOperation
+	}
+	now := time.Now()
+	filter.CreatedAt, filter.UpdatedAt = now, now
+	r.filters[filter.ID] = *filter
+	return nil
+}
+
+func (r *MemorySavedFilterRepo) Update(ctx context.Context, filter *domain.SavedFilter) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	filter.UpdatedAt = time.Now()
+	r.filters[filter.ID] = *filter
+	return nil
+}
+
+func (r *MemorySavedFilterRepo) FindByID(ctx context.Context, id uuid.UUID) (*domain.SavedFilter, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	filter, ok := r.filters[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &filter, nil
+}
+
+func (r *MemorySavedFilterRepo) FindByOwner(ctx context.Context, owner string) ([]domain.SavedFilter, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	filters := []domain.SavedFilter{}
+	for _, filter := range r.filters {
+		if filter.Owner == owner {
+			filters = append(filters, filter)
+		}
+	}
+	slices.SortFunc(filters, func(a, b domain.SavedFilter) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return filters, nil
+}
+
+func (r *MemorySavedFilterRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.filters[id]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.filters, id)
+	return nil
+}
@@ -0,0 +1,321 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+	"todo-app/domain"
+	"todo-app/logging"
+	"todo-app/search"
+
+	"github.com/google/uuid"
+)
+
+// MemoryTodoRepo keeps todos in process memory. It is meant for demos and fast
+// tests and mirrors the observable behaviour of TodoRepo: IDs and timestamps
+// are assigned on write, FindAll returns todos in insertion order and every
+// write records an event in its outbox.
+type MemoryTodoRepo struct {
+	mu     sync.RWMutex
+	todos  map[uuid.UUID]domain.Todo
+	order  []uuid.UUID
+	outbox *MemoryOutboxRepo
+	// staged collects the outbox events of a transaction until it commits.
+	// It is nil outside of Transaction.
+	staged *[]domain.OutboxEvent
+	logger *slog.Logger
+}
+
+func NewMemoryTodoRepo(logger *slog.Logger) *MemoryTodoRepo {
+	return &MemoryTodoRepo{
+		todos:  make(map[uuid.UUID]domain.Todo),
+		outbox: NewMemoryOutboxRepo(),
+		logger: logger,
+	}
+}
+
+// Outbox returns the outbox the repository records its events in.
+func (r *MemoryTodoRepo) Outbox() *MemoryOutboxRepo {
+	return r.outbox
+}
+
+func (r *MemoryTodoRepo) record(events ...domain.OutboxEvent) {
+	if r.staged != nil {
+		*r.staged = append(*r.staged, events...)
+		return
+	}
+	r.outbox.add(events...)
+}
+
+// log returns the request-scoped logger from ctx, falling back to the
+// repository's own logger outside of a request.
+func (r *MemoryTodoRepo) log(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx, r.logger)
+}
+
+func (r *MemoryTodoRepo) Create(ctx context.Context, todo *domain.Todo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if todo.ID == uuid.Nil {
+		todo.ID = uuid.New()
+	}
+	if _, exists := r.todos[todo.ID]; exists {
+		r.log(ctx).ErrorContext(ctx, "Failed to create todo", "error", "duplicate id", "todo_id", todo.ID)
+		return domain.ErrDatabaseOperation
+	}
+	now := time.Now()
+	if todo.CreatedAt.IsZero() {
+		todo.CreatedAt = now
+	}
+	if todo.UpdatedAt.IsZero() {
+		todo.UpdatedAt = now
+	}
+	r.todos[todo.ID] = cloneTodo(*todo)
+	r.order = append(r.order, todo.ID)
+	r.record(*newOutboxEvent(domain.TodoCreated, todo, nil))
+	r.log(ctx).InfoContext(ctx, "Todo created", "todo_id", todo.ID)
+	return nil
+}
+
+func (r *MemoryTodoRepo) CreateBatch(ctx context.Context, todos []domain.Todo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	seen := make(map[uuid.UUID]bool, len(todos))
+	for i := range todos {
+		todo := &todos[i]
+		if todo.ID == uuid.Nil {
+			todo.ID = uuid.New()
+		}
+		if _, exists := r.todos[todo.ID]; exists || seen[todo.ID] {
+			r.log(ctx).ErrorContext(ctx, "Failed to create todos", "error", "duplicate id", "todo_id", todo.ID)
+			return domain.ErrDatabaseOperation
+		}
+		seen[todo.ID] = true
+	}
+	now := time.Now()
+	for i := range todos {
+		todo := &todos[i]
+		if todo.CreatedAt.IsZero() {
+			todo.CreatedAt = now
+		}
+		if todo.UpdatedAt.IsZero() {
+			todo.UpdatedAt = now
+		}
+		r.todos[todo.ID] = cloneTodo(*todo)
+		r.order = append(r.order, todo.ID)
+		r.record(*newOutboxEvent(domain.TodoCreated, todo, nil))
+	}
+	r.log(ctx).InfoContext(ctx, "Todos created", "count", len(todos))
+	return nil
+}
+
+func (r *MemoryTodoRepo) Update(ctx context.Context, todo *domain.Todo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if todo.ID == uuid.Nil {
+		todo.ID = uuid.New()
+	}
+	now := time.Now()
+	if todo.CreatedAt.IsZero() {
+		todo.CreatedAt = now
+	}
+	todo.UpdatedAt = now
+	var previous *domain.Todo
+	if existing, exists := r.todos[todo.ID]; exists {
+		previous = &existing
+	} else {
+		r.order = append(r.order, todo.ID)
+	}
+	r.todos[todo.ID] = cloneTodo(*todo)
+	r.record(*newOutboxEvent(domain.TodoUpdated, todo, previous))
+	r.log(ctx).InfoContext(ctx, "Todo updated", "todo_id", todo.ID)
+	return nil
+}
+
+func (r *MemoryTodoRepo) FindAll(ctx context.Context) ([]domain.Todo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	todos := make([]domain.Todo, 0, len(r.order))
+	for _, id := range r.order {
+		todos = append(todos, cloneTodo(r.todos[id]))
+	}
+	r.log(ctx).InfoContext(ctx, "Todos retrieved", "count", len(todos))
+	return todos, nil
+}
+
+func (r *MemoryTodoRepo) FindByID(ctx context.Context, id uuid.UUID) (*domain.Todo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	todo, ok := r.todos[id]
+	if !ok {
+		r.log(ctx).WarnContext(ctx, "Todo not found", "todo_id", id)
+		return nil, domain.ErrNotFound
+	}
+	r.log(ctx).InfoContext(ctx, "Todo retrieved", "todo_id", id)
+	todo = cloneTodo(todo)
+	return &todo, nil
+}
+
+func (r *MemoryTodoRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.todos[id]
+	if !ok {
+		r.log(ctx).WarnContext(ctx, "Todo not found for deletion", "todo_id", id)
+		return domain.ErrNotFound
+	}
+	delete(r.todos, id)
+	for i, existing := range r.order {
+		if existing == id {
+			r.order = append(r.order[:i], r.order[i+1:]...)
+			break
+		}
+	}
+	r.record(*newOutboxEvent(domain.TodoDeleted, &existing, nil))
+	r.log(ctx).InfoContext(ctx, "Todo deleted", "todo_id", id)
+	return nil
+}
+
+func (r *MemoryTodoRepo) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[string]int64)
+	for _, todo := range r.todos {
+		counts[todo.Status]++
+	}
+	return counts, nil
+}
+
+// FindByFilter evaluates filter in Go.
+func (r *MemoryTodoRepo) FindByFilter(ctx context.Context, filter domain.Filter) ([]domain.Todo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	todos := []domain.Todo{}
+	for _, id := range r.order {
+		todo := r.todos[id]
+		if matchFilter(filter, &todo) {
+			todos = append(todos, cloneTodo(todo))
+		}
+	}
+	r.log(ctx).InfoContext(ctx, "Todos filtered", "count", len(todos))
+	return todos, nil
+}
+
+// Stream snapshots the matching todos and calls fn without holding the
+// lock, so a slow consumer does not block writers.
+func (r *MemoryTodoRepo) Stream(ctx context.Context, filter domain.Filter, sortBy string, fn func(domain.Todo) error) error {
+	r.mu.RLock()
+	var todos []domain.Todo
+	for _, id := range r.order {
+		if todo := r.todos[id]; filter == nil || matchFilter(filter, &todo) {
+			todos = append(todos, cloneTodo(todo))
+		}
+	}
+	r.mu.RUnlock()
+
+	orderTodos(todos, sortBy)
+	for _, todo := range todos {
+		if err := fn(todo); err != nil {
+			return err
+		}
+	}
+	r.log(ctx).InfoContext(ctx, "Todos streamed", "count", len(todos))
+	return nil
+}
+
+// Facets counts in Go the todos matching filter.
+func (r *MemoryTodoRepo) Facets(ctx context.Context, filter domain.Filter) (*domain.Facets, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var todos []domain.Todo
+	for _, id := range r.order {
+		if todo := r.todos[id]; filter == nil || matchFilter(filter, &todo) {
+			todos = append(todos, todo)
+		}
+	}
+	return countFacets(todos), nil
+}
+
+// Search matches, ranks and highlights todos in Go, like TodoRepo does on
+// databases without full-text search.
+func (r *MemoryTodoRepo) Search(ctx context.Context, query string, limit int) ([]domain.SearchResult, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	todos := make([]domain.Todo, 0, len(r.order))
+	for _, id := range r.order {
+		todos = append(todos, cloneTodo(r.todos[id]))
+	}
+	results := rankMatches(search.Parse(query), todos, limit)
+	r.log(ctx).InfoContext(ctx, "Todos searched", "count", len(results))
+	return results, nil
+}
+
+// FuzzySearch scores every title in Go, like TodoRepo does on databases
+// without pg_trgm.
+func (r *MemoryTodoRepo) FuzzySearch(ctx context.Context, query string, threshold float64, limit int) ([]domain.FuzzyMatch, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	todos := make([]domain.Todo, 0, len(r.order))
+	for _, id := range r.order {
+		todos = append(todos, cloneTodo(r.todos[id]))
+	}
+	matches := scoreFuzzy(query, todos, threshold, limit)
+	r.log(ctx).InfoContext(ctx, "Todos fuzzy searched", "count", len(matches))
+	return matches, nil
+}
+
+// Transaction runs fn against a copy of the store and swaps the copy in on
+// success, together with the outbox events fn caused. The store stays locked
+// for the duration, so transactions are serialized with every other
+// operation.
+func (r *MemoryTodoRepo) Transaction(ctx context.Context, fn func(tx domain.TodoRepository) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tx := &MemoryTodoRepo{
+		todos:  make(map[uuid.UUID]domain.Todo, len(r.todos)),
+		order:  append([]uuid.UUID(nil), r.order...),
+		outbox: r.outbox,
+		staged: new([]domain.OutboxEvent),
+		logger: r.logger,
+	}
+	for id, todo := range r.todos {
+		tx.todos[id] = todo
+	}
+	if err := fn(tx); err != nil {
+		r.log(ctx).WarnContext(ctx, "Transaction rolled back", "error", err)
+		return err
+	}
+	r.todos, r.order = tx.todos, tx.order
+	r.record(*tx.staged...)
+	return nil
+}
+
+// cloneTodo copies the slices and pointers of todo so callers never share them with the
+// store.
+func cloneTodo(todo domain.Todo) domain.Todo {
+	if todo.Tags != nil {
+		todo.Tags = append([]string(nil), todo.Tags...)
+	}
+	if todo.DueDate != nil {
+		due := *todo.DueDate
+		todo.DueDate = &due
+	}
+	if todo.CompletedAt != nil {
+		completed := *todo.CompletedAt
+		todo.CompletedAt = &completed
+	}
+	return todo
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"log/slog"
+	"testing"
+	"todo-app/domain"
+	"todo-app/repository/repositorytest"
+)
+
+func TestMemoryTodoRepository_Conformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) domain.TodoRepository {
+		return NewMemoryTodoRepo(slog.Default())
+	})
+}
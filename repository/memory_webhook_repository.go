@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+	"todo-app/domain"
+
+	"github.com/google/uuid"
+)
+
+// MemoryWebhookRepo is the in-memory counterpart of WebhookRepo.
+type MemoryWebhookRepo struct {
+	mu         sync.Mutex
+	webhooks   map[uuid.UUID]domain.Webhook
+	deliveries map[uuid.UUID]domain.WebhookDelivery
+	attempts   map[uuid.UUID][]domain.WebhookAttempt
+	nextID     uint
+}
+
+func NewMemoryWebhookRepo() *MemoryWebhookRepo {
+	return &MemoryWebhookRepo{
+		webhooks:   make(map[uuid.UUID]domain.Webhook),
+		deliveries: make(map[uuid.UUID]domain.WebhookDelivery),
+		attempts:   make(map[uuid.UUID][]domain.WebhookAttempt),
+	}
+}
+
+func (r *MemoryWebhookRepo) Create(ctx context.Context, webhook *domain.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if webhook.ID == uuid.Nil {
+		webhook.ID = uuid.New()
+	}
+	if _, exists := r.webhooks[webhook.ID]; exists {
+		return domain.ErrDatabaseOperation
+	}
+	now := time.Now()
+	webhook.CreatedAt, webhook.UpdatedAt = now, now
+	r.webhooks[webhook.ID] = cloneWebhook(*webhook)
+	return nil
+}
+
+func (r *MemoryWebhookRepo) Update(ctx context.Context, webhook *domain.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	webhook.UpdatedAt = time.Now()
+	r.webhooks[webhook.ID] = cloneWebhook(*webhook)
+	return nil
+}
+
+func (r *MemoryWebhookRepo) FindByID(ctx context.Context, id uuid.UUID) (*domain.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	webhook, ok := r.webhooks[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	webhook = cloneWebhook(webhook)
+	return &webhook, nil
+}
+
+func (r *MemoryWebhookRepo) FindAll(ctx context.Context) ([]domain.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	webhooks := make([]domain.Webhook, 0, len(r.webhooks))
+	for _, webhook := range r.webhooks {
+		webhooks = append(webhooks, cloneWebhook(webhook))
+	}
+	slices.SortFunc(webhooks, func(a, b domain.Webhook) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return webhooks, nil
+}
+
+func (r *MemoryWebhookRepo) FindByOwner(ctx context.Context, owner string) ([]domain.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	webhooks := []domain.Webhook{}
+	for _, webhook := range r.webhooks {
+		if webhook.Owner == owner {
+			webhooks = append(webhooks, cloneWebhook(webhook))
+		}
+	}
+	slices.SortFunc(webhooks, func(a, b domain.Webhook) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return webhooks, nil
+}
+
+func (r *MemoryWebhookRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.webhooks[id]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.webhooks, id)
+	for deliveryID, delivery := range r.deliveries {
+		if delivery.WebhookID == id {
+			delete(r.deliveries, deliveryID)
+			delete(r.attempts, deliveryID)
+		}
+	}
+	return nil
+}
+
+func (r *MemoryWebhookRepo) Enqueue(ctx context.Context, deliveries []domain.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for i := range deliveries {
+		d := &deliveries[i]
+		if r.queued(d.WebhookID, d.EventID) {
+			continue
+		}
+		if d.ID == uuid.Nil {
+			d.ID = uuid.New()
+		}
+		d.CreatedAt, d.UpdatedAt = now, now
+		r.deliveries[d.ID] = *d
+	}
+	return nil
+}
+
+func (r *MemoryWebhookRepo) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due []domain.WebhookDelivery
+	for _, d := range r.deliveries {
+		if d.Status == domain.DeliveryPending && !d.NextAttemptAt.After(now) && r.webhooks[d.WebhookID].Enabled {
+			due = append(due, d)
+		}
+	}
+	slices.SortFunc(due, func(a, b domain.WebhookDelivery) int { return a.NextAttemptAt.Compare(b.NextAttemptAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	for _, d := range due {
+		d.NextAttemptAt = now.Add(lease)
+		r.deliveries[d.ID] = d
+	}
+	return due, nil
+}
+
+func (r *MemoryWebhookRepo) RecordAttempt(ctx context.Context, delivery *domain.WebhookDelivery, attempt *domain.WebhookAttempt, disableAfter int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	attempt.ID, attempt.DeliveryID = r.nextID, delivery.ID
+	r.attempts[delivery.ID] = append(r.attempts[delivery.ID], *attempt)
+
+	delivery.UpdatedAt = time.Now()
+	stored := *delivery
+	stored.Attempts = nil
+	r.deliveries[delivery.ID] = stored
+
+	webhook, ok := r.webhooks[delivery.WebhookID]
+	if !ok {
+		return false, nil
+	}
+	disabled := false
+	if attempt.Succeeded() {
+		webhook.ConsecutiveFailures = 0
+	} else {
+		webhook.ConsecutiveFailures++
+		if webhook.Enabled && webhook.ConsecutiveFailures >= disableAfter {
+			disabledAt := attempt.AttemptedAt
+			webhook.Enabled, webhook.DisabledAt = false, &disabledAt
+			disabled = true
+		}
+	}
+	r.webhooks[webhook.ID] = webhook
+	return disabled, nil
+}
+
+func (r *MemoryWebhookRepo) Deliveries(ctx context.Context, webhookID uuid.UUID, limit int) ([]domain.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deliveries []domain.WebhookDelivery
+	for _, d := range r.deliveries {
+		if d.WebhookID == webhookID {
+			d.Attempts = slices.Clone(r.attempts[d.ID])
+			deliveries = append(deliveries, d)
+		}
+	}
+	slices.SortFunc(deliveries, func(a, b domain.WebhookDelivery) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+func (r *MemoryWebhookRepo) queued(webhookID uuid.UUID, eventID string) bool {
+	for _, d := range r.deliveries {
+		if d.WebhookID == webhookID && d.EventID == eventID {
+			return true
+		}
+	}
+	return false
+}
+
+func cloneWebhook(webhook domain.Webhook) domain.Webhook {
+	webhook.EventTypes = slices.Clone(webhook.EventTypes)
+	if webhook.DisabledAt != nil {
+		disabledAt := *webhook.DisabledAt
+		webhook.DisabledAt = &disabledAt
+	}
+	return webhook
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+	"todo-app/domain"
+	"todo-app/logging"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OutboxRepo struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewOutboxRepo(db *gorm.DB, logger *slog.Logger) *OutboxRepo {
+	return &OutboxRepo{db: db, logger: logger}
+}
+
+func (r *OutboxRepo) log(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx, r.logger)
+}
+
+func (r *OutboxRepo) ClaimPending(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.OutboxEvent, error) {
+	var events []domain.OutboxEvent
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("delivered_at IS NULL AND next_attempt_at <= ?", now).
+			Order("id").
+			Limit(limit)
+		// As for webhook deliveries, only Postgres needs row locks to keep
+		// relays on other replicas from claiming the same events.
+		if tx.Dialector.Name() == "postgres" {
+			query = query.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+		}
+		if err := query.Find(&events).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		ids := make([]uint64, len(events))
+		for i := range events {
+			ids[i] = events[i].ID
+		}
+		return tx.Model(&domain.OutboxEvent{}).Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, r.fail(ctx, "Failed to claim outbox events", err)
+	}
+	return events, nil
+}
+
+func (r *OutboxRepo) MarkDelivered(ctx context.Context, ids []uint64, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	err := r.db.WithContext(ctx).Model(&domain.OutboxEvent{}).Where("id IN ?", ids).
+		Updates(map[string]any{"delivered_at": at, "last_error": ""}).Error
+	if err != nil {
+		return r.fail(ctx, "Failed to mark outbox events delivered", err)
+	}
+	return nil
+}
+
+func (r *OutboxRepo) Retry(ctx context.Context, id uint64, next time.Time, lastError string, deliveredTo []string) error {
+	// Map updates bypass the column's JSON serializer.
+	sinks, err := json.Marshal(deliveredTo)
+	if err != nil {
+		return r.fail(ctx, "Failed to encode outbox sinks", err)
+	}
+	err = r.db.WithContext(ctx).Model(&domain.OutboxEvent{}).Where("id = ?", id).
+		Updates(map[string]any{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": next,
+			"last_error":      lastError,
+			"delivered_to":    string(sinks),
+		}).Error
+	if err != nil {
+		return r.fail(ctx, "Failed to reschedule outbox event", err)
+	}
+	return nil
+}
+
+func (r *OutboxRepo) DeleteDelivered(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("delivered_at < ?", before).Delete(&domain.OutboxEvent{})
+	if result.Error != nil {
+		return 0, r.fail(ctx, "Failed to delete delivered outbox events", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+func (r *OutboxRepo) fail(ctx context.Context, msg string, err error) error {
+	r.log(ctx).ErrorContext(ctx, msg, "error", err)
+	return fmt.Errorf("%w: %v", domain.ErrDatabaseOperation, err)
+}
+
+// newOutboxEvent records a change to todo for the relay. previous is the
+// state an update replaced.
+func newOutboxEvent(typ domain.TodoEventType, todo, previous *domain.Todo) *domain.OutboxEvent {
+	now := time.Now().UTC()
+	event := domain.TodoEvent{Type: typ, Todo: cloneTodo(*todo), OccurredAt: now}
+	if previous != nil {
+		p := cloneTodo(*previous)
+		event.Previous = &p
+	}
+	return &domain.OutboxEvent{Event: event, NextAttemptAt: now}
+}
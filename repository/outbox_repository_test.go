@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+	"todo-app/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxRepositories(t *testing.T) {
+	stores := map[string]func(t *testing.T) (domain.TodoRepository, domain.OutboxRepository){
+		"gorm": func(t *testing.T) (domain.TodoRepository, domain.OutboxRepository) {
+			db := setupTestDB(t)
+			return NewTodoRepo(db, slog.Default()), NewOutboxRepo(db, slog.Default())
+		},
+		"memory": func(t *testing.T) (domain.TodoRepository, domain.OutboxRepository) {
+			todos := NewMemoryTodoRepo(slog.Default())
+			return todos, todos.Outbox()
+		},
+	}
+	for name, newStores := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			todos, outbox := newStores(t)
+			now := time.Now().UTC().Add(time.Second)
+
+			todo := &domain.Todo{Title: "Buy milk", Status: "IN_PROGRESS", Project: "home"}
+			require.NoError(t, todos.Create(ctx, todo))
+			renamed := *todo
+			renamed.Title = "Buy oat milk"
+			require.NoError(t, todos.Update(ctx, &renamed))
+			require.NoError(t, todos.Delete(ctx, todo.ID))
+			assert.ErrorIs(t, todos.Delete(ctx, todo.ID), domain.ErrNotFound)
+
+			rolledBack := errors.New("rolled back")
+			err := todos.Transaction(ctx, func(tx domain.TodoRepository) error {
+				require.NoError(t, tx.Create(ctx, &domain.Todo{Title: "Never", Status: "IN_PROGRESS"}))
+				return rolledBack
+			})
+			require.ErrorIs(t, err, rolledBack)
+			require.NoError(t, todos.Transaction(ctx, func(tx domain.TodoRepository) error {
+				return tx.Create(ctx, &domain.Todo{Title: "Committed", Status: "IN_PROGRESS"})
+			}))
+
+			pending, err := outbox.ClaimPending(ctx, now, time.Minute, 10)
+			require.NoError(t, err)
+			require.Len(t, pending, 4, "failed and rolled back writes record nothing")
+			events := make([]domain.TodoEvent, len(pending))
+			for i := range pending {
+				events[i] = pending[i].TodoEvent()
+			}
+			assert.Equal(t, []domain.TodoEventType{domain.TodoCreated, domain.TodoUpdated, domain.TodoDeleted, domain.TodoCreated},
+				[]domain.TodoEventType{events[0].Type, events[1].Type, events[2].Type, events[3].Type})
+			assert.NotEqual(t, events[0].ID, events[1].ID)
+			assert.Equal(t, "Buy milk", events[0].Todo.Title)
+			assert.Equal(t, "Buy oat milk", events[1].Todo.Title)
+			require.NotNil(t, events[1].Previous)
+			assert.Equal(t, "Buy milk", events[1].Previous.Title)
+			assert.Equal(t, "home", events[2].Todo.Project, "deletes carry the last stored state")
+			assert.Equal(t, "Committed", events[3].Todo.Title)
+
+			again, err := outbox.ClaimPending(ctx, now, time.Minute, 10)
+			require.NoError(t, err)
+			assert.Empty(t, again, "claimed events are leased")
+
+			require.NoError(t, outbox.MarkDelivered(ctx, []uint64{pending[0].ID, pending[1].ID, pending[2].ID}, now))
+			require.NoError(t, outbox.Retry(ctx, pending[3].ID, now.Add(time.Second), "webhooks: database operation failed", []string{"change stream"}))
+			retried, err := outbox.ClaimPending(ctx, now.Add(2*time.Minute), time.Minute, 10)
+			require.NoError(t, err)
+			require.Len(t, retried, 1, "delivered events are not claimed again")
+			assert.Equal(t, pending[3].ID, retried[0].ID)
+			assert.Equal(t, 1, retried[0].Attempts)
+			assert.Equal(t, "webhooks: database operation failed", retried[0].LastError)
+			assert.Equal(t, []string{"change stream"}, retried[0].DeliveredTo)
+
+			n, err := outbox.DeleteDelivered(ctx, now)
+			require.NoError(t, err)
+			assert.Zero(t, n, "only events delivered before the cutoff are deleted")
+			n, err = outbox.DeleteDelivered(ctx, now.Add(time.Second))
+			require.NoError(t, err)
+			assert.EqualValues(t, 3, n)
+		})
+	}
+}
@@ -0,0 +1,490 @@
+// Package repositorytest holds the conformance suite every
+// domain.TodoRepository implementation must pass.
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"todo-app/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Factory returns an empty repository. It is called once per subtest so
+// implementations never share state between cases.
+type Factory func(t *testing.T) domain.TodoRepository
+
+// Run exercises the TodoRepository contract against the repository returned
+// by newRepo.
+func Run(t *testing.T, newRepo Factory) {
+	t.Run("create assigns id and timestamps", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		todo := newTodo("Write tests")
+
+		require.NoError(t, repo.Create(ctx, todo))
+
+		assert.NotEqual(t, uuid.Nil, todo.ID)
+		assert.False(t, todo.CreatedAt.IsZero())
+		assert.False(t, todo.UpdatedAt.IsZero())
+	})
+
+	t.Run("create keeps caller supplied id", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		id := uuid.New()
+		todo := newTodo("Keep my id")
+		todo.ID = id
+
+		require.NoError(t, repo.Create(ctx, todo))
+
+		found, err := repo.FindByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, id, found.ID)
+	})
+
+	t.Run("create rejects duplicate id", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		todo := newTodo("Original")
+		require.NoError(t, repo.Create(ctx, todo))
+
+		duplicate := newTodo("Duplicate")
+		duplicate.ID = todo.ID
+
+		assert.ErrorIs(t, repo.Create(ctx, duplicate), domain.ErrDatabaseOperation)
+	})
+
+	t.Run("create batch", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		batch := []domain.Todo{*newTodo("First"), *newTodo("Second")}
+		batch[1].Tags = []string{"imported"}
+
+		require.NoError(t, repo.CreateBatch(ctx, batch))
+
+		for _, todo := range batch {
+			assert.NotEqual(t, uuid.Nil, todo.ID)
+			assert.False(t, todo.CreatedAt.IsZero())
+		}
+		all, err := repo.FindAll(ctx)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, ids(batch), ids(all))
+		found, err := repo.FindByID(ctx, batch[1].ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"imported"}, found.Tags)
+		assert.NoError(t, repo.CreateBatch(ctx, nil))
+	})
+
+	t.Run("create batch is all or nothing", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		existing := newTodo("Existing")
+		require.NoError(t, repo.Create(ctx, existing))
+
+		err := repo.CreateBatch(ctx, []domain.Todo{*newTodo("New"), {ID: existing.ID, Title: "Duplicate", Status: "IN_PROGRESS"}})
+
+		assert.ErrorIs(t, err, domain.ErrDatabaseOperation)
+		all, err := repo.FindAll(ctx)
+		require.NoError(t, err)
+		assert.Len(t, all, 1)
+	})
+
+	t.Run("find by id round trips fields", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		todo := newTodo("Round trip")
+		todo.Description = "All fields survive storage"
+		todo.Image = "aGVsbG8="
+		todo.Project = "home"
+		todo.Tags = []string{"errand", "urgent"}
+		require.NoError(t, repo.Create(ctx, todo))
+
+		found, err := repo.FindByID(ctx, todo.ID)
+
+		require.NoError(t, err)
+		assert.Equal(t, todo.Title, found.Title)
+		assert.Equal(t, todo.Description, found.Description)
+		assert.Equal(t, todo.Image, found.Image)
+		assert.Equal(t, todo.Status, found.Status)
+		assert.Equal(t, todo.Project, found.Project)
+		assert.Equal(t, todo.Tags, found.Tags)
+		assert.WithinDuration(t, todo.CreatedAt, found.CreatedAt, time.Millisecond)
+	})
+
+	t.Run("find by id not found", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		found, err := repo.FindByID(ctx, uuid.New())
+
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		assert.Nil(t, found)
+	})
+
+	t.Run("find by id returns a copy", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		todo := newTodo("Immutable")
+		todo.Tags = []string{"original"}
+		require.NoError(t, repo.Create(ctx, todo))
+		todo.Tags[0] = "changed by caller"
+
+		found, err := repo.FindByID(ctx, todo.ID)
+		require.NoError(t, err)
+		found.Title = "Mutated"
+		found.Tags[0] = "mutated"
+
+		again, err := repo.FindByID(ctx, todo.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Immutable", again.Title)
+		assert.Equal(t, []string{"original"}, again.Tags)
+	})
+
+	t.Run("find all", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		empty, err := repo.FindAll(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, empty)
+
+		first, second := newTodo("First"), newTodo("Second")
+		require.NoError(t, repo.Create(ctx, first))
+		require.NoError(t, repo.Create(ctx, second))
+
+		todos, err := repo.FindAll(ctx)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []uuid.UUID{first.ID, second.ID}, ids(todos))
+	})
+
+	t.Run("update changes fields and keeps created at", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		todo := newTodo("Before")
+		require.NoError(t, repo.Create(ctx, todo))
+		createdAt := todo.CreatedAt
+
+		todo.Title = "After"
+		todo.Status = "COMPLETED"
+		require.NoError(t, repo.Update(ctx, todo))
+
+		found, err := repo.FindByID(ctx, todo.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "After", found.Title)
+		assert.Equal(t, "COMPLETED", found.Status)
+		assert.WithinDuration(t, createdAt, found.CreatedAt, time.Millisecond)
+		assert.False(t, found.UpdatedAt.Before(found.CreatedAt))
+	})
+
+	t.Run("count by status", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		done := newTodo("Done")
+		done.Status = "COMPLETED"
+		require.NoError(t, repo.Create(ctx, newTodo("Open one")))
+		require.NoError(t, repo.Create(ctx, newTodo("Open two")))
+		require.NoError(t, repo.Create(ctx, done))
+
+		counts, err := repo.CountByStatus(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int64{"IN_PROGRESS": 2, "COMPLETED": 1}, counts)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		todo := newTodo("Short lived")
+		require.NoError(t, repo.Create(ctx, todo))
+
+		require.NoError(t, repo.Delete(ctx, todo.ID))
+
+		_, err := repo.FindByID(ctx, todo.ID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		assert.ErrorIs(t, repo.Delete(ctx, todo.ID), domain.ErrNotFound)
+	})
+
+	t.Run("search", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		report := newTodo("Weekly report")
+		report.Description = "Send the weekly report to the team"
+		draft := newTodo("Draft report")
+		draft.Description = "Not ready"
+		groceries := newTodo("Groceries")
+		groceries.Description = "Milk, eggs and 100% juice"
+		for _, todo := range []*domain.Todo{report, draft, groceries} {
+			require.NoError(t, repo.Create(ctx, todo))
+		}
+
+		results, err := repo.Search(ctx, "report", 0)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, report.ID, results[0].Todo.ID, "more matches rank higher")
+		assert.Greater(t, results[0].Rank, results[1].Rank)
+		assert.Equal(t, "Weekly <mark>report</mark>", results[0].Highlights.Title)
+		assert.Contains(t, results[0].Highlights.Description, "<mark>report</mark>")
+
+		results, err = repo.Search(ctx, "report -draft OR milk", 0)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []uuid.UUID{report.ID, groceries.ID}, ids(todos(results)))
+
+		results, err = repo.Search(ctx, "report", 1)
+		require.NoError(t, err)
+		assert.Len(t, results, 1)
+
+		results, err = repo.Search(ctx, "100%", 0)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{groceries.ID}, ids(todos(results)))
+
+		results, err = repo.Search(ctx, "invoice", 0)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("fuzzy search", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		groceries := newTodo("Buy groceries")
+		grocer := newTodo("Call the grocer")
+		report := newTodo("Weekly report")
+		for _, todo := range []*domain.Todo{groceries, grocer, report} {
+			require.NoError(t, repo.Create(ctx, todo))
+		}
+
+		matches, err := repo.FuzzySearch(ctx, "grocerys", 0.3, 0)
+		require.NoError(t, err)
+		require.Len(t, matches, 2)
+		assert.Equal(t, groceries.ID, matches[0].Todo.ID, "closer titles score higher")
+		assert.Greater(t, matches[0].Score, matches[1].Score)
+		assert.LessOrEqual(t, matches[0].Score, 1.0)
+
+		matches, err = repo.FuzzySearch(ctx, "grocerys", 0.3, 1)
+		require.NoError(t, err)
+		assert.Len(t, matches, 1)
+
+		matches, err = repo.FuzzySearch(ctx, "grocerys", 0.9, 0)
+		require.NoError(t, err)
+		assert.Empty(t, matches, "the threshold excludes weaker matches")
+
+		matches, err = repo.FuzzySearch(ctx, "invoice", 0.3, 0)
+		require.NoError(t, err)
+		assert.Empty(t, matches)
+	})
+
+	t.Run("due date round trip", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		due := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+		todo := newTodo("Release")
+		todo.DueDate = &due
+		require.NoError(t, repo.Create(ctx, todo))
+
+		found, err := repo.FindByID(ctx, todo.ID)
+		require.NoError(t, err)
+		require.NotNil(t, found.DueDate)
+		assert.True(t, due.Equal(*found.DueDate))
+	})
+
+	t.Run("find by filter", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		due := time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)
+		notes := newTodo("Write release notes")
+		notes.Tags = []string{"backend", "docs"}
+		notes.Project = "Launch"
+		notes.DueDate = &due
+		api := newTodo("Ship API")
+		api.Description = "Release 100% of the endpoints"
+		api.Tags = []string{"backend-ops"}
+		api.Status = "COMPLETED"
+		completed := time.Date(2026, 10, 14, 9, 30, 0, 0, time.UTC)
+		api.CompletedAt = &completed
+		archived := newTodo("Archived plans")
+		for _, todo := range []*domain.Todo{notes, api, archived} {
+			require.NoError(t, repo.Create(ctx, todo))
+		}
+		day := func(d int) time.Time { return time.Date(2026, 10, d, 0, 0, 0, 0, time.UTC) }
+
+		tests := []struct {
+			name   string
+			filter domain.Filter
+			want   []uuid.UUID
+		}{
+			{"all", domain.AndFilter{}, []uuid.UUID{notes.ID, api.ID, archived.ID}},
+			{"none", domain.OrFilter{}, nil},
+			{"status", domain.FieldFilter{Field: domain.FilterStatus, Op: domain.OpEq, Value: "COMPLETED"}, []uuid.UUID{api.ID}},
+			{"project", domain.FieldFilter{Field: domain.FilterProject, Op: domain.OpEq, Value: "Launch"}, []uuid.UUID{notes.ID}},
+			{"tag is exact", domain.FieldFilter{Field: domain.FilterTag, Op: domain.OpEq, Value: "backend"}, []uuid.UUID{notes.ID}},
+			{"text", domain.FieldFilter{Field: domain.FilterText, Op: domain.OpContains, Value: "release"}, []uuid.UUID{notes.ID, api.ID}},
+			{"text escapes wildcards", domain.FieldFilter{Field: domain.FilterText, Op: domain.OpContains, Value: "100%"}, []uuid.UUID{api.ID}},
+			{"title", domain.FieldFilter{Field: domain.FilterTitle, Op: domain.OpContains, Value: "release"}, []uuid.UUID{notes.ID}},
+			{"due before", domain.FieldFilter{Field: domain.FilterDue, Op: domain.OpBefore, Value: day(21)}, []uuid.UUID{notes.ID}},
+			{"due not before", domain.FieldFilter{Field: domain.FilterDue, Op: domain.OpNotBefore, Value: day(21)}, nil},
+			{"due unset", domain.FieldFilter{Field: domain.FilterDue, Op: domain.OpUnset}, []uuid.UUID{api.ID, archived.ID}},
+			{"not due before", domain.NotFilter{Filter: domain.FieldFilter{Field: domain.FilterDue, Op: domain.OpBefore, Value: day(21)}}, []uuid.UUID{api.ID, archived.ID}},
+			{"completed not before", domain.FieldFilter{Field: domain.FilterCompleted, Op: domain.OpNotBefore, Value: day(14)}, []uuid.UUID{api.ID}},
+			{"completed before", domain.FieldFilter{Field: domain.FilterCompleted, Op: domain.OpBefore, Value: day(14)}, nil},
+			{"not completed before", domain.NotFilter{Filter: domain.FieldFilter{Field: domain.FilterCompleted, Op: domain.OpBefore, Value: day(14)}}, []uuid.UUID{notes.ID, api.ID, archived.ID}},
+			{"created", domain.FieldFilter{Field: domain.FilterCreated, Op: domain.OpNotBefore, Value: time.Now().Add(-time.Hour)}, []uuid.UUID{notes.ID, api.ID, archived.ID}},
+			{"and or not", domain.AndFilter{
+				domain.OrFilter{
+					domain.FieldFilter{Field: domain.FilterTag, Op: domain.OpEq, Value: "backend"},
+					domain.FieldFilter{Field: domain.FilterStatus, Op: domain.OpEq, Value: "COMPLETED"},
+				},
+				domain.NotFilter{Filter: domain.FieldFilter{Field: domain.FilterText, Op: domain.OpContains, Value: "notes"}},
+			}, []uuid.UUID{api.ID}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				found, err := repo.FindByFilter(ctx, tt.filter)
+				require.NoError(t, err)
+				assert.ElementsMatch(t, tt.want, ids(found))
+			})
+		}
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		due := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+		later := due.AddDate(0, 0, 7)
+		notes := newTodo("Write notes")
+		notes.Tags = []string{"docs"}
+		api := newTodo("Ship API")
+		api.Tags = []string{"backend"}
+		api.DueDate = &later
+		deploy := newTodo("Deploy")
+		deploy.Tags = []string{"backend"}
+		deploy.DueDate = &due
+		for _, todo := range []*domain.Todo{notes, api, deploy} {
+			require.NoError(t, repo.Create(ctx, todo))
+		}
+		stream := func(filter domain.Filter, sortBy string) []domain.Todo {
+			var streamed []domain.Todo
+			require.NoError(t, repo.Stream(ctx, filter, sortBy, func(todo domain.Todo) error {
+				streamed = append(streamed, todo)
+				return nil
+			}))
+			return streamed
+		}
+
+		assert.Equal(t, []uuid.UUID{deploy.ID, api.ID, notes.ID}, ids(stream(domain.AndFilter{}, "title")))
+		assert.Equal(t, []uuid.UUID{deploy.ID, api.ID, notes.ID}, ids(stream(domain.AndFilter{}, "due")), "todos without a due date last")
+		backend := stream(domain.FieldFilter{Field: domain.FilterTag, Op: domain.OpEq, Value: "backend"}, "title")
+		assert.Equal(t, []uuid.UUID{deploy.ID, api.ID}, ids(backend))
+		assert.Equal(t, []string{"backend"}, backend[0].Tags)
+		require.NotNil(t, backend[0].DueDate)
+		assert.True(t, due.Equal(*backend[0].DueDate))
+
+		stop := errors.New("stop")
+		calls := 0
+		err := repo.Stream(ctx, domain.AndFilter{}, "", func(domain.Todo) error {
+			calls++
+			return stop
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, calls, "streaming stops at the first error")
+	})
+
+	t.Run("facets", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		for _, todo := range []*domain.Todo{
+			{Title: "API", Status: "IN_PROGRESS", Project: "Launch", Tags: []string{"backend", "api"}},
+			{Title: "Docs", Status: "IN_PROGRESS", Project: "Launch", Tags: []string{"docs"}},
+			{Title: "Deploy", Status: "COMPLETED", Project: "Ops", Tags: []string{"backend"}},
+			{Title: "Groceries", Status: "IN_PROGRESS"},
+		} {
+			require.NoError(t, repo.Create(ctx, todo))
+		}
+
+		facets, err := repo.Facets(ctx, domain.AndFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, &domain.Facets{
+			Total:   4,
+			Status:  []domain.FacetCount{{Value: "IN_PROGRESS", Count: 3}, {Value: "COMPLETED", Count: 1}},
+			Tag:     []domain.FacetCount{{Value: "backend", Count: 2}, {Value: "api", Count: 1}, {Value: "docs", Count: 1}},
+			Project: []domain.FacetCount{{Value: "Launch", Count: 2}, {Value: "Ops", Count: 1}},
+		}, facets)
+
+		facets, err = repo.Facets(ctx, domain.FieldFilter{Field: domain.FilterTag, Op: domain.OpEq, Value: "backend"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), facets.Total)
+		assert.Equal(t, []domain.FacetCount{{Value: "COMPLETED", Count: 1}, {Value: "IN_PROGRESS", Count: 1}}, facets.Status)
+		assert.Equal(t, []domain.FacetCount{{Value: "backend", Count: 2}, {Value: "api", Count: 1}}, facets.Tag)
+
+		facets, err = repo.Facets(ctx, domain.OrFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, &domain.Facets{Status: []domain.FacetCount{}, Tag: []domain.FacetCount{}, Project: []domain.FacetCount{}}, facets)
+	})
+
+	t.Run("transaction commits", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		existing := newTodo("Existing")
+		require.NoError(t, repo.Create(ctx, existing))
+		created := newTodo("Created in transaction")
+
+		err := repo.Transaction(ctx, func(tx domain.TodoRepository) error {
+			if err := tx.Create(ctx, created); err != nil {
+				return err
+			}
+			return tx.Delete(ctx, existing.ID)
+		})
+
+		require.NoError(t, err)
+		all, err := repo.FindAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{created.ID}, ids(all))
+	})
+
+	t.Run("transaction rolls back on error", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		existing := newTodo("Existing")
+		require.NoError(t, repo.Create(ctx, existing))
+		created := newTodo("Rolled back")
+		failure := errors.New("abort")
+
+		err := repo.Transaction(ctx, func(tx domain.TodoRepository) error {
+			if err := tx.Create(ctx, created); err != nil {
+				return err
+			}
+			if err := tx.Delete(ctx, existing.ID); err != nil {
+				return err
+			}
+			return failure
+		})
+
+		assert.ErrorIs(t, err, failure)
+		all, err := repo.FindAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{existing.ID}, ids(all))
+	})
+}
+
+func newTodo(title string) *domain.Todo {
+	return &domain.Todo{Title: title, Status: "IN_PROGRESS"}
+}
+
+func todos(results []domain.SearchResult) []domain.Todo {
+	out := make([]domain.Todo, len(results))
+	for i, r := range results {
+		out[i] = r.Todo
+	}
+	return out
+}
+
+func ids(todos []domain.Todo) []uuid.UUID {
+	out := make([]uuid.UUID, len(todos))
+	for i, t := range todos {
+		out[i] = t.ID
+	}
+	return out
+}
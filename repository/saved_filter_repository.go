@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"todo-app/domain"
+	"todo-app/logging"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SavedFilterRepo struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewSavedFilterRepo(db *gorm.DB, logger *slog.Logger) *SavedFilterRepo {
+	return &SavedFilterRepo{db: db, logger: logger}
+}
+
+func (r *SavedFilterRepo) log(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx, r.logger)
+}
+
+func (r *SavedFilterRepo) Create(ctx context.Context, filter *domain.SavedFilter) error {
+	if err := r.db.WithContext(ctx).Create(filter).Error; err != nil {
+		return r.fail(ctx, "Failed to create saved filter", err)
+	}
+	r.log(ctx).InfoContext(ctx, "Saved filter created", "filter_id", filter.ID)
+	return nil
+}
+
+func (r *SavedFilterRepo) Update(ctx context.Context, filter *domain.SavedFilter) error {
+	if err := r.db.WithContext(ctx).Save(filter).Error; err != nil {
+		return r.fail(ctx, "Failed to update saved filter", err)
+	}
+	r.log(ctx).InfoContext(ctx, "Saved filter updated", "filter_id", filter.ID)
+	return nil
+}
+
+func (r *SavedFilterRepo) FindByID(ctx context.Context, id uuid.UUID) (*domain.SavedFilter, error) {
+	var filter domain.SavedFilter
+	err := r.db.WithContext(ctx).First(&filter, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		r.log(ctx).WarnContext(ctx, "Saved filter not found", "filter_id", id)
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, r.fail(ctx, "Failed to find saved filter", err)
+	}
+	return &filter, nil
+}
+
+func (r *SavedFilterRepo) FindByOwner(ctx context.Context, owner string) ([]domain.SavedFilter, error) {
+	var filters []domain.SavedFilter
+	if err := r.db.WithContext(ctx).Where("owner = ?", owner).Order("created_at").Find(&filters).Error; err != nil {
+		return nil, r.fail(ctx, "Failed to list saved filters", err)
+	}
+	return filters, nil
+}
+
+func (r *SavedFilterRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Delete(&domain.SavedFilter{}, "id = ?", id)
+	if result.Error != nil {
+		return r.fail(ctx, "Failed to delete saved filter", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		r.log(ctx).WarnContext(ctx, "Saved filter not found for deletion", "filter_id", id)
+		return domain.ErrNotFound
+	}
+	r.log(ctx).InfoContext(ctx, "Saved filter deleted", "filter_id", id)
+	return nil
+}
+
+func (r *SavedFilterRepo) fail(ctx context.Context, msg string, err error) error {
+	r.log(ctx).ErrorContext(ctx, msg, "error", err)
+	return fmt.Errorf("%w: %v", domain.ErrDatabaseOperation, err)
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"todo-app/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSavedFilterRepositories(t *testing.T) {
+	stores := map[string]func(t *testing.T) domain.SavedFilterRepository{
+		"gorm": func(t *testing.T) domain.SavedFilterRepository {
+			return NewSavedFilterRepo(setupTestDB(t), slog.Default())
+		},
+		"memory": func(t *testing.T) domain.SavedFilterRepository { return NewMemorySavedFilterRepo() },
+	}
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newStore(t)
+
+			mine := &domain.SavedFilter{Name: "Backend", Query: "tag:backend", Sort: "due", Owner: "alice"}
+			other := &domain.SavedFilter{Name: "Home", Query: "project:Home", Owner: "bob"}
+			require.NoError(t, store.Create(ctx, mine))
+			require.NoError(t, store.Create(ctx, other))
+			assert.NotEqual(t, uuid.Nil, mine.ID)
+
+			found, err := store.FindByID(ctx, mine.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "tag:backend", found.Query)
+			assert.Equal(t, "due", found.Sort)
+
+			found.Query = "tag:backend status:IN_PROGRESS"
+			require.NoError(t, store.Update(ctx, found))
+			owned, err := store.FindByOwner(ctx, "alice")
+			require.NoError(t, err)
+			require.Len(t, owned, 1)
+			assert.Equal(t, "tag:backend status:IN_PROGRESS", owned[0].Query)
+
+			require.NoError(t, store.Delete(ctx, mine.ID))
+			assert.ErrorIs(t, store.Delete(ctx, mine.ID), domain.ErrNotFound)
+			_, err = store.FindByID(ctx, mine.ID)
+			assert.ErrorIs(t, err, domain.ErrNotFound)
+			owned, err = store.FindByOwner(ctx, "alice")
+			require.NoError(t, err)
+			assert.Empty(t, owned)
+		})
+	}
+}
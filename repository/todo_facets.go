@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"todo-app/domain"
+	"todo-app/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
+)
+
+// Facets runs one GROUP BY query per facet, restricted by filter. Tags are
+// stored as a JSON array, which is expanded with the database's JSON table
+// function.
+func (r *TodoRepo) Facets(ctx context.Context, filter domain.Filter) (_ *domain.Facets, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TodoRepo.Facets")
+	defer func() { tracing.Finish(span, err) }()
+
+	facets, err := r.facets(ctx, filter)
+	if err != nil {
+		r.log(ctx).ErrorContext(ctx, "Failed to count facets", "error", err)
+		return nil, fmt.Errorf("%w: %v", domain.ErrDatabaseOperation, err)
+	}
+	span.SetAttributes(attribute.Int64("todo.count", facets.Total))
+	return facets, nil
+}
+
+func (r *TodoRepo) facets(ctx context.Context, filter domain.Filter) (*domain.Facets, error) {
+	if filter == nil {
+		filter = domain.AndFilter{}
+	}
+	condition, args, err := whereFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	todos := func() *gorm.DB {
+		return r.db.WithContext(ctx).Model(&domain.Todo{}).Where(condition, args...)
+	}
+
+	facets := &domain.Facets{}
+	if err := todos().Count(&facets.Total).Error; err != nil {
+		return nil, err
+	}
+	if facets.Status, err = countBy(todos(), "status"); err != nil {
+		return nil, err
+	}
+	if facets.Project, err = countBy(todos().Where("project IS NOT NULL AND project <> ''"), "project"); err != nil {
+		return nil, err
+	}
+
+	tags := todos()
+	if r.db.Dialector.Name() == "postgres" {
+		tags = tags.Joins(`CROSS JOIN LATERAL jsonb_array_elements_text(CASE WHEN jsonb_typeof(NULLIF(todos.tags, '')::jsonb) = 'array' THEN todos.tags::jsonb ELSE '[]'::jsonb END) AS tag(value)`)
+	} else {
+		tags = tags.Joins(`CROSS JOIN json_each(CASE WHEN json_valid(todos.tags) AND json_type(todos.tags) = 'array' THEN todos.tags ELSE '[]' END) AS tag`)
+	}
+	if facets.Tag, err = countBy(tags, "tag.value"); err != nil {
+		return nil, err
+	}
+	return facets, nil
+}
+
+// countBy groups db by column, most frequent value first.
+func countBy(db *gorm.DB, column string) ([]domain.FacetCount, error) {
+	counts := []domain.FacetCount{}
+	err := db.Select(column + " AS value, COUNT(*) AS count").
+		Group(column).
+		Order("count DESC, value").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// countFacets computes Facets in Go for the memory repository.
+func countFacets(todos []domain.Todo) *domain.Facets {
+	status, tag, project := map[string]int64{}, map[string]int64{}, map[string]int64{}
+	for _, todo := range todos {
+		status[todo.Status]++
+		if todo.Project != "" {
+			project[todo.Project]++
+		}
+		for _, t := range todo.Tags {
+			tag[t]++
+		}
+	}
+	return &domain.Facets{
+		Total:   int64(len(todos)),
+		Status:  sortedCounts(status),
+		Tag:     sortedCounts(tag),
+		Project: sortedCounts(project),
+	}
+}
+
+func sortedCounts(counts map[string]int64) []domain.FacetCount {
+	out := make([]domain.FacetCount, 0, len(counts))
+	for value, count := range counts {
+		out = append(out, domain.FacetCount{Value: value, Count: count})
+	}
+	slices.SortFunc(out, func(a, b domain.FacetCount) int {
+		if a.Count != b.Count {
+			if a.Count > b.Count {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Value, b.Value)
+	})
+	return out
+}
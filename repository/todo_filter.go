@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"todo-app/domain"
+	"todo-app/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// FindByFilter translates filter into a WHERE condition.
+func (r *TodoRepo) FindByFilter(ctx context.Context, filter domain.Filter) (_ []domain.Todo, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TodoRepo.FindByFilter")
+	defer func() { tracing.Finish(span, err) }()
+
+	condition, args, err := whereFilter(filter)
+	if err != nil {
+		r.log(ctx).ErrorContext(ctx, "Failed to translate filter", "error", err)
+		return nil, fmt.Errorf("%w: %v", domain.ErrDatabaseOperation, err)
+	}
+	var todos []domain.Todo
+	if err := r.db.WithContext(ctx).Where(condition, args...).Find(&todos).Error; err != nil {
+		r.log(ctx).ErrorContext(ctx, "Failed to filter todos", "error", err)
+		return nil, fmt.Errorf("%w: %v", domain.ErrDatabaseOperation, err)
+	}
+	span.SetAttributes(attribute.Int("todo.count", len(todos)))
+	r.log(ctx).InfoContext(ctx, "Todos filtered", "count", len(todos))
+	return todos, nil
+}
+
+// filterColumns maps the fields of field filters to their columns.
+var filterColumns = map[domain.FilterField]string{
+	domain.FilterStatus:      "status",
+	domain.FilterProject:     "project",
+	domain.FilterTitle:       "title",
+	domain.FilterDescription: "description",
+	domain.FilterDue:         "due_date",
+	domain.FilterCreated:     "created_at",
+	domain.FilterUpdated:     "updated_at",
+	domain.FilterCompleted:   "completed_at",
+}
+
+// whereFilter translates filter into a WHERE condition that is portable
+// across the supported databases.
+func whereFilter(filter domain.Filter) (string, []any, error) {
+	switch f := filter.(type) {
+	case domain.AndFilter:
+		return whereAll(f, " AND ", "1 = 1")
+	case domain.OrFilter:
+		return whereAll(f, " OR ", "1 = 0")
+	case domain.NotFilter:
+		condition, args, err := whereFilter(f.Filter)
+		if err != nil {
+			return "", nil, err
+		}
+		return "NOT (" + condition + ")", args, nil
+	case domain.FieldFilter:
+		return whereField(f)
+	default:
+		return "", nil, fmt.Errorf("unsupported filter %T", filter)
+	}
+}
+
+func whereAll(filters []domain.Filter, sep, empty string) (string, []any, error) {
+	if len(filters) == 0 {
+		return empty, nil, nil
+	}
+	conditions := make([]string, len(filters))
+	var args []any
+	for i, filter := range filters {
+		condition, a, err := whereFilter(filter)
+		if err != nil {
+			return "", nil, err
+		}
+		conditions[i] = "(" + condition + ")"
+		args = append(args, a...)
+	}
+	return strings.Join(conditions, sep), args, nil
+}
+
+func whereField(f domain.FieldFilter) (string, []any, error) {
+	if f.Op == domain.OpUnset {
+		column, ok := filterColumns[f.Field]
+		if !ok {
+			return "", nil, fmt.Errorf("unsupported filter %s %s", f.Field, f.Op)
+		}
+		return column + " IS NULL", nil, nil
+	}
+	switch value := f.Value.(type) {
+	case string:
+		return whereText(f.Field, f.Op, value)
+	case time.Time:
+		column, ok := filterColumns[f.Field]
+		if !ok {
+			return "", nil, fmt.Errorf("unsupported filter %s %s", f.Field, f.Op)
+		}
+		switch f.Op {
+		case domain.OpBefore:
+			return "(" + column + " IS NOT NULL AND " + column + " < ?)", []any{value}, nil
+		case domain.OpNotBefore:
+			return "(" + column + " IS NOT NULL AND " + column + " >= ?)", []any{value}, nil
+		}
+	}
+	return "", nil, fmt.Errorf("unsupported filter %s %s %T", f.Field, f.Op, f.Value)
+}
+
+func whereText(field domain.FilterField, op domain.FilterOp, value string) (string, []any, error) {
+	switch {
+	case field == domain.FilterTag && op == domain.OpEq:
+		// Tags are stored as a JSON array, so a tag is its JSON string
+		// within the column, quotes included.
+		quoted, err := json.Marshal(value)
+		if err != nil {
+			return "", nil, err
+		}
+		return `COALESCE(tags, '') LIKE ? ESCAPE '\'`, []any{likePattern(string(quoted))}, nil
+	case field == domain.FilterText && op == domain.OpContains:
+		return `(LOWER(title) LIKE ? ESCAPE '\' OR LOWER(COALESCE(description, '')) LIKE ? ESCAPE '\')`,
+			[]any{likePattern(value), likePattern(value)}, nil
+	}
+	column, ok := filterColumns[field]
+	if !ok {
+		return "", nil, fmt.Errorf("unsupported filter %s %s", field, op)
+	}
+	switch op {
+	case domain.OpEq:
+		return column + " = ?", []any{value}, nil
+	case domain.OpContains:
+		return "LOWER(COALESCE(" + column + `, '')) LIKE ? ESCAPE '\'`, []any{likePattern(value)}, nil
+	}
+	return "", nil, fmt.Errorf("unsupported filter %s %s", field, op)
+}
+
+// matchFilter evaluates filter against todo in Go, with the semantics of
+// whereFilter.
+func matchFilter(filter domain.Filter, todo *domain.Todo) bool {
+	switch f := filter.(type) {
+	case domain.AndFilter:
+		for _, filter := range f {
+			if !matchFilter(filter, todo) {
+				return false
+			}
+		}
+		return true
+	case domain.OrFilter:
+		for _, filter := range f {
+			if matchFilter(filter, todo) {
+				return true
+			}
+		}
+		return false
+	case domain.NotFilter:
+		return !matchFilter(f.Filter, todo)
+	case domain.FieldFilter:
+		return matchField(f, todo)
+	default:
+		return false
+	}
+}
+
+func matchField(f domain.FieldFilter, todo *domain.Todo) bool {
+	switch f.Field {
+	case domain.FilterStatus:
+		return matchText(f, todo.Status)
+	case domain.FilterProject:
+		return matchText(f, todo.Project)
+	case domain.FilterTitle:
+		return matchText(f, todo.Title)
+	case domain.FilterDescription:
+		return matchText(f, todo.Description)
+	case domain.FilterText:
+		return matchText(f, todo.Title) || matchText(f, todo.Description)
+	case domain.FilterTag:
+		value, _ := f.Value.(string)
+		return f.Op == domain.OpEq && todo.HasTag(value)
+	case domain.FilterDue:
+		if f.Op == domain.OpUnset {
+			return todo.DueDate == nil
+		}
+		return todo.DueDate != nil && matchTime(f, *todo.DueDate)
+	case domain.FilterCreated:
+		return matchTime(f, todo.CreatedAt)
+	case domain.FilterUpdated:
+		return matchTime(f, todo.UpdatedAt)
+	case domain.FilterCompleted:
+		return todo.CompletedAt != nil && matchTime(f, *todo.CompletedAt)
+	}
+	return false
+}
+
+func matchText(f domain.FieldFilter, have string) bool {
+	value, _ := f.Value.(string)
+	switch f.Op {
+	case domain.OpEq:
+		return have == value
+	case domain.OpContains:
+		return strings.Contains(strings.ToLower(have), value)
+	}
+	return false
+}
+
+func matchTime(f domain.FieldFilter, have time.Time) bool {
+	value, ok := f.Value.(time.Time)
+	if !ok {
+		return false
+	}
+	switch f.Op {
+	case domain.OpBefore:
+		return have.Before(value)
+	case domain.OpNotBefore:
+		return !have.Before(value)
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"todo-app/domain"
+	"todo-app/search"
+	"todo-app/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// migrateFuzzy enables pg_trgm and indexes todo titles by trigram, which
+// serves both the similarity and the word similarity operators.
+func migrateFuzzy(db *gorm.DB) error {
+	statements := []string{
+		`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+		`CREATE INDEX IF NOT EXISTS idx_todos_title_trgm ON todos USING GIN (title gin_trgm_ops)`,
+	}
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FuzzySearch uses pg_trgm on Postgres. Other databases score every title
+// in Go with search.FuzzyScore.
+func (r *TodoRepo) FuzzySearch(ctx context.Context, query string, threshold float64, limit int) (_ []domain.FuzzyMatch, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TodoRepo.FuzzySearch", trace.WithAttributes(
+		attribute.Float64("search.threshold", threshold),
+	))
+	defer func() { tracing.Finish(span, err) }()
+
+	var matches []domain.FuzzyMatch
+	if r.db.Dialector.Name() == "postgres" {
+		matches, err = r.fuzzyTrigram(ctx, query, threshold, limit)
+	} else {
+		var todos []domain.Todo
+		if err = r.db.WithContext(ctx).Find(&todos).Error; err == nil {
+			matches = scoreFuzzy(query, todos, threshold, limit)
+		}
+	}
+	if err != nil {
+		r.log(ctx).ErrorContext(ctx, "Failed to fuzzy search todos", "error", err)
+		return nil, fmt.Errorf("%w: %v", domain.ErrDatabaseOperation, err)
+	}
+	span.SetAttributes(attribute.Int("todo.count", len(matches)))
+	r.log(ctx).InfoContext(ctx, "Todos fuzzy searched", "count", len(matches))
+	return matches, nil
+}
+
+// fuzzyTrigram matches titles similar to query as a whole (%) or containing
+// a similar run of words (<%). The operators compare against thresholds
+// that are settings rather than arguments, so they are set for the
+// transaction only.
+func (r *TodoRepo) fuzzyTrigram(ctx context.Context, query string, threshold float64, limit int) ([]domain.FuzzyMatch, error) {
+	var rows []struct {
+		domain.Todo `gorm:"embedded"`
+		Score       float64
+	}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		setting := strconv.FormatFloat(threshold, 'f', -1, 64)
+		for _, name := range []string{"pg_trgm.similarity_threshold", "pg_trgm.word_similarity_threshold"} {
+			if err := tx.Exec("SELECT set_config(?, ?, true)", name, setting).Error; err != nil {
+				return err
+			}
+		}
+		db := tx.Table("todos").
+			Select("todos.*, GREATEST(similarity(title, ?), word_similarity(?, title)) AS score", query, query).
+			Where("title % ? OR ? <% title", query, query).
+			Order("score DESC, created_at DESC")
+		if limit > 0 {
+			db = db.Limit(limit)
+		}
+		return db.Scan(&rows).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]domain.FuzzyMatch, len(rows))
+	for i, row := range rows {
+		matches[i] = domain.FuzzyMatch{Todo: row.Todo, Score: row.Score}
+	}
+	return matches, nil
+}
+
+// scoreFuzzy keeps the todos whose title scores at least threshold against
+// query, best first, newest first among equals.
+func scoreFuzzy(query string, todos []domain.Todo, threshold float64, limit int) []domain.FuzzyMatch {
+	matches := []domain.FuzzyMatch{}
+	for _, todo := range todos {
+		if score := search.FuzzyScore(query, todo.Title); score >= threshold {
+			matches = append(matches, domain.FuzzyMatch{Todo: todo, Score: score})
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b domain.FuzzyMatch) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		return b.Todo.CreatedAt.Compare(a.Todo.CreatedAt)
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
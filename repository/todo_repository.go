@@ -1,14 +1,23 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"todo-app/domain"
+	"todo-app/logging"
+	"todo-app/tracing"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 )
 
+// TodoRepo stores todos with gorm. Create, CreateBatch, Update and Delete
+// record a todo event in the outbox within the same transaction as the
+// change.
 type TodoRepo struct {
 	db     *gorm.DB
 	logger *slog.Logger
@@ -18,59 +27,168 @@ func NewTodoRepo(db *gorm.DB, logger *slog.Logger) *TodoRepo {
 	return &TodoRepo{db: db, logger: logger}
 }
 
-func (r *TodoRepo) Create(todo *domain.Todo) error {
-	if err := r.db.Create(todo).Error; err != nil {
-		r.logger.Error("Failed to create todo", "error", err, "todo_id", todo.ID)
+// log returns the request-scoped logger from ctx, falling back to the
+// repository's own logger outside of a request.
+func (r *TodoRepo) log(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx, r.logger)
+}
+
+func (r *TodoRepo) Create(ctx context.Context, todo *domain.Todo) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TodoRepo.Create")
+	defer func() { tracing.Finish(span, err) }()
+
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(todo).Error; err != nil {
+			return err
+		}
+		return tx.Create(newOutboxEvent(domain.TodoCreated, todo, nil)).Error
+	})
+	if err != nil {
+		r.log(ctx).ErrorContext(ctx, "Failed to create todo", "error", err, "todo_id", todo.ID)
+		return fmt.Errorf("%w: %v", domain.ErrDatabaseOperation, err)
+	}
+	r.log(ctx).InfoContext(ctx, "Todo created", "todo_id", todo.ID)
+	return nil
+}
+
+func (r *TodoRepo) CreateBatch(ctx context.Context, todos []domain.Todo) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TodoRepo.CreateBatch",
+		trace.WithAttributes(attribute.Int("todo.count", len(todos))))
+	defer func() { tracing.Finish(span, err) }()
+
+	if len(todos) == 0 {
+		return nil
+	}
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&todos).Error; err != nil {
+			return err
+		}
+		events := make([]domain.OutboxEvent, len(todos))
+		for i := range todos {
+			events[i] = *newOutboxEvent(domain.TodoCreated, &todos[i], nil)
+		}
+		return tx.Create(&events).Error
+	})
+	if err != nil {
+		r.log(ctx).ErrorContext(ctx, "Failed to create todos", "error", err, "count", len(todos))
 		return fmt.Errorf("%w: %v", domain.ErrDatabaseOperation, err)
 	}
-	r.logger.Info("Todo created", "todo_id", todo.ID)
+	r.log(ctx).InfoContext(ctx, "Todos created", "count", len(todos))
 	return nil
 }
 
-func (r *TodoRepo) Update(todo *domain.Todo) error {
-	if err := r.db.Save(todo).Error; err != nil {
-		r.logger.Error("Failed to update todo", "error", err, "todo_id", todo.ID)
+func (r *TodoRepo) Update(ctx context.Context, todo *domain.Todo) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TodoRepo.Update")
+	defer func() { tracing.Finish(span, err) }()
+
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var previous *domain.Todo
+		var existing domain.Todo
+		switch err := tx.First(&existing, "id = ?", todo.ID).Error; {
+		case err == nil:
+			previous = &existing
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+		if err := tx.Save(todo).Error; err != nil {
+			return err
+		}
+		return tx.Create(newOutboxEvent(domain.TodoUpdated, todo, previous)).Error
+	})
+	if err != nil {
+		r.log(ctx).ErrorContext(ctx, "Failed to update todo", "error", err, "todo_id", todo.ID)
 		return fmt.Errorf("%w: %v", domain.ErrDatabaseOperation, err)
 	}
-	r.logger.Info("Todo updated", "todo_id", todo.ID)
+	r.log(ctx).InfoContext(ctx, "Todo updated", "todo_id", todo.ID)
 	return nil
 }
 
-func (r *TodoRepo) FindAll() ([]domain.Todo, error) {
+func (r *TodoRepo) FindAll(ctx context.Context) (_ []domain.Todo, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TodoRepo.FindAll")
+	defer func() { tracing.Finish(span, err) }()
+
 	var todos []domain.Todo
-	if err := r.db.Find(&todos).Error; err != nil {
-		r.logger.Error("Failed to list todos", "error", err)
+	if err := r.db.WithContext(ctx).Find(&todos).Error; err != nil {
+		r.log(ctx).ErrorContext(ctx, "Failed to list todos", "error", err)
 		return nil, fmt.Errorf("%w: %v", domain.ErrDatabaseOperation, err)
 	}
-	r.logger.Info("Todos retrieved", "count", len(todos))
+	span.SetAttributes(attribute.Int("todo.count", len(todos)))
+	r.log(ctx).InfoContext(ctx, "Todos retrieved", "count", len(todos))
 	return todos, nil
 }
 
-func (r *TodoRepo) FindByID(id uuid.UUID) (*domain.Todo, error) {
+func (r *TodoRepo) FindByID(ctx context.Context, id uuid.UUID) (_ *domain.Todo, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TodoRepo.FindByID")
+	defer func() { tracing.Finish(span, err) }()
+
 	var todo domain.Todo
-	err := r.db.First(&todo, "id = ?", id).Error
+	err = r.db.WithContext(ctx).First(&todo, "id = ?", id).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			r.logger.Warn("Todo not found", "todo_id", id)
+			r.log(ctx).WarnContext(ctx, "Todo not found", "todo_id", id)
 			return nil, domain.ErrNotFound
 		}
-		r.logger.Error("Failed to find todo", "error", err, "todo_id", id)
+		r.log(ctx).ErrorContext(ctx, "Failed to find todo", "error", err, "todo_id", id)
 		return nil, fmt.Errorf("%w: %v", domain.ErrDatabaseOperation, err)
 	}
-	r.logger.Info("Todo retrieved", "todo_id", id)
+	r.log(ctx).InfoContext(ctx, "Todo retrieved", "todo_id", id)
 	return &todo, nil
 }
 
-func (r *TodoRepo) Delete(id uuid.UUID) error {
-	result := r.db.Delete(&domain.Todo{}, "id = ?", id)
-	if err := result.Error; err != nil {
-		r.logger.Error("Failed to delete todo", "error", err, "todo_id", id)
-		return fmt.Errorf("%w: %v", domain.ErrDatabaseOperation, err)
-	}
-	if result.RowsAffected == 0 {
-		r.logger.Warn("Todo not found for deletion", "todo_id", id)
+func (r *TodoRepo) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TodoRepo.Delete")
+	defer func() { tracing.Finish(span, err) }()
+
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing domain.Todo
+		if err := tx.First(&existing, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&existing).Error; err != nil {
+			return err
+		}
+		return tx.Create(newOutboxEvent(domain.TodoDeleted, &existing, nil)).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		r.log(ctx).WarnContext(ctx, "Todo not found for deletion", "todo_id", id)
 		return domain.ErrNotFound
 	}
-	r.logger.Info("Todo deleted", "todo_id", id)
+	if err != nil {
+		r.log(ctx).ErrorContext(ctx, "Failed to delete todo", "error", err, "todo_id", id)
+		return fmt.Errorf("%w: %v", domain.ErrDatabaseOperation, err)
+	}
+	r.log(ctx).InfoContext(ctx, "Todo deleted", "todo_id", id)
 	return nil
 }
+
+func (r *TodoRepo) CountByStatus(ctx context.Context) (_ map[string]int64, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TodoRepo.CountByStatus")
+	defer func() { tracing.Finish(span, err) }()
+
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	err = r.db.WithContext(ctx).Model(&domain.Todo{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		r.log(ctx).ErrorContext(ctx, "Failed to count todos", "error", err)
+		return nil, fmt.Errorf("%w: %v", domain.ErrDatabaseOperation, err)
+	}
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *TodoRepo) Transaction(ctx context.Context, fn func(tx domain.TodoRepository) error) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TodoRepo.Transaction")
+	defer func() { tracing.Finish(span, err) }()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&TodoRepo{db: tx, logger: r.logger})
+	})
+}
@@ -1,13 +1,18 @@
 package repository
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
 	"testing"
+	"time"
 	"todo-app/domain"
+	"todo-app/repository/repositorytest"
 
+	"github.com/glebarez/sqlite"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
-	"gorm.io/driver/sqlite"
+	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 )
 
@@ -15,7 +20,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	assert.NoError(t, err)
 
-	err = db.AutoMigrate(&domain.Todo{})
+	err = Migrate(db)
 	assert.NoError(t, err)
 
 	return db
@@ -33,7 +38,7 @@ func TestTodoRepository_Create(t *testing.T) {
 			Status:      "IN_PROGRESS",
 		}
 
-		err := repo.Create(todo)
+		err := repo.Create(context.Background(), todo)
 
 		assert.NoError(t, err)
 		assert.NotZero(t, todo.ID)
@@ -62,7 +67,7 @@ func TestTodoRepository_FindByID(t *testing.T) {
 		assert.NoError(t, err)
 
 		// Find the created todo
-		found, err := repo.FindByID(todo.ID)
+		found, err := repo.FindByID(context.Background(), todo.ID)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, found)
@@ -72,9 +77,69 @@ func TestTodoRepository_FindByID(t *testing.T) {
 
 	t.Run("not found", func(t *testing.T) {
 		nonExistentID := uuid.New()
-		found, err := repo.FindByID(nonExistentID)
+		found, err := repo.FindByID(context.Background(), nonExistentID)
 
 		assert.Error(t, err)
 		assert.Nil(t, found)
 	})
 }
+
+func TestTodoRepository_StreamBatches(t *testing.T) {
+	db := setupTestDB(t)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	repo := NewTodoRepo(db, slog.Default())
+	ctx := context.Background()
+
+	// Enough todos for several batches, sharing titles, due dates and
+	// creation times so the pages must break ties by ID.
+	const total = 2*streamBatchSize + 7
+	created := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	due := created.AddDate(0, 1, 0)
+	todos := make([]domain.Todo, total)
+	for i := range todos {
+		todos[i] = domain.Todo{Title: fmt.Sprintf("Todo %d", i%3), Status: "IN_PROGRESS", CreatedAt: created}
+		if i%4 == 0 {
+			todos[i].DueDate = &due
+		}
+	}
+	for start := 0; start < total; start += 100 {
+		require.NoError(t, repo.CreateBatch(ctx, todos[start:min(start+100, total)]))
+	}
+
+	for _, sortBy := range []string{"", "title", "due"} {
+		t.Run(sortBy, func(t *testing.T) {
+			seen := map[uuid.UUID]bool{}
+			var streamed []domain.Todo
+			err := repo.Stream(ctx, domain.AndFilter{}, sortBy, func(todo domain.Todo) error {
+				assert.False(t, seen[todo.ID], "streamed twice")
+				seen[todo.ID] = true
+				streamed = append(streamed, todo)
+				// With a single connection this would block if the batch
+				// still held it.
+				queryCtx, cancel := context.WithTimeout(ctx, time.Second)
+				defer cancel()
+				_, err := repo.FindByID(queryCtx, todo.ID)
+				return err
+			})
+			require.NoError(t, err)
+			assert.Len(t, streamed, total)
+			for i := 1; i < len(streamed); i++ {
+				a, b := streamed[i-1], streamed[i]
+				switch sortBy {
+				case "title":
+					assert.LessOrEqual(t, a.Title, b.Title)
+				case "due":
+					assert.False(t, a.DueDate == nil && b.DueDate != nil, "todos without a due date last")
+				}
+			}
+		})
+	}
+}
+
+func TestTodoRepository_Conformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) domain.TodoRepository {
+		return NewTodoRepo(setupTestDB(t), slog.Default())
+	})
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"todo-app/domain"
+	"todo-app/search"
+	"todo-app/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
+)
+
+// searchConfig is the Postgres text search configuration used to index and
+// query todos.
+const searchConfig = "english"
+
+// migrateSearch adds the full-text search column and its index on Postgres.
+// The column is generated, so it never has to be written by the
+// repositories and is not part of domain.Todo.
+func migrateSearch(db *gorm.DB) error {
+	statements := []string{
+		`ALTER TABLE todos ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+			setweight(to_tsvector('` + searchConfig + `', coalesce(title, '')), 'A') ||
+			setweight(to_tsvector('` + searchConfig + `', coalesce(description, '')), 'B')
+		) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_todos_search_vector ON todos USING GIN (search_vector)`,
+	}
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Search uses the tsvector index on Postgres. Other databases filter with
+// LIKE and rank and highlight the matches in Go.
+func (r *TodoRepo) Search(ctx context.Context, query string, limit int) (_ []domain.SearchResult, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TodoRepo.Search")
+	defer func() { tracing.Finish(span, err) }()
+
+	var results []domain.SearchResult
+	if r.db.Dialector.Name() == "postgres" {
+		results, err = r.searchFullText(ctx, query, limit)
+	} else {
+		results, err = r.searchLike(ctx, query, limit)
+	}
+	if err != nil {
+		r.log(ctx).ErrorContext(ctx, "Failed to search todos", "error", err)
+		return nil, fmt.Errorf("%w: %v", domain.ErrDatabaseOperation, err)
+	}
+	span.SetAttributes(attribute.Int("todo.count", len(results)))
+	r.log(ctx).InfoContext(ctx, "Todos searched", "count", len(results))
+	return results, nil
+}
+
+func (r *TodoRepo) searchFullText(ctx context.Context, query string, limit int) ([]domain.SearchResult, error) {
+	var rows []struct {
+		domain.Todo          `gorm:"embedded"`
+		Rank                 float64
+		TitleHighlight       string
+		DescriptionHighlight string
+	}
+	// ts_headline returns the text as stored, so matches are delimited with
+	// markers and the text is escaped before they become mark tags.
+	highlight := fmt.Sprintf(`StartSel="%s", StopSel="%s"`, search.StartSel, search.StopSel)
+	db := r.db.WithContext(ctx).
+		Table("todos, websearch_to_tsquery(?, ?) AS query", searchConfig, query).
+		Select("todos.*, ts_rank_cd(todos.search_vector, query) AS rank, "+
+			"ts_headline(?, todos.title, query, ?) AS title_highlight, "+
+			"ts_headline(?, coalesce(todos.description, ''), query, ?) AS description_highlight",
+			searchConfig, highlight+", HighlightAll=true",
+			searchConfig, fmt.Sprintf("%s, MaxWords=%d, MinWords=%d", highlight, search.SnippetWords, search.SnippetWords/2)).
+		Where("todos.search_vector @@ query").
+		Order("rank DESC, todos.created_at DESC")
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if err := db.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	results := make([]domain.SearchResult, len(rows))
+	for i, row := range rows {
+		results[i] = domain.SearchResult{
+			Todo: row.Todo,
+			Rank: row.Rank,
+			Highlights: domain.SearchHighlights{
+				Title:       search.RenderHighlight(row.TitleHighlight),
+				Description: search.RenderHighlight(row.DescriptionHighlight),
+			},
+		}
+	}
+	return results, nil
+}
+
+func (r *TodoRepo) searchLike(ctx context.Context, query string, limit int) ([]domain.SearchResult, error) {
+	q := search.Parse(query)
+	if q.Empty() {
+		return []domain.SearchResult{}, nil
+	}
+	condition, args := likeCondition(q)
+	var todos []domain.Todo
+	if err := r.db.WithContext(ctx).Where(condition, args...).Find(&todos).Error; err != nil {
+		return nil, err
+	}
+	return rankMatches(q, todos, limit), nil
+}
+
+// likeCondition translates q into a WHERE condition of LIKE patterns on the
+// title and description.
+func likeCondition(q search.Query) (string, []any) {
+	const field = `(LOWER(title) LIKE ? ESCAPE '\' OR LOWER(COALESCE(description, '')) LIKE ? ESCAPE '\')`
+	var clauses []string
+	var args []any
+	for _, c := range q.Clauses {
+		var conditions []string
+		for _, term := range c.Include {
+			conditions = append(conditions, field)
+			args = append(args, likePattern(term), likePattern(term))
+		}
+		for _, term := range c.Exclude {
+			conditions = append(conditions, "NOT "+field)
+			args = append(args, likePattern(term), likePattern(term))
+		}
+		clauses = append(clauses, "("+strings.Join(conditions, " AND ")+")")
+	}
+	return strings.Join(clauses, " OR "), args
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func likePattern(term string) string {
+	return "%" + likeEscaper.Replace(term) + "%"
+}
+
+// rankMatches ranks and highlights the todos that match q, most relevant
+// first, newest first among equals.
+func rankMatches(q search.Query, todos []domain.Todo, limit int) []domain.SearchResult {
+	results := []domain.SearchResult{}
+	for _, todo := range todos {
+		if !q.Match(todo.Title, todo.Description) {
+			continue
+		}
+		results = append(results, domain.SearchResult{
+			Todo: todo,
+			Rank: q.Rank(todo.Title, todo.Description),
+			Highlights: domain.SearchHighlights{
+				Title:       q.Highlight(todo.Title),
+				Description: q.Snippet(todo.Description),
+			},
+		})
+	}
+	slices.SortStableFunc(results, func(a, b domain.SearchResult) int {
+		if a.Rank != b.Rank {
+			if a.Rank > b.Rank {
+				return -1
+			}
+			return 1
+		}
+		return b.Todo.CreatedAt.Compare(a.Todo.CreatedAt)
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
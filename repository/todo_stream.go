@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"todo-app/domain"
+	"todo-app/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// streamBatchSize is the number of todos Stream reads per query. A batch is
+// read in full before fn sees it, so no cursor, and on SQLite no
+// connection, stays open while fn writes to a slow client.
+const streamBatchSize = 500
+
+// streamKey is a column Stream orders and pages by, with its value in a
+// todo. Nullable keys sort last and have a nil value when NULL.
+type streamKey struct {
+	column   string
+	nullable bool
+	value    func(domain.Todo) any
+}
+
+var (
+	titleKey   = streamKey{column: "title", value: func(t domain.Todo) any { return t.Title }}
+	statusKey  = streamKey{column: "status", value: func(t domain.Todo) any { return t.Status }}
+	createdKey = streamKey{column: "created_at", value: func(t domain.Todo) any { return t.CreatedAt }}
+	idKey      = streamKey{column: "id", value: func(t domain.Todo) any { return t.ID }}
+	dueKey     = streamKey{column: "due_date", nullable: true, value: func(t domain.Todo) any {
+		if t.DueDate == nil {
+			return nil
+		}
+		return *t.DueDate
+	}}
+)
+
+// streamOrders maps the sort keys of domain.ListOptions to the keys Stream
+// orders by. Ties keep creation order, and the ID makes every position
+// unique so batches neither skip nor repeat todos. Other sort keys use
+// creation order.
+var streamOrders = map[string][]streamKey{
+	"title":  {titleKey, createdKey, idKey},
+	"date":   {createdKey, idKey},
+	"status": {statusKey, createdKey, idKey},
+	"due":    {dueKey, createdKey, idKey},
+}
+
+// Stream reads the matching todos in batches of streamBatchSize, each
+// starting after the last todo of the previous one, so exports do not hold
+// every todo in memory nor a database cursor while they write.
+func (r *TodoRepo) Stream(ctx context.Context, filter domain.Filter, sortBy string, fn func(domain.Todo) error) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TodoRepo.Stream", trace.WithAttributes(
+		attribute.String("todo.sort_by", sortBy),
+	))
+	defer func() { tracing.Finish(span, err) }()
+
+	fail := func(msg string, err error) error {
+		r.log(ctx).ErrorContext(ctx, msg, "error", err)
+		return fmt.Errorf("%w: %v", domain.ErrDatabaseOperation, err)
+	}
+	condition, args, err := whereFilter(filter)
+	if err != nil {
+		return fail("Failed to translate filter", err)
+	}
+	keys, ok := streamOrders[sortBy]
+	if !ok {
+		keys = streamOrders["date"]
+	}
+	order := make([]string, 0, len(keys)+1)
+	for _, key := range keys {
+		if key.nullable {
+			order = append(order, key.column+" IS NULL")
+		}
+		order = append(order, key.column)
+	}
+
+	count := 0
+	var last *domain.Todo
+	for {
+		db := r.db.WithContext(ctx).Where(condition, args...)
+		if last != nil {
+			after, afterArgs := afterKeys(keys, *last)
+			db = db.Where(after, afterArgs...)
+		}
+		var batch []domain.Todo
+		if err := db.Order(strings.Join(order, ", ")).Limit(streamBatchSize).Find(&batch).Error; err != nil {
+			return fail("Failed to stream todos", err)
+		}
+		for _, todo := range batch {
+			if err := fn(todo); err != nil {
+				return err
+			}
+			count++
+		}
+		if len(batch) < streamBatchSize {
+			break
+		}
+		last = &batch[len(batch)-1]
+	}
+	span.SetAttributes(attribute.Int("todo.count", count))
+	r.log(ctx).InfoContext(ctx, "Todos streamed", "count", count)
+	return nil
+}
+
+// afterKeys returns the condition matching the todos that come after last
+// in the order of keys.
+func afterKeys(keys []streamKey, last domain.Todo) (string, []any) {
+	if len(keys) == 0 {
+		return "1 = 0", nil
+	}
+	key := keys[0]
+	rest, restArgs := afterKeys(keys[1:], last)
+	value := key.value(last)
+	switch {
+	case !key.nullable:
+		return "(" + key.column + " > ? OR (" + key.column + " = ? AND " + rest + "))", append([]any{value, value}, restArgs...)
+	case value == nil:
+		// NULLs sort last, so only NULLs can follow a NULL.
+		return "(" + key.column + " IS NULL AND " + rest + ")", restArgs
+	default:
+		return "(" + key.column + " IS NULL OR " + key.column + " > ? OR (" + key.column + " = ? AND " + rest + "))", append([]any{value, value}, restArgs...)
+	}
+}
+
+// orderTodos sorts todos in place like streamOrders. The sort is stable, so
+// ties keep storage order.
+func orderTodos(todos []domain.Todo, sortBy string) {
+	var compare func(a, b domain.Todo) int
+	switch sortBy {
+	case "title":
+		compare = func(a, b domain.Todo) int { return cmp.Compare(a.Title, b.Title) }
+	case "date":
+		compare = func(a, b domain.Todo) int { return a.CreatedAt.Compare(b.CreatedAt) }
+	case "status":
+		compare = func(a, b domain.Todo) int { return cmp.Compare(a.Status, b.Status) }
+	case "due":
+		compare = func(a, b domain.Todo) int {
+			if a.DueDate == nil || b.DueDate == nil {
+				return cmp.Compare(boolRank(a.DueDate == nil), boolRank(b.DueDate == nil))
+			}
+			return a.DueDate.Compare(*b.DueDate)
+		}
+	default:
+		return
+	}
+	slices.SortStableFunc(todos, compare)
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
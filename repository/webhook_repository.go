@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"todo-app/domain"
+	"todo-app/logging"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookRepo struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewWebhookRepo(db *gorm.DB, logger *slog.Logger) *WebhookRepo {
+	return &WebhookRepo{db: db, logger: logger}
+}
+
+func (r *WebhookRepo) log(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx, r.logger)
+}
+
+func (r *WebhookRepo) Create(ctx context.Context, webhook *domain.Webhook) error {
+	if err := r.db.WithContext(ctx).Create(webhook).Error; err != nil {
+		return r.fail(ctx, "Failed to create webhook", err)
+	}
+	r.log(ctx).InfoContext(ctx, "Webhook created", "webhook_id", webhook.ID)
+	return nil
+}
+
+func (r *WebhookRepo) Update(ctx context.Context, webhook *domain.Webhook) error {
+	if err := r.db.WithContext(ctx).Save(webhook).Error; err != nil {
+		return r.fail(ctx, "Failed to update webhook", err)
+	}
+	r.log(ctx).InfoContext(ctx, "Webhook updated", "webhook_id", webhook.ID)
+	return nil
+}
+
+func (r *WebhookRepo) FindByID(ctx context.Context, id uuid.UUID) (*domain.Webhook, error) {
+	var webhook domain.Webhook
+	err := r.db.WithContext(ctx).First(&webhook, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		r.log(ctx).WarnContext(ctx, "Webhook not found", "webhook_id", id)
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, r.fail(ctx, "Failed to find webhook", err)
+	}
+	return &webhook, nil
+}
+
+func (r *WebhookRepo) FindAll(ctx context.Context) ([]domain.Webhook, error) {
+	var webhooks []domain.Webhook
+	if err := r.db.WithContext(ctx).Order("created_at").Find(&webhooks).Error; err != nil {
+		return nil, r.fail(ctx, "Failed to list webhooks", err)
+	}
+	return webhooks, nil
+}
+
+func (r *WebhookRepo) FindByOwner(ctx context.Context, owner string) ([]domain.Webhook, error) {
+	var webhooks []domain.Webhook
+	if err := r.db.WithContext(ctx).Where("owner = ?", owner).Order("created_at").Find(&webhooks).Error; err != nil {
+		return nil, r.fail(ctx, "Failed to list webhooks", err)
+	}
+	return webhooks, nil
+}
+
+func (r *WebhookRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	var found bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		deliveries := tx.Model(&domain.WebhookDelivery{}).Select("id").Where("webhook_id = ?", id)
+		if err := tx.Where("delivery_id IN (?)", deliveries).Delete(&domain.WebhookAttempt{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("webhook_id = ?", id).Delete(&domain.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&domain.Webhook{}, "id = ?", id)
+		found = result.RowsAffected > 0
+		return result.Error
+	})
+	if err != nil {
+		return r.fail(ctx, "Failed to delete webhook", err)
+	}
+	if !found {
+		r.log(ctx).WarnContext(ctx, "Webhook not found for deletion", "webhook_id", id)
+		return domain.ErrNotFound
+	}
+	r.log(ctx).InfoContext(ctx, "Webhook deleted", "webhook_id", id)
+	return nil
+}
+
+func (r *WebhookRepo) Enqueue(ctx context.Context, deliveries []domain.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "webhook_id"}, {Name: "event_id"}}, DoNothing: true}).
+		Create(&deliveries).Error
+	if err != nil {
+		return r.fail(ctx, "Failed to enqueue webhook deliveries", err)
+	}
+	return nil
+}
+
+func (r *WebhookRepo) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Joins("JOIN webhooks ON webhooks.id = webhook_deliveries.webhook_id").
+			Where("webhook_deliveries.status = ? AND webhook_deliveries.next_attempt_at <= ? AND webhooks.enabled = ?",
+				domain.DeliveryPending, now, true).
+			Order("webhook_deliveries.next_attempt_at").
+			Limit(limit)
+		// SQLite has a single writer, so only Postgres needs row locks to
+		// keep replicas from claiming the same deliveries.
+		if tx.Dialector.Name() == "postgres" {
+			query = query.Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "webhook_deliveries"}, Options: "SKIP LOCKED"})
+		}
+		if err := query.Find(&deliveries).Error; err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, len(deliveries))
+		for i := range deliveries {
+			ids[i] = deliveries[i].ID
+		}
+		return tx.Model(&domain.WebhookDelivery{}).Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, r.fail(ctx, "Failed to claim webhook deliveries", err)
+	}
+	return deliveries, nil
+}
+
+func (r *WebhookRepo) RecordAttempt(ctx context.Context, delivery *domain.WebhookDelivery, attempt *domain.WebhookAttempt, disableAfter int) (bool, error) {
+	var disabled bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		attempt.DeliveryID = delivery.ID
+		if err := tx.Create(attempt).Error; err != nil {
+			return err
+		}
+		err := tx.Model(delivery).Updates(map[string]any{
+			"status":          delivery.Status,
+			"attempt_count":   delivery.AttemptCount,
+			"next_attempt_at": delivery.NextAttemptAt,
+			"last_error":      delivery.LastError,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		webhooks := tx.Model(&domain.Webhook{}).Where("id = ?", delivery.WebhookID)
+		if attempt.Succeeded() {
+			return webhooks.Update("consecutive_failures", 0).Error
+		}
+		if err := webhooks.Update("consecutive_failures", gorm.Expr("consecutive_failures + 1")).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&domain.Webhook{}).
+			Where("id = ? AND enabled = ? AND consecutive_failures >= ?", delivery.WebhookID, true, disableAfter).
+			Updates(map[string]any{"enabled": false, "disabled_at": attempt.AttemptedAt})
+		disabled = result.RowsAffected > 0
+		return result.Error
+	})
+	if err != nil {
+		return false, r.fail(ctx, "Failed to record webhook attempt", err)
+	}
+	return disabled, nil
+}
+
+func (r *WebhookRepo) Deliveries(ctx context.Context, webhookID uuid.UUID, limit int) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+	err := r.db.WithContext(ctx).
+		Preload("Attempts", func(db *gorm.DB) *gorm.DB { return db.Order("number") }).
+		Where("webhook_id = ?", webhookID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, r.fail(ctx, "Failed to list webhook deliveries", err)
+	}
+	return deliveries, nil
+}
+
+func (r *WebhookRepo) fail(ctx context.Context, msg string, err error) error {
+	r.log(ctx).ErrorContext(ctx, msg, "error", err)
+	return fmt.Errorf("%w: %v", domain.ErrDatabaseOperation, err)
+}
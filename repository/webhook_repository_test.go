@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+	"todo-app/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookRepositories(t *testing.T) {
+	stores := map[string]func(t *testing.T) domain.WebhookRepository{
+		"gorm":   func(t *testing.T) domain.WebhookRepository { return NewWebhookRepo(setupTestDB(t), slog.Default()) },
+		"memory": func(t *testing.T) domain.WebhookRepository { return NewMemoryWebhookRepo() },
+	}
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newStore(t)
+			now := time.Now().UTC().Truncate(time.Millisecond)
+
+			webhook := &domain.Webhook{
+				URL:        "https://example.com/hook",
+				Secret:     "0123456789abcdef",
+				EventTypes: []domain.TodoEventType{domain.TodoCreated},
+				Enabled:    true,
+				Owner:      "alice",
+			}
+			require.NoError(t, store.Create(ctx, webhook))
+			other := &domain.Webhook{URL: "https://example.com/other", Secret: "0123456789abcdef", Owner: "bob"}
+			require.NoError(t, store.Create(ctx, other))
+			found, err := store.FindByID(ctx, webhook.ID)
+			require.NoError(t, err)
+			assert.Equal(t, webhook.URL, found.URL)
+			assert.Equal(t, webhook.EventTypes, found.EventTypes)
+			assert.Equal(t, "alice", found.Owner)
+			all, err := store.FindAll(ctx)
+			require.NoError(t, err)
+			assert.Len(t, all, 2)
+			owned, err := store.FindByOwner(ctx, "alice")
+			require.NoError(t, err)
+			require.Len(t, owned, 1)
+			assert.Equal(t, webhook.ID, owned[0].ID)
+
+			delivery := domain.WebhookDelivery{
+				WebhookID:     webhook.ID,
+				EventID:       "b-1",
+				EventType:     domain.TodoCreated,
+				Payload:       json.RawMessage(`{"type":"todo.created"}`),
+				Status:        domain.DeliveryPending,
+				NextAttemptAt: now.Add(-time.Second),
+			}
+			later := delivery
+			later.EventID, later.NextAttemptAt = "b-2", now.Add(time.Hour)
+			require.NoError(t, store.Enqueue(ctx, []domain.WebhookDelivery{delivery, later}))
+			require.NoError(t, store.Enqueue(ctx, []domain.WebhookDelivery{delivery}), "an event already queued is skipped")
+
+			claimed, err := store.ClaimDue(ctx, now, time.Minute, 10)
+			require.NoError(t, err)
+			require.Len(t, claimed, 1, "only due deliveries are claimed")
+			assert.Equal(t, "b-1", claimed[0].EventID)
+			assert.JSONEq(t, `{"type":"todo.created"}`, string(claimed[0].Payload))
+			again, err := store.ClaimDue(ctx, now, time.Minute, 10)
+			require.NoError(t, err)
+			assert.Empty(t, again, "claimed deliveries are leased")
+
+			d := claimed[0]
+			d.AttemptCount, d.LastError, d.NextAttemptAt = 1, "503 Service Unavailable", now.Add(-time.Second)
+			disabled, err := store.RecordAttempt(ctx, &d, &domain.WebhookAttempt{Number: 1, StatusCode: 503, AttemptedAt: now}, 2)
+			require.NoError(t, err)
+			assert.False(t, disabled)
+
+			d.AttemptCount = 2
+			disabled, err = store.RecordAttempt(ctx, &d, &domain.WebhookAttempt{Number: 2, Error: "connection refused", AttemptedAt: now}, 2)
+			require.NoError(t, err)
+			assert.True(t, disabled, "second consecutive failure disables the webhook")
+			found, err = store.FindByID(ctx, webhook.ID)
+			require.NoError(t, err)
+			assert.False(t, found.Enabled)
+			assert.Equal(t, 2, found.ConsecutiveFailures)
+			assert.NotNil(t, found.DisabledAt)
+
+			claimed, err = store.ClaimDue(ctx, now, time.Minute, 10)
+			require.NoError(t, err)
+			assert.Empty(t, claimed, "deliveries of disabled webhooks wait")
+
+			found.Enabled, found.ConsecutiveFailures, found.DisabledAt = true, 0, nil
+			require.NoError(t, store.Update(ctx, found))
+			claimed, err = store.ClaimDue(ctx, now, time.Minute, 10)
+			require.NoError(t, err)
+			require.Len(t, claimed, 1)
+			d = claimed[0]
+			d.AttemptCount, d.Status, d.LastError = 3, domain.DeliverySucceeded, ""
+			_, err = store.RecordAttempt(ctx, &d, &domain.WebhookAttempt{Number: 3, StatusCode: 204, AttemptedAt: now}, 2)
+			require.NoError(t, err)
+
+			deliveries, err := store.Deliveries(ctx, webhook.ID, 10)
+			require.NoError(t, err)
+			require.Len(t, deliveries, 2)
+			var delivered domain.WebhookDelivery
+			for _, d := range deliveries {
+				if d.EventID == "b-1" {
+					delivered = d
+				}
+			}
+			assert.Equal(t, domain.DeliverySucceeded, delivered.Status)
+			require.Len(t, delivered.Attempts, 3)
+			assert.Equal(t, []int{503, 0, 204}, []int{delivered.Attempts[0].StatusCode, delivered.Attempts[1].StatusCode, delivered.Attempts[2].StatusCode})
+			assert.Equal(t, "connection refused", delivered.Attempts[1].Error)
+
+			require.NoError(t, store.Delete(ctx, webhook.ID))
+			_, err = store.FindByID(ctx, webhook.ID)
+			assert.ErrorIs(t, err, domain.ErrNotFound)
+			deliveries, err = store.Deliveries(ctx, webhook.ID, 10)
+			require.NoError(t, err)
+			assert.Empty(t, deliveries)
+			assert.ErrorIs(t, store.Delete(ctx, webhook.ID), domain.ErrNotFound)
+		})
+	}
+}
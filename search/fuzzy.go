@@ -0,0 +1,102 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DefaultThreshold is the fuzzy score a text needs to match when no
+// threshold is given, the default similarity threshold of pg_trgm.
+const DefaultThreshold = 0.3
+
+// FuzzyScore rates from 0 to 1 how closely text resembles query, ignoring
+// case and punctuation. It is the best trigram or edit distance similarity
+// between query and the whole text or any run of as many words of text as
+// query has, so a misspelled word still scores high within a long title,
+// like word_similarity does on Postgres. Edit distance catches swapped
+// letters in short words, which share few trigrams.
+func FuzzyScore(query, text string) float64 {
+	q := words(query)
+	t := words(text)
+	if len(q) == 0 || len(t) == 0 {
+		return 0
+	}
+	needle := strings.Join(q, " ")
+	best := max(Similarity(needle, strings.Join(t, " ")), editSimilarity(needle, strings.Join(t, " ")))
+	for i := 0; i+len(q) <= len(t); i++ {
+		window := strings.Join(t[i:i+len(q)], " ")
+		best = max(best, Similarity(needle, window), editSimilarity(needle, window))
+	}
+	return best
+}
+
+// Similarity is the trigram similarity of a and b as pg_trgm computes it:
+// the number of trigrams they share divided by the number of distinct
+// trigrams of both.
+func Similarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	shared := 0
+	for t := range ta {
+		if _, ok := tb[t]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
+
+// trigrams extracts the trigrams of s like pg_trgm: every word, lower cased
+// and padded with two spaces in front and one behind, contributes each of
+// its three character substrings.
+func trigrams(s string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, word := range words(s) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = struct{}{}
+		}
+	}
+	return set
+}
+
+// words splits s into lower case runs of letters and digits.
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// editSimilarity is 1 minus the Levenshtein distance of a and b relative to
+// the longer of the two.
+func editSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 0
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein counts the insertions, deletions and substitutions that turn
+// a into b.
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
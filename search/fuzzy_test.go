@@ -0,0 +1,43 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimilarity(t *testing.T) {
+	// The values pg_trgm's similarity() returns for the same inputs.
+	assert.InDelta(t, 1.0, Similarity("Word", "word"), 1e-9)
+	assert.InDelta(t, 4.0/11, Similarity("word", "two words"), 1e-9)
+	assert.Zero(t, Similarity("abc", "xyz"))
+	assert.Zero(t, Similarity("", "word"))
+}
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 3, levenshtein([]rune("kitten"), []rune("sitting")))
+	assert.Equal(t, 2, levenshtein([]rune("tset"), []rune("test")))
+	assert.Equal(t, 4, levenshtein(nil, []rune("test")))
+}
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		query, text string
+		above       bool
+	}{
+		{"grocerys", "Buy groceries for the week", true},
+		{"relase notes", "Write release notes", true},
+		{"tset", "Run the test suite", true},
+		{"Weekly Report", "weekly report", true},
+		{"invoice", "Buy groceries for the week", false},
+		{"", "anything", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			score := FuzzyScore(tt.query, tt.text)
+			assert.Equal(t, tt.above, score >= DefaultThreshold, "score %.2f", score)
+		})
+	}
+	assert.Equal(t, 1.0, FuzzyScore("report", "Weekly report"))
+	assert.Greater(t, FuzzyScore("grocerys", "Groceries"), FuzzyScore("grocerys", "Go to the grocer"))
+}
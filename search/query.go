@@ -0,0 +1,198 @@
+// Package search parses web search style queries and evaluates them in Go
+// for storage backends without a full-text index. The syntax follows
+// Postgres' websearch_to_tsquery: words must all appear, "quoted phrases"
+// must appear as written, OR separates alternatives and a leading - excludes
+// a word or phrase.
+package search
+
+import (
+	"html"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Tags wrapped around matched terms in highlights.
+const (
+	MarkStart = "<mark>"
+	MarkEnd   = "</mark>"
+)
+
+// StartSel and StopSel delimit matches in text that is not escaped yet, such
+// as the output of ts_headline. They are private use characters, so they do
+// not turn up in todos; RenderHighlight replaces them with the mark tags.
+const (
+	StartSel = "\ue000"
+	StopSel  = "\ue001"
+)
+
+// SnippetWords is the length, in words, of a description snippet.
+const SnippetWords = 35
+
+// Relevance weights of a match in the title and in the description. They
+// match the default weights of ts_rank for the A and B labels.
+const (
+	titleWeight       = 1.0
+	descriptionWeight = 0.4
+)
+
+// Query is a parsed search. It matches a text that satisfies any clause.
+type Query struct {
+	Clauses []Clause
+
+	highlight *regexp.Regexp
+}
+
+// Clause matches a text that contains every Include term and no Exclude
+// term. Terms are lower case.
+type Clause struct {
+	Include []string
+	Exclude []string
+}
+
+// Parse parses s. Words are stripped of surrounding punctuation, so a query
+// without any word or phrase yields an empty Query.
+func Parse(s string) Query {
+	var q Query
+	var clause Clause
+	flush := func() {
+		if len(clause.Include) > 0 || len(clause.Exclude) > 0 {
+			q.Clauses = append(q.Clauses, clause)
+		}
+		clause = Clause{}
+	}
+
+	for rest := strings.TrimSpace(s); rest != ""; rest = strings.TrimLeftFunc(rest, unicode.IsSpace) {
+		exclude := false
+		if strings.HasPrefix(rest, "-") {
+			exclude, rest = true, rest[1:]
+		}
+
+		var term string
+		quoted := strings.HasPrefix(rest, `"`)
+		if quoted {
+			phrase, after, _ := strings.Cut(rest[1:], `"`)
+			term, rest = strings.Join(strings.Fields(phrase), " "), after
+		} else {
+			end := strings.IndexFunc(rest, unicode.IsSpace)
+			if end < 0 {
+				end = len(rest)
+			}
+			term, rest = rest[:end], rest[end:]
+			if !exclude && strings.EqualFold(term, "or") {
+				flush()
+				continue
+			}
+			term = strings.TrimFunc(term, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsNumber(r) })
+		}
+
+		term = strings.ToLower(term)
+		switch {
+		case term == "":
+		case exclude:
+			clause.Exclude = append(clause.Exclude, term)
+		default:
+			clause.Include = append(clause.Include, term)
+		}
+	}
+	flush()
+
+	if terms := q.Terms(); len(terms) > 0 {
+		// Longest first, so a phrase wins over a word it contains.
+		slices.SortFunc(terms, func(a, b string) int { return len(b) - len(a) })
+		quoted := make([]string, len(terms))
+		for i, term := range terms {
+			quoted[i] = regexp.QuoteMeta(term)
+		}
+		q.highlight = regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))
+	}
+	return q
+}
+
+// Empty reports whether q has no clauses. An empty query matches nothing.
+func (q Query) Empty() bool {
+	return len(q.Clauses) == 0
+}
+
+// Terms returns the distinct included terms of every clause.
+func (q Query) Terms() []string {
+	var terms []string
+	for _, c := range q.Clauses {
+		for _, term := range c.Include {
+			if !slices.Contains(terms, term) {
+				terms = append(terms, term)
+			}
+		}
+	}
+	return terms
+}
+
+// Match reports whether the title and description of a todo satisfy q.
+func (q Query) Match(title, description string) bool {
+	text := strings.ToLower(title + "\n" + description)
+	for _, c := range q.Clauses {
+		if c.match(text) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Clause) match(text string) bool {
+	for _, term := range c.Include {
+		if !strings.Contains(text, term) {
+			return false
+		}
+	}
+	for _, term := range c.Exclude {
+		if strings.Contains(text, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// Rank scores how well a todo matches: every occurrence of an included term
+// counts, those in the title more than those in the description.
+func (q Query) Rank(title, description string) float64 {
+	if q.highlight == nil {
+		return 0
+	}
+	return titleWeight*float64(len(q.highlight.FindAllStringIndex(title, -1))) +
+		descriptionWeight*float64(len(q.highlight.FindAllStringIndex(description, -1)))
+}
+
+// Highlight wraps every occurrence of an included term in text in MarkStart
+// and MarkEnd.
+func (q Query) Highlight(text string) string {
+	if q.highlight == nil {
+		return html.EscapeString(text)
+	}
+	return RenderHighlight(q.highlight.ReplaceAllString(text, StartSel+"$0"+StopSel))
+}
+
+// RenderHighlight HTML escapes text whose matches are delimited by StartSel
+// and StopSel and wraps the matches in <mark> tags.
+func RenderHighlight(text string) string {
+	return strings.NewReplacer(StartSel, MarkStart, StopSel, MarkEnd).Replace(html.EscapeString(text))
+}
+
+// Snippet returns up to SnippetWords words of text, starting shortly before
+// the first match, with matches highlighted.
+func (q Query) Snippet(text string) string {
+	words := strings.Fields(text)
+	if len(words) <= SnippetWords {
+		return q.Highlight(text)
+	}
+	start := 0
+	if q.highlight != nil {
+		for i, word := range words {
+			if q.highlight.MatchString(word) {
+				start = max(0, min(i-SnippetWords/4, len(words)-SnippetWords))
+				break
+			}
+		}
+	}
+	return q.Highlight(strings.Join(words[start:start+SnippetWords], " "))
+}
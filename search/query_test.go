@@ -0,0 +1,84 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []Clause
+	}{
+		{"", nil},
+		{"  !? ", nil},
+		{"Buy milk", []Clause{{Include: []string{"buy", "milk"}}}},
+		{`"weekly report" draft,`, []Clause{{Include: []string{"weekly report", "draft"}}}},
+		{"report -draft", []Clause{{Include: []string{"report"}, Exclude: []string{"draft"}}}},
+		{`-"to do"`, []Clause{{Exclude: []string{"to do"}}}},
+		{"milk or eggs", []Clause{{Include: []string{"milk"}}, {Include: []string{"eggs"}}}},
+		{"OR milk OR", []Clause{{Include: []string{"milk"}}}},
+		{`"unterminated phrase`, []Clause{{Include: []string{"unterminated phrase"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q := Parse(tt.query)
+			assert.Equal(t, tt.want, q.Clauses)
+			assert.Equal(t, tt.want == nil, q.Empty())
+		})
+	}
+}
+
+func TestQuery_Match(t *testing.T) {
+	q := Parse(`"weekly report" -draft OR invoice`)
+
+	assert.True(t, q.Match("Weekly Report", ""))
+	assert.True(t, q.Match("Send", "the weekly report to Anna"))
+	assert.False(t, q.Match("Weekly report", "still a draft"))
+	assert.False(t, q.Match("Report weekly", ""), "phrases keep their word order")
+	assert.True(t, q.Match("Pay invoice", "draft"))
+	assert.False(t, Parse("").Match("anything", ""))
+}
+
+func TestQuery_Rank(t *testing.T) {
+	q := Parse("milk")
+
+	assert.Greater(t, q.Rank("Buy milk", ""), q.Rank("Groceries", "milk"), "title matches weigh more")
+	assert.Greater(t, q.Rank("Milk", "oat milk, not cow milk"), q.Rank("Milk", ""))
+	assert.Zero(t, q.Rank("Bread", ""))
+}
+
+func TestQuery_Highlight(t *testing.T) {
+	q := Parse(`"weekly report" report`)
+
+	assert.Equal(t, "Send <mark>Weekly Report</mark> and <mark>report</mark>s", q.Highlight("Send Weekly Report and reports"))
+	assert.Equal(t, "untouched", Parse("").Highlight("untouched"))
+	assert.Equal(t, "&lt;b&gt;bold&lt;/b&gt;", Parse("").Highlight("<b>bold</b>"))
+}
+
+func TestQuery_HighlightEscapesHTML(t *testing.T) {
+	q := Parse("report")
+
+	assert.Equal(t, `&lt;img src=x onerror=&#34;alert(1)&#34;&gt; <mark>report</mark> &amp; more`,
+		q.Highlight(`<img src=x onerror="alert(1)"> report & more`))
+	assert.Equal(t, "&lt;script&gt;<mark>report</mark>&lt;/script&gt;", q.Snippet("<script>report</script>"))
+}
+
+func TestRenderHighlight(t *testing.T) {
+	assert.Equal(t, "a &lt;i&gt; <mark>b</mark>", RenderHighlight("a <i> "+StartSel+"b"+StopSel))
+}
+
+func TestQuery_Snippet(t *testing.T) {
+	q := Parse("needle")
+	words := strings.Fields(strings.Repeat("hay ", 100))
+	words[60] = "needle"
+
+	snippet := q.Snippet(strings.Join(words, " "))
+
+	assert.Len(t, strings.Fields(snippet), SnippetWords)
+	assert.Contains(t, snippet, "<mark>needle</mark>")
+	assert.False(t, strings.HasPrefix(snippet, "<mark>"), "context is kept before the match")
+	assert.Equal(t, "short <mark>needle</mark>", q.Snippet("short needle"))
+}
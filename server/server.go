@@ -0,0 +1,174 @@
+// Package server runs the HTTP server together with background workers and
+// shuts everything down in order when the run context is cancelled.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Server owns an http.Server plus the background workers and resources whose
+// lifetime is tied to it.
+//
+// Shutdown happens in this order: shutdown hooks run, such as the one
+// failing readiness, the server keeps serving for Timeouts.Delay so load
+// balancers notice, drain hooks run, the listener stops accepting
+// connections, in-flight requests drain for up to Timeouts.Drain, workers
+// are cancelled and awaited for up to Timeouts.Workers, and finally closers
+// release resources such as the database pool.
+type Server struct {
+	http     *http.Server
+	timeouts Timeouts
+	logger   *slog.Logger
+
+	hooks      []func()
+	drainHooks []func()
+	workers    []worker
+	closers    []closer
+}
+
+// Timeouts bound the phases of a shutdown. Each phase has its own budget,
+// so a slow drain does not leave workers such as the outbox relay without
+// time to finish.
+type Timeouts struct {
+	// Delay is how long requests are still served after the shutdown hooks
+	// ran, before the listener closes.
+	Delay time.Duration
+	// Drain is how long in-flight requests may take to finish.
+	Drain time.Duration
+	// Workers is how long workers may take to stop.
+	Workers time.Duration
+}
+
+type worker struct {
+	name string
+	run  func(ctx context.Context)
+}
+
+type closer struct {
+	name  string
+	close func() error
+}
+
+func New(srv *http.Server, timeouts Timeouts, logger *slog.Logger) *Server {
+	return &Server{
+		http:     srv,
+		timeouts: timeouts,
+		logger:   logger,
+	}
+}
+
+// OnShutdown registers fn to run as soon as shutdown starts, before the
+// shutdown delay.
+func (s *Server) OnShutdown(fn func()) {
+	s.hooks = append(s.hooks, fn)
+}
+
+// OnDrain registers fn to run after the shutdown delay, just before the
+// listener is closed, for ending connections that would not drain on their
+// own.
+func (s *Server) OnDrain(fn func()) {
+	s.drainHooks = append(s.drainHooks, fn)
+}
+
+// AddWorker registers a background worker. run must return once ctx is done.
+func (s *Server) AddWorker(name string, run func(ctx context.Context)) {
+	s.workers = append(s.workers, worker{name: name, run: run})
+}
+
+// AddCloser registers a resource to release after requests and workers have
+// stopped. Closers run in reverse registration order.
+func (s *Server) AddCloser(name string, close func() error) {
+	s.closers = append(s.closers, closer{name: name, close: close})
+}
+
+// Run serves until ctx is cancelled or the listener fails, then shuts down.
+func (s *Server) Run(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.http.Addr)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", s.http.Addr, err)
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve is Run with a caller supplied listener.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	var wg sync.WaitGroup
+	for _, w := range s.workers {
+		wg.Add(1)
+		go func(w worker) {
+			defer wg.Done()
+			s.logger.Info("Worker started", "worker", w.name)
+			w.run(workerCtx)
+			s.logger.Info("Worker stopped", "worker", w.name)
+		}(w)
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		s.logger.Info("Starting server", "addr", ln.Addr().String())
+		serveErr <- s.http.Serve(ln)
+	}()
+
+	var runErr error
+	delay := s.timeouts.Delay
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			runErr = fmt.Errorf("serving http: %w", err)
+		}
+		delay = 0 // Nothing is being served to wait for.
+	case <-ctx.Done():
+		s.logger.Info("Shutdown signal received", "delay", delay.String())
+	}
+
+	for _, hook := range s.hooks {
+		hook()
+	}
+	// Keep serving while load balancers see the failing readiness probe and
+	// stop sending new requests.
+	time.Sleep(delay)
+
+	for _, hook := range s.drainHooks {
+		hook()
+	}
+	s.logger.Info("Draining connections", "timeout", s.timeouts.Drain.String())
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), s.timeouts.Drain)
+	defer cancelDrain()
+	if err := s.http.Shutdown(drainCtx); err != nil {
+		s.logger.Warn("Connections did not drain before timeout, closing them", "error", err)
+		s.http.Close()
+	}
+
+	stopWorkers()
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(s.timeouts.Workers):
+		s.logger.Warn("Workers did not stop before timeout", "timeout", s.timeouts.Workers.String())
+	}
+
+	for i := len(s.closers) - 1; i >= 0; i-- {
+		c := s.closers[i]
+		if err := c.close(); err != nil {
+			s.logger.Error("Failed to close resource", "resource", c.name, "error", err)
+			runErr = errors.Join(runErr, fmt.Errorf("closing %s: %w", c.name, err))
+		}
+	}
+
+	s.logger.Info("Server stopped")
+	return runErr
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listen(t *testing.T) net.Listener {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	return ln
+}
+
+func TestServer_DrainsInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		io.WriteString(w, "done")
+	})
+	srv := New(&http.Server{Handler: handler}, Timeouts{Drain: time.Second, Workers: time.Second}, slog.Default())
+
+	var order []string
+	srv.OnShutdown(func() { order = append(order, "hook") })
+	srv.OnDrain(func() { order = append(order, "drain") })
+	srv.AddWorker("ticker", func(ctx context.Context) {
+		<-ctx.Done()
+		order = append(order, "worker")
+	})
+	srv.AddCloser("first", func() error { order = append(order, "first"); return nil })
+	srv.AddCloser("second", func() error { order = append(order, "second"); return nil })
+
+	ln := listen(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() { runErr <- srv.Serve(ctx, ln) }()
+
+	respCh := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String())
+		if err != nil {
+			respCh <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		respCh <- string(body)
+	}()
+
+	<-started
+	cancel()
+
+	assert.Equal(t, "done", <-respCh)
+	require.NoError(t, <-runErr)
+	assert.Equal(t, []string{"hook", "drain", "worker", "second", "first"}, order)
+}
+
+func TestServer_ForcesCloseAfterTimeout(t *testing.T) {
+	var finished atomic.Bool
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+			finished.Store(true)
+		}
+	})
+	srv := New(&http.Server{Handler: handler}, Timeouts{Drain: 50 * time.Millisecond, Workers: time.Second}, slog.Default())
+
+	ln := listen(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() { runErr <- srv.Serve(ctx, ln) }()
+	go http.Get("http://" + ln.Addr().String())
+
+	<-started
+	cancel()
+
+	select {
+	case err := <-runErr:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("server did not stop after shutdown timeout")
+	}
+	assert.False(t, finished.Load())
+}
+
+func TestServer_ServesDuringShutdownDelay(t *testing.T) {
+	srv := New(&http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	})}, Timeouts{Delay: 200 * time.Millisecond, Drain: time.Second, Workers: time.Second}, slog.Default())
+	shuttingDown := make(chan struct{})
+	srv.OnShutdown(func() { close(shuttingDown) })
+
+	ln := listen(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() { runErr <- srv.Serve(ctx, ln) }()
+	cancel()
+	<-shuttingDown
+
+	resp, err := http.Get("http://" + ln.Addr().String())
+	require.NoError(t, err, "requests are still served during the delay")
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "ok", string(body))
+	require.NoError(t, <-runErr)
+}
+
+func TestServer_WorkersGetTheirOwnTimeout(t *testing.T) {
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	})
+	srv := New(&http.Server{Handler: handler}, Timeouts{Drain: 50 * time.Millisecond, Workers: time.Second}, slog.Default())
+	var stopped atomic.Bool
+	srv.AddWorker("slow", func(ctx context.Context) {
+		<-ctx.Done()
+		time.Sleep(100 * time.Millisecond)
+		stopped.Store(true)
+	})
+
+	ln := listen(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() { runErr <- srv.Serve(ctx, ln) }()
+	go http.Get("http://" + ln.Addr().String())
+	<-started
+	cancel()
+
+	require.NoError(t, <-runErr)
+	assert.True(t, stopped.Load(), "a drain that times out does not use up the workers' time")
+}
@@ -0,0 +1,50 @@
+// Package timezone carries the time zone of a request, in which relative
+// dates such as "today" in filter queries are resolved. The zone database is
+// embedded so IANA names work on hosts without one.
+package timezone
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	_ "time/tzdata"
+)
+
+// ErrUnknown is returned by Load for names that are not IANA time zones.
+var ErrUnknown = errors.New("unknown time zone")
+
+// Load returns the location named by an IANA time zone name such as
+// "Asia/Bangkok" or "UTC". Unlike time.LoadLocation it refuses "" and
+// "Local", which would depend on the server.
+func Load(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == "Local" {
+		return nil, ErrUnknown
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, ErrUnknown
+	}
+	return loc, nil
+}
+
+type ctxKey struct{}
+
+// NewContext returns a copy of ctx carrying loc.
+func NewContext(ctx context.Context, loc *time.Location) context.Context {
+	return context.WithValue(ctx, ctxKey{}, loc)
+}
+
+// FromContext returns the location stored in ctx, or UTC.
+func FromContext(ctx context.Context) *time.Location {
+	if loc, ok := ctx.Value(ctxKey{}).(*time.Location); ok {
+		return loc
+	}
+	return time.UTC
+}
+
+// Now returns the current time in the location stored in ctx.
+func Now(ctx context.Context) time.Time {
+	return time.Now().In(FromContext(ctx))
+}
@@ -0,0 +1,30 @@
+package timezone
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	loc, err := Load("Asia/Bangkok")
+	require.NoError(t, err)
+	assert.Equal(t, "Asia/Bangkok", loc.String())
+
+	for _, name := range []string{"", "Local", "Mars/Olympus", "../etc/passwd"} {
+		_, err := Load(name)
+		assert.ErrorIs(t, err, ErrUnknown, name)
+	}
+}
+
+func TestContext(t *testing.T) {
+	assert.Equal(t, time.UTC, FromContext(context.Background()))
+
+	loc, err := Load("America/New_York")
+	require.NoError(t, err)
+	assert.Equal(t, loc, FromContext(NewContext(context.Background(), loc)))
+	assert.Equal(t, loc, Now(NewContext(context.Background(), loc)).Location())
+}
@@ -0,0 +1,120 @@
+package todotxt
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"todo-app/domain"
+)
+
+// Tasks map onto todos as follows, in both directions:
+//
+//   - x marks COMPLETED todos, the others are IN_PROGRESS
+//   - the creation date is created_at and the completion date completed_at
+//   - the first +project is the project
+//   - @contexts are tags
+//   - due:YYYY-MM-DD is the due date
+//   - the priority is a pri:A tag, the key todo.txt keeps it under once a
+//     task is done
+//   - other key:value tags are tags of the same form
+//
+// The title is the description without the tags mapped to fields. Todo
+// descriptions and images have no place in todo.txt and are not written.
+
+var priorityTag = regexp.MustCompile(`^pri:([A-Z])$`)
+
+// ToTodo returns the todo t describes.
+func ToTodo(t Task) domain.Todo {
+	todo := domain.Todo{Status: "IN_PROGRESS", CreatedAt: t.Created}
+	if t.Done {
+		todo.Status = "COMPLETED"
+		if !t.Completed.IsZero() {
+			completed := t.Completed
+			todo.CompletedAt = &completed
+		}
+	}
+	if len(t.Projects) > 0 {
+		todo.Project = t.Projects[0]
+	}
+	addTag := func(tag string) {
+		if !slices.Contains(todo.Tags, tag) {
+			todo.Tags = append(todo.Tags, tag)
+		}
+	}
+	if t.Priority != 0 {
+		addTag("pri:" + string(t.Priority))
+	}
+
+	var title []string
+	project := false
+	for _, word := range strings.Fields(t.Description) {
+		switch {
+		case !project && len(t.Projects) > 0 && word == "+"+t.Projects[0]:
+			project = true
+		case len(word) > 1 && word[0] == '@':
+			addTag(word[1:])
+		case extraPattern.MatchString(word):
+			key, value, _ := strings.Cut(word, ":")
+			if due, err := time.Parse(time.DateOnly, value); key == "due" && err == nil && todo.DueDate == nil {
+				todo.DueDate = &due
+			} else {
+				addTag(word)
+			}
+		default:
+			title = append(title, word)
+		}
+	}
+	todo.Title = strings.Join(title, " ")
+	return todo
+}
+
+// FromTodo returns the task describing todo.
+func FromTodo(todo domain.Todo) Task {
+	t := Task{Done: todo.Status == "COMPLETED"}
+	if !todo.CreatedAt.IsZero() {
+		t.Created = day(todo.CreatedAt)
+	}
+	if t.Done && todo.CompletedAt != nil {
+		t.Completed = day(*todo.CompletedAt)
+	}
+
+	words := strings.Fields(todo.Title)
+	if todo.Project != "" {
+		project := word(todo.Project)
+		t.Projects = append(t.Projects, project)
+		words = append(words, "+"+project)
+	}
+	for _, tag := range todo.Tags {
+		if m := priorityTag.FindStringSubmatch(tag); m != nil && !t.Done && t.Priority == 0 {
+			t.Priority = m[1][0]
+			continue
+		}
+		if m := extraPattern.FindStringSubmatch(tag); m != nil {
+			t.Extras = append(t.Extras, Extra{Key: m[1], Value: m[2]})
+			words = append(words, tag)
+			continue
+		}
+		context := word(tag)
+		t.Contexts = append(t.Contexts, context)
+		words = append(words, "@"+context)
+	}
+	if todo.DueDate != nil {
+		due := Extra{Key: "due", Value: todo.DueDate.UTC().Format(time.DateOnly)}
+		t.Extras = append(t.Extras, due)
+		words = append(words, due.Key+":"+due.Value)
+	}
+	t.Description = strings.Join(words, " ")
+	return t
+}
+
+func day(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// word joins the words of s with underscores, as tags cannot contain
+// spaces.
+func word(s string) string {
+	return strings.Join(strings.Fields(s), "_")
+}
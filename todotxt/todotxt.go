@@ -0,0 +1,131 @@
+// Package todotxt reads and writes the todo.txt format, one task per line:
+//
+//	x 2026-10-16 2026-10-01 Ship release +Launch @backend due:2026-10-20
+//	(A) 2026-10-02 Call the bank @phone
+//
+// A task starts with x if it is done, then the completion date and the
+// creation date, or with a (A) to (Z) priority and the creation date; every
+// part is optional. The description may contain +project, @context and
+// key:value tags. See https://github.com/todotxt/todo.txt for the format.
+package todotxt
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Task is one line of a todo.txt file.
+type Task struct {
+	Done bool
+	// Priority is 'A' to 'Z', or 0 without a priority.
+	Priority byte
+	// Completed and Created are zero when absent.
+	Completed time.Time
+	Created   time.Time
+	// Description is the rest of the line, tags included.
+	Description string
+	Projects    []string
+	Contexts    []string
+	Extras      []Extra
+}
+
+// Extra is a key:value tag.
+type Extra struct {
+	Key   string
+	Value string
+}
+
+// extraPattern matches key:value tags. Keys start with a letter and values
+// may not start with a slash, so URLs and times stay plain words.
+var extraPattern = regexp.MustCompile(`^([A-Za-z][\w-]*):([^\s:/][^\s:]*)$`)
+
+// Parse parses one line. It never fails: whatever does not fit the format
+// is part of the description.
+func Parse(line string) Task {
+	var t Task
+	rest := strings.TrimSpace(line)
+	if after, ok := strings.CutPrefix(rest, "x "); ok {
+		t.Done = true
+		rest = strings.TrimLeft(after, " ")
+		if t.Completed, rest = leadingDate(rest); !t.Completed.IsZero() {
+			t.Created, rest = leadingDate(rest)
+		}
+	} else {
+		if len(rest) >= 4 && rest[0] == '(' && rest[1] >= 'A' && rest[1] <= 'Z' && rest[2] == ')' && rest[3] == ' ' {
+			t.Priority = rest[1]
+			rest = strings.TrimLeft(rest[4:], " ")
+		}
+		t.Created, rest = leadingDate(rest)
+	}
+
+	t.Description = rest
+	for _, word := range strings.Fields(rest) {
+		switch {
+		case len(word) > 1 && word[0] == '+':
+			t.Projects = append(t.Projects, word[1:])
+		case len(word) > 1 && word[0] == '@':
+			t.Contexts = append(t.Contexts, word[1:])
+		default:
+			if m := extraPattern.FindStringSubmatch(word); m != nil {
+				t.Extras = append(t.Extras, Extra{Key: m[1], Value: m[2]})
+			}
+		}
+	}
+	return t
+}
+
+// leadingDate splits a YYYY-MM-DD date followed by a space off s.
+func leadingDate(s string) (time.Time, string) {
+	word, rest, _ := strings.Cut(s, " ")
+	date, err := time.Parse(time.DateOnly, word)
+	if err != nil {
+		return time.Time{}, s
+	}
+	return date, strings.TrimLeft(rest, " ")
+}
+
+// String formats t as a todo.txt line. The completion date is only written
+// for done tasks and the priority only for open ones, as the format
+// requires.
+func (t Task) String() string {
+	var parts []string
+	if t.Done {
+		parts = append(parts, "x")
+		if !t.Completed.IsZero() {
+			parts = append(parts, t.Completed.Format(time.DateOnly))
+		}
+	} else if t.Priority != 0 {
+		parts = append(parts, "("+string(t.Priority)+")")
+	}
+	// A creation date without a completion date would read as one.
+	if !t.Created.IsZero() && (!t.Done || !t.Completed.IsZero()) {
+		parts = append(parts, t.Created.Format(time.DateOnly))
+	}
+	if t.Description != "" {
+		parts = append(parts, t.Description)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Line is a task read from a file with its 1-based line number.
+type Line struct {
+	Number int
+	Task   Task
+}
+
+// Read parses the tasks of a todo.txt file, skipping blank lines.
+func Read(r io.Reader) ([]Line, error) {
+	scanner := bufio.NewScanner(r)
+	var lines []Line
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimPrefix(scanner.Text(), "\ufeff")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		lines = append(lines, Line{Number: n, Task: Parse(text)})
+	}
+	return lines, scanner.Err()
+}
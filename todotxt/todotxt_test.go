@@ -0,0 +1,124 @@
+package todotxt
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"todo-app/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		line string
+		want Task
+	}{
+		{"Call mom", Task{Description: "Call mom"}},
+		{
+			"(A) 2026-10-01 Call the bank @phone +Finance due:2026-10-20",
+			Task{
+				Priority: 'A', Created: date(2026, 10, 1),
+				Description: "Call the bank @phone +Finance due:2026-10-20",
+				Projects:    []string{"Finance"}, Contexts: []string{"phone"},
+				Extras: []Extra{{"due", "2026-10-20"}},
+			},
+		},
+		{
+			"x 2026-10-16 2026-10-01 Ship release pri:B",
+			Task{
+				Done: true, Completed: date(2026, 10, 16), Created: date(2026, 10, 1),
+				Description: "Ship release pri:B", Extras: []Extra{{"pri", "B"}},
+			},
+		},
+		{"x 2026-10-16 Done without a creation date", Task{Done: true, Completed: date(2026, 10, 16), Description: "Done without a creation date"}},
+		{"xylophone lessons", Task{Description: "xylophone lessons"}},
+		{"(a) lower case is no priority", Task{Description: "(a) lower case is no priority"}},
+		{"Read https://example.com at 12:30 + @ too", Task{Description: "Read https://example.com at 12:30 + @ too"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			assert.Equal(t, tt.want, Parse(tt.line))
+		})
+	}
+}
+
+func TestTask_String(t *testing.T) {
+	lines := []string{
+		"Call mom",
+		"(A) 2026-10-01 Call the bank @phone +Finance due:2026-10-20",
+		"x 2026-10-16 2026-10-01 Ship release pri:B",
+		"x 2026-10-16 Done without a creation date",
+	}
+	for _, line := range lines {
+		assert.Equal(t, line, Parse(line).String())
+	}
+	assert.Equal(t, "x Done", Task{Done: true, Priority: 'A', Created: date(2026, 10, 1), Description: "Done"}.String(),
+		"done tasks drop the priority, and the creation date without a completion date")
+}
+
+func TestRead(t *testing.T) {
+	lines, err := Read(strings.NewReader("\ufeffFirst\n\n  \r\nSecond\r\n"))
+
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+	assert.Equal(t, 1, lines[0].Number)
+	assert.Equal(t, "First", lines[0].Task.Description)
+	assert.Equal(t, 4, lines[1].Number)
+	assert.Equal(t, "Second", lines[1].Task.Description)
+}
+
+func TestToTodo(t *testing.T) {
+	todo := ToTodo(Parse("(A) 2026-10-01 Call +Finance the bank +Home @phone @phone due:2026-10-20 ref:42"))
+
+	due := date(2026, 10, 20)
+	assert.Equal(t, domain.Todo{
+		Title:     "Call the bank +Home",
+		Status:    "IN_PROGRESS",
+		Project:   "Finance",
+		Tags:      []string{"pri:A", "phone", "ref:42"},
+		DueDate:   &due,
+		CreatedAt: date(2026, 10, 1),
+	}, todo)
+
+	done := ToTodo(Parse("x 2026-10-16 2026-10-01 Ship release due:soon"))
+	assert.Equal(t, "COMPLETED", done.Status)
+	require.NotNil(t, done.CompletedAt)
+	assert.Equal(t, date(2026, 10, 16), *done.CompletedAt)
+	assert.Equal(t, []string{"due:soon"}, done.Tags, "unparsable due dates are kept as tags")
+	assert.Nil(t, done.DueDate)
+}
+
+func TestFromTodo(t *testing.T) {
+	due := time.Date(2026, 10, 20, 15, 0, 0, 0, time.UTC)
+	todo := domain.Todo{
+		Title:       "Call\nthe bank",
+		Description: "not part of todo.txt",
+		Status:      "IN_PROGRESS",
+		Project:     "Home Finance",
+		Tags:        []string{"pri:A", "phone calls", "ref:42"},
+		DueDate:     &due,
+		CreatedAt:   time.Date(2026, 10, 1, 23, 30, 0, 0, time.FixedZone("", -2*3600)),
+	}
+
+	assert.Equal(t, "(A) 2026-10-02 Call the bank +Home_Finance @phone_calls ref:42 due:2026-10-20", FromTodo(todo).String())
+
+	todo.Status = "COMPLETED"
+	completed := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+	todo.CompletedAt = &completed
+	assert.Equal(t, "x 2026-10-16 2026-10-02 Call the bank +Home_Finance pri:A @phone_calls ref:42 due:2026-10-20", FromTodo(todo).String())
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, line := range []string{
+		"(B) 2026-10-01 Write notes +Launch @docs due:2026-10-20",
+		"x 2026-10-16 2026-10-01 Ship release +Launch pri:A @backend",
+	} {
+		assert.Equal(t, line, FromTodo(ToTodo(Parse(line))).String())
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"todo-app/config"
+	"todo-app/domain"
+	"todo-app/export"
+	"todo-app/i18n"
+	"todo-app/importer"
+	"todo-app/repository"
+	"todo-app/usecase"
+)
+
+const todoTxtUsage = `Usage:
+  todo-app [flags] todotxt export [file]
+  todo-app [flags] todotxt import [-dry-run] [file]
+
+export writes every todo to file, or to stdout, in todo.txt format.
+import creates todos from file, or from stdin, and reports skipped lines.
+`
+
+// errTodoTxtUsage reports a malformed todotxt command line.
+var errTodoTxtUsage = errors.New("invalid todotxt command")
+
+// runTodoTxt imports or exports todo.txt files against the configured
+// storage backend, going through the todo usecase like the HTTP API. Only
+// errors are logged, to stderr, so logs never mix with an export on stdout
+// and skipped lines are reported once.
+func runTodoTxt(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errTodoTxtUsage
+	}
+	cmd, args := args[0], args[1:]
+	flags := flag.NewFlagSet("todotxt "+cmd, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	dryRun := false
+	if cmd == "import" {
+		flags.BoolVar(&dryRun, "dry-run", false, "only report what would be created")
+	}
+	if err := flags.Parse(args); err != nil || flags.NArg() > 1 || (cmd != "import" && cmd != "export") {
+		return errTodoTxtUsage
+	}
+	path := flags.Arg(0)
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	backend, err := repository.Open(cfg.Database, logger)
+	if err != nil {
+		return fmt.Errorf("open storage backend: %w", err)
+	}
+	defer backend.Close()
+	todos := usecase.NewTodoUsecase(backend.Todos, nil, logger)
+	ctx := context.Background()
+
+	if cmd == "export" {
+		return exportTodoTxt(ctx, todos, path)
+	}
+	return importTodoTxt(ctx, todos, path, dryRun)
+}
+
+func exportTodoTxt(ctx context.Context, todos domain.TodoUsecase, path string) (err error) {
+	out := os.Stdout
+	if path != "" && path != "-" {
+		if out, err = os.Create(path); err != nil {
+			return err
+		}
+		defer func() {
+			if closeErr := out.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+	}
+	format, err := export.Lookup("todotxt")
+	if err != nil {
+		return err
+	}
+	w := format.NewWriter(out, nil)
+	count := 0
+	err = todos.Export(ctx, domain.ListOptions{SortBy: "date"}, func(todo domain.Todo) error {
+		count++
+		return w.Write(todo)
+	})
+	if err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d todos\n", count)
+	return nil
+}
+
+func importTodoTxt(ctx context.Context, todos domain.TodoUsecase, path string, dryRun bool) error {
+	in := os.Stdin
+	if path != "" && path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	file, err := importer.ReadTodoTxt(in)
+	if err != nil {
+		return err
+	}
+	records, err := todos.Import(ctx, file.Records, dryRun)
+	if err != nil {
+		return err
+	}
+
+	created := 0
+	for _, record := range records {
+		if record.Err == nil {
+			created++
+			continue
+		}
+		var validationErr *domain.ValidationError
+		if !errors.As(record.Err, &validationErr) {
+			fmt.Fprintf(os.Stderr, "line %d: %v\n", record.Row, record.Err)
+			continue
+		}
+		var messages []string
+		for _, v := range i18n.Translate(i18n.Default(), validationErr.Violations) {
+			messages = append(messages, v.Message)
+		}
+		fmt.Fprintf(os.Stderr, "line %d: %s\n", record.Row, strings.Join(messages, "; "))
+	}
+	verb := "Imported"
+	if dryRun {
+		verb = "Would import"
+	}
+	fmt.Fprintf(os.Stderr, "%s %d todos, skipped %d lines\n", verb, created, len(records)-created)
+	return nil
+}
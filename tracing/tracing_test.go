@@ -0,0 +1,99 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"todo-app/domain"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/gorm"
+)
+
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestGormPlugin(t *testing.T) {
+	recorder := setupRecorder(t)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&domain.Todo{}))
+	require.NoError(t, db.Use(GormPlugin()))
+
+	ctx, parent := Tracer().Start(context.Background(), "parent")
+	require.NoError(t, db.WithContext(ctx).Create(&domain.Todo{Title: "Traced", Status: "IN_PROGRESS"}).Error)
+	var todo domain.Todo
+	err = db.WithContext(ctx).First(&todo, "title = ?", "missing").Error
+	parent.End()
+
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+
+	create, query := spans[0], spans[1]
+	assert.Equal(t, "gorm.Create", create.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), create.Parent().SpanID())
+	attrs := map[string]string{}
+	for _, kv := range create.Attributes() {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	assert.Equal(t, "sqlite", attrs["db.system"])
+	assert.Equal(t, "todos", attrs["db.collection.name"])
+	assert.Contains(t, attrs["db.query.text"], "INSERT INTO")
+	assert.NotContains(t, attrs["db.query.text"], "Traced")
+
+	assert.Equal(t, "gorm.Query", query.Name())
+	assert.Equal(t, codes.Unset, query.Status().Code, "record not found is not a span error")
+}
+
+func TestFinish(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	_, ok := Tracer().Start(context.Background(), "ok")
+	Finish(ok, nil)
+	_, notFound := Tracer().Start(context.Background(), "not found")
+	Finish(notFound, domain.ErrNotFound)
+	_, failed := Tracer().Start(context.Background(), "failed")
+	Finish(failed, errors.New("boom"))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+	assert.Len(t, spans[1].Events(), 1)
+	assert.Equal(t, codes.Error, spans[2].Status().Code)
+}
+
+func TestLogHandler(t *testing.T) {
+	setupRecorder(t)
+	var buf bytes.Buffer
+	logger := slog.New(NewLogHandler(slog.NewJSONHandler(&buf, nil)))
+
+	ctx, span := Tracer().Start(context.Background(), "request")
+	logger.InfoContext(ctx, "inside span")
+	span.End()
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, span.SpanContext().TraceID().String(), record["trace_id"])
+	assert.Equal(t, span.SpanContext().SpanID().String(), record["span_id"])
+
+	buf.Reset()
+	logger.Info("outside span")
+	assert.NotContains(t, buf.String(), "trace_id")
+}
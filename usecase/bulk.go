@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"todo-app/domain"
+	"todo-app/tracing"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// errBulkFailed stops an atomic batch's transaction after the failing
+// operation's result has been recorded.
+var errBulkFailed = errors.New("bulk operation failed")
+
+func (u *todoUsecase) Bulk(ctx context.Context, ops []domain.BulkOperation, atomic bool) (_ []domain.BulkResult, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "todoUsecase.Bulk", trace.WithAttributes(
+		attribute.Int("bulk.operations", len(ops)),
+		attribute.Bool("bulk.atomic", atomic),
+	))
+	defer func() { tracing.Finish(span, err) }()
+
+	results := make([]domain.BulkResult, len(ops))
+	if !atomic {
+		for i, op := range ops {
+			var events []domain.DomainEvent
+			results[i], events = u.apply(ctx, u.repo, op)
+			u.publish(ctx, events...)
+		}
+		return results, nil
+	}
+
+	failed := -1
+	var events []domain.DomainEvent
+	err = u.repo.Transaction(ctx, func(tx domain.TodoRepository) error {
+		for i, op := range ops {
+			var opEvents []domain.DomainEvent
+			results[i], opEvents = u.apply(ctx, tx, op)
+			if results[i].Err != nil {
+				failed = i
+				return errBulkFailed
+			}
+			events = append(events, opEvents...)
+		}
+		return nil
+	})
+	if failed < 0 {
+		if err != nil {
+			return nil, err // commit failed, already logged in repository
+		}
+		u.publish(ctx, events...)
+		return results, nil
+	}
+
+	for i := range results {
+		if i == failed {
+			continue
+		}
+		results[i] = domain.BulkResult{Op: ops[i].Op, ID: ops[i].ID, Err: domain.ErrBulkAborted}
+	}
+	u.log(ctx).WarnContext(ctx, "Atomic bulk request rolled back", "failed_index", failed, "error", results[failed].Err)
+	return results, fmt.Errorf("%w: operation %d: %v", domain.ErrBulkAborted, failed, results[failed].Err)
+}
+
+// apply runs a single bulk operation against repo and returns its result
+// with the events to publish once it is committed. The todo is copied so a
+// failed operation never leaves partial changes in the caller's request.
+func (u *todoUsecase) apply(ctx context.Context, repo domain.TodoRepository, op domain.BulkOperation) (domain.BulkResult, []domain.DomainEvent) {
+	result := domain.BulkResult{Op: op.Op, ID: op.ID}
+	if err := validateBulkOperation(op); err != nil {
+		result.Err = err
+		return result, nil
+	}
+
+	var events []domain.DomainEvent
+	switch op.Op {
+	case domain.BulkCreate:
+		todo := *op.Todo
+		if events, result.Err = u.create(ctx, repo, &todo); result.Err == nil {
+			result.ID, result.Todo = todo.ID, &todo
+		}
+	case domain.BulkUpdate:
+		todo := *op.Todo
+		todo.ID = op.ID
+		if events, result.Err = u.update(ctx, repo, &todo); result.Err == nil {
+			result.Todo = &todo
+		}
+	case domain.BulkDelete:
+		events, result.Err = u.delete(ctx, repo, op.ID)
+	}
+	return result, events
+}
+
+func validateBulkOperation(op domain.BulkOperation) error {
+	var violations []domain.FieldViolation
+	switch op.Op {
+	case domain.BulkCreate:
+		if op.Todo == nil {
+			violations = append(violations, violation("todo", "required", ""))
+		}
+	case domain.BulkUpdate:
+		if op.ID == uuid.Nil {
+			violations = append(violations, violation("id", "required", ""))
+		}
+		if op.Todo == nil {
+			violations = append(violations, violation("todo", "required", ""))
+		}
+	case domain.BulkDelete:
+		// delete reports a missing ID itself
+	default:
+		violations = append(violations, violation("op", "oneof", "create update delete"))
+	}
+	if len(violations) > 0 {
+		return domain.NewValidationError(violations...)
+	}
+	return nil
+}
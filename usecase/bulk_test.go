@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"todo-app/domain"
+	"todo-app/domain/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTodoUsecase_Bulk(t *testing.T) {
+	existing := &domain.Todo{ID: uuid.New(), Title: "Existing", Status: "IN_PROGRESS"}
+	missing := uuid.New()
+	ops := []domain.BulkOperation{
+		{Op: domain.BulkCreate, Todo: &domain.Todo{Title: "New", Status: "IN_PROGRESS"}},
+		{Op: domain.BulkUpdate, ID: existing.ID, Todo: &domain.Todo{Title: "Renamed", Status: "COMPLETED"}},
+		{Op: domain.BulkDelete, ID: missing},
+		{Op: "archive"},
+	}
+
+	t.Run("best effort applies every valid operation", func(t *testing.T) {
+		mockRepo := new(mocks.MockTodoRepository)
+		usecase := NewTodoUsecase(mockRepo, nil, slog.Default())
+		mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil).Once()
+		mockRepo.On("FindByID", mock.Anything, existing.ID).Return(existing, nil).Once()
+		mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil).Once()
+		mockRepo.On("FindByID", mock.Anything, missing).Return(nil, domain.ErrNotFound).Once()
+
+		results, err := usecase.Bulk(context.Background(), ops, false)
+
+		require.NoError(t, err)
+		require.Len(t, results, 4)
+		assert.NoError(t, results[0].Err)
+		assert.Equal(t, "New", results[0].Todo.Title)
+		assert.NoError(t, results[1].Err)
+		assert.Equal(t, existing.ID, results[1].Todo.ID)
+		assert.ErrorIs(t, results[2].Err, domain.ErrNotFound)
+		var validationErr *domain.ValidationError
+		assert.ErrorAs(t, results[3].Err, &validationErr)
+		assert.Equal(t, "op", validationErr.Violations[0].Field)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("atomic rolls back on the first failure", func(t *testing.T) {
+		mockRepo, bus := new(mocks.MockTodoRepository), &recordingBus{}
+		usecase := NewTodoUsecase(mockRepo, bus, slog.Default())
+		mockRepo.On("Transaction", mock.Anything, mock.Anything).Return(nil).Once()
+		mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil).Once()
+		mockRepo.On("FindByID", mock.Anything, existing.ID).Return(existing, nil).Once()
+		mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil).Once()
+		mockRepo.On("FindByID", mock.Anything, missing).Return(nil, domain.ErrNotFound).Once()
+
+		results, err := usecase.Bulk(context.Background(), ops, true)
+
+		assert.ErrorIs(t, err, domain.ErrBulkAborted)
+		require.Len(t, results, 4)
+		assert.ErrorIs(t, results[0].Err, domain.ErrBulkAborted)
+		assert.Nil(t, results[0].Todo)
+		assert.ErrorIs(t, results[1].Err, domain.ErrBulkAborted)
+		assert.ErrorIs(t, results[2].Err, domain.ErrNotFound)
+		assert.ErrorIs(t, results[3].Err, domain.ErrBulkAborted, "operations after the failure are not attempted")
+		assert.Empty(t, bus.events, "rolled back changes are not published")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("atomic success", func(t *testing.T) {
+		mockRepo, bus := new(mocks.MockTodoRepository), &recordingBus{}
+		usecase := NewTodoUsecase(mockRepo, bus, slog.Default())
+		mockRepo.On("Transaction", mock.Anything, mock.Anything).Return(nil).Once()
+		mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil).Once()
+
+		results, err := usecase.Bulk(context.Background(), ops[:1], true)
+
+		require.NoError(t, err)
+		assert.NoError(t, results[0].Err)
+		require.Len(t, bus.events, 1)
+		assert.IsType(t, domain.TodoCreatedEvent{}, bus.events[0])
+	})
+}
@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"time"
+	"todo-app/domain"
+	"todo-app/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// importBatchSize is the number of todos Import stores per insert.
+const importBatchSize = 100
+
+func (u *todoUsecase) Import(ctx context.Context, records []domain.ImportRecord, dryRun bool) (_ []domain.ImportRecord, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "todoUsecase.Import", trace.WithAttributes(
+		attribute.Int("import.records", len(records)),
+		attribute.Bool("import.dry_run", dryRun),
+	))
+	defer func() { tracing.Finish(span, err) }()
+
+	results := make([]domain.ImportRecord, len(records))
+	var valid []domain.Todo
+	var rows []int // index in results of each valid todo
+	now := time.Now().UTC()
+	for i, record := range records {
+		results[i] = record
+		if record.Err != nil {
+			continue
+		}
+		todo := &results[i].Todo
+		normalizeDueDate(todo)
+		trackCompletion(todo, nil, now)
+		if err := u.validateTodo(ctx, todo); err != nil {
+			results[i].Err = err
+			continue
+		}
+		valid = append(valid, *todo)
+		rows = append(rows, i)
+	}
+	failed := len(records) - len(valid)
+	span.SetAttributes(attribute.Int("import.failed", failed))
+	if failed > 0 {
+		u.log(ctx).WarnContext(ctx, "Import rows rejected", "failed", failed, "total", len(records))
+	}
+	if dryRun || len(valid) == 0 {
+		return results, nil
+	}
+
+	err = u.repo.Transaction(ctx, func(tx domain.TodoRepository) error {
+		for start := 0; start < len(valid); start += importBatchSize {
+			if err := tx.CreateBatch(ctx, valid[start:min(start+importBatchSize, len(valid))]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err // Error already logged in repository
+	}
+
+	at := time.Now().UTC()
+	events := make([]domain.DomainEvent, len(valid))
+	for i, todo := range valid {
+		results[rows[i]].Todo = todo
+		events[i] = domain.TodoCreatedEvent{Todo: todo, OccurredAt: at}
+	}
+	u.publish(ctx, events...)
+	u.log(ctx).InfoContext(ctx, "Todos imported", "created", len(valid), "failed", failed)
+	return results, nil
+}
@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"testing"
+	"todo-app/domain"
+	"todo-app/domain/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTodoUsecase_Import(t *testing.T) {
+	unreadable := domain.NewValidationError(violation("due_date", "datetime", ""))
+	records := []domain.ImportRecord{
+		{Row: 2, Todo: domain.Todo{Title: "Ship release", Status: "IN_PROGRESS"}},
+		{Row: 3, Todo: domain.Todo{Status: "IN_PROGRESS"}},
+		{Row: 4, Err: unreadable},
+		{Row: 5, Todo: domain.Todo{Title: "Write notes", Status: "DONE"}},
+	}
+
+	t.Run("creates the valid rows and reports the others", func(t *testing.T) {
+		mockRepo, bus := new(mocks.MockTodoRepository), &recordingBus{}
+		usecase := NewTodoUsecase(mockRepo, bus, slog.Default())
+		mockRepo.On("Transaction", mock.Anything, mock.Anything).Return(nil).Once()
+		mockRepo.On("CreateBatch", mock.Anything, mock.MatchedBy(func(todos []domain.Todo) bool {
+			return len(todos) == 1 && todos[0].Title == "Ship release"
+		})).Return(nil).Once()
+
+		results, err := usecase.Import(context.Background(), records, false)
+
+		require.NoError(t, err)
+		require.Len(t, results, 4)
+		assert.NoError(t, results[0].Err)
+		assert.NotEqual(t, uuid.Nil, results[0].Todo.ID, "created todos carry their ID")
+		var validationErr *domain.ValidationError
+		require.ErrorAs(t, results[1].Err, &validationErr)
+		assert.Equal(t, "title", validationErr.Violations[0].Field)
+		assert.Same(t, unreadable, results[2].Err)
+		require.ErrorAs(t, results[3].Err, &validationErr)
+		assert.Equal(t, "status", validationErr.Violations[0].Field)
+		require.Len(t, bus.events, 1)
+		assert.IsType(t, domain.TodoCreatedEvent{}, bus.events[0])
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("dry run stores nothing", func(t *testing.T) {
+		mockRepo := new(mocks.MockTodoRepository)
+		usecase := NewTodoUsecase(mockRepo, nil, slog.Default())
+
+		results, err := usecase.Import(context.Background(), records, true)
+
+		require.NoError(t, err)
+		assert.NoError(t, results[0].Err)
+		assert.Equal(t, uuid.Nil, results[0].Todo.ID)
+		mockRepo.AssertNotCalled(t, "Transaction", mock.Anything, mock.Anything)
+	})
+
+	t.Run("inserts in batches", func(t *testing.T) {
+		mockRepo := new(mocks.MockTodoRepository)
+		usecase := NewTodoUsecase(mockRepo, nil, slog.Default())
+		many := make([]domain.ImportRecord, importBatchSize+1)
+		for i := range many {
+			many[i] = domain.ImportRecord{Row: i + 2, Todo: domain.Todo{Title: fmt.Sprintf("Todo %d", i), Status: "IN_PROGRESS"}}
+		}
+		mockRepo.On("Transaction", mock.Anything, mock.Anything).Return(nil).Once()
+		mockRepo.On("CreateBatch", mock.Anything, mock.MatchedBy(func(todos []domain.Todo) bool { return len(todos) == importBatchSize })).Return(nil).Once()
+		mockRepo.On("CreateBatch", mock.Anything, mock.MatchedBy(func(todos []domain.Todo) bool { return len(todos) == 1 })).Return(nil).Once()
+
+		_, err := usecase.Import(context.Background(), many, false)
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		mockRepo, bus := new(mocks.MockTodoRepository), &recordingBus{}
+		usecase := NewTodoUsecase(mockRepo, bus, slog.Default())
+		mockRepo.On("Transaction", mock.Anything, mock.Anything).Return(nil).Once()
+		mockRepo.On("CreateBatch", mock.Anything, mock.Anything).Return(domain.ErrDatabaseOperation).Once()
+
+		_, err := usecase.Import(context.Background(), records, false)
+
+		assert.ErrorIs(t, err, domain.ErrDatabaseOperation)
+		assert.Empty(t, bus.events)
+	})
+}
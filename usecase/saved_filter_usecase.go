@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"todo-app/domain"
+	"todo-app/logging"
+	"todo-app/querylang"
+	"todo-app/timezone"
+	"todo-app/tracing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// smartLists are the built-in saved filters every user sees. Their queries
+// use relative dates, so they follow the calendar of the client's time
+// zone.
+var smartLists = []domain.SavedFilter{
+	smartList("today", "Today", "due:today status:IN_PROGRESS", ""),
+	smartList("overdue", "Overdue", "due<today status:IN_PROGRESS", "due"),
+	smartList("upcoming", "Upcoming 7 days", "due>=today due<today+7d status:IN_PROGRESS", "due"),
+	smartList("completed-this-week", "Completed this week", "status:COMPLETED completed>=startofweek", ""),
+}
+
+func smartList(key, name, query, sort string) domain.SavedFilter {
+	return domain.SavedFilter{ID: domain.SmartListID(key), Key: key, Name: name, Query: query, Sort: sort, BuiltIn: true}
+}
+
+// findSmartList returns the smart list with id, or nil.
+func findSmartList(id uuid.UUID) *domain.SavedFilter {
+	for _, list := range smartLists {
+		if list.ID == id {
+			return &list
+		}
+	}
+	return nil
+}
+
+type savedFilterUsecase struct {
+	repo     domain.SavedFilterRepository
+	todos    domain.TodoUsecase
+	validate *validator.Validate
+	logger   *slog.Logger
+}
+
+// NewSavedFilterUsecase returns the saved filter usecase. Filters are run
+// through todos, so they behave exactly like GET /todos.
+func NewSavedFilterUsecase(repo domain.SavedFilterRepository, todos domain.TodoUsecase, logger *slog.Logger) domain.SavedFilterUsecase {
+	return &savedFilterUsecase{
+		repo:     repo,
+		todos:    todos,
+		validate: newValidator(),
+		logger:   logger,
+	}
+}
+
+func (u *savedFilterUsecase) log(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx, u.logger)
+}
+
+func (u *savedFilterUsecase) Create(ctx context.Context, filter *domain.SavedFilter) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "savedFilterUsecase.Create")
+	defer func() { tracing.Finish(span, err) }()
+
+	filter.Key, filter.BuiltIn = "", false
+	if err := u.validateFilter(ctx, filter); err != nil {
+		return err
+	}
+	return u.repo.Create(ctx, filter)
+}
+
+func (u *savedFilterUsecase) Update(ctx context.Context, filter *domain.SavedFilter) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "savedFilterUsecase.Update",
+		trace.WithAttributes(attribute.String("filter.id", filter.ID.String())))
+	defer func() { tracing.Finish(span, err) }()
+
+	existing, err := u.find(ctx, filter.Owner, filter.ID)
+	if err != nil {
+		return err
+	}
+	if existing.BuiltIn {
+		u.log(ctx).WarnContext(ctx, "Smart lists cannot be changed", "filter_id", filter.ID)
+		return domain.ErrReadOnly
+	}
+	filter.Key, filter.BuiltIn = "", false
+	if err := u.validateFilter(ctx, filter); err != nil {
+		return err
+	}
+	filter.CreatedAt = existing.CreatedAt
+	return u.repo.Update(ctx, filter)
+}
+
+func (u *savedFilterUsecase) Get(ctx context.Context, owner string, id uuid.UUID) (_ *domain.SavedFilter, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "savedFilterUsecase.Get",
+		trace.WithAttributes(attribute.String("filter.id", id.String())))
+	defer func() { tracing.Finish(span, err) }()
+
+	return u.find(ctx, owner, id)
+}
+
+func (u *savedFilterUsecase) List(ctx context.Context, owner string) (_ []domain.SavedFilter, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "savedFilterUsecase.List")
+	defer func() { tracing.Finish(span, err) }()
+
+	owned, err := u.repo.FindByOwner(ctx, owner)
+	if err != nil {
+		return nil, err // Error already logged in repository
+	}
+	return append(append([]domain.SavedFilter{}, smartLists...), owned...), nil
+}
+
+func (u *savedFilterUsecase) Delete(ctx context.Context, owner string, id uuid.UUID) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "savedFilterUsecase.Delete",
+		trace.WithAttributes(attribute.String("filter.id", id.String())))
+	defer func() { tracing.Finish(span, err) }()
+
+	existing, err := u.find(ctx, owner, id)
+	if err != nil {
+		return err
+	}
+	if existing.BuiltIn {
+		u.log(ctx).WarnContext(ctx, "Smart lists cannot be deleted", "filter_id", id)
+		return domain.ErrReadOnly
+	}
+	return u.repo.Delete(ctx, id)
+}
+
+func (u *savedFilterUsecase) Todos(ctx context.Context, owner string, id uuid.UUID) (_ []domain.Todo, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "savedFilterUsecase.Todos",
+		trace.WithAttributes(attribute.String("filter.id", id.String())))
+	defer func() { tracing.Finish(span, err) }()
+
+	filter, err := u.find(ctx, owner, id)
+	if err != nil {
+		return nil, err
+	}
+	return u.todos.List(ctx, domain.ListOptions{SortBy: filter.Sort, Query: filter.Query})
+}
+
+// find returns the smart list or the filter of owner with id. Filters of
+// other owners are reported as not found, so their IDs are not disclosed.
+func (u *savedFilterUsecase) find(ctx context.Context, owner string, id uuid.UUID) (*domain.SavedFilter, error) {
+	if list := findSmartList(id); list != nil {
+		return list, nil
+	}
+	filter, err := u.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err // Error already logged in repository
+	}
+	if filter.Owner != owner {
+		u.log(ctx).WarnContext(ctx, "Saved filter belongs to another user", "filter_id", id)
+		return nil, domain.ErrNotFound
+	}
+	return filter, nil
+}
+
+// validateFilter checks the fields of filter and that its query parses, so
+// a stored filter never fails with a syntax error when it is run.
+func (u *savedFilterUsecase) validateFilter(ctx context.Context, filter *domain.SavedFilter) error {
+	filter.Name = strings.TrimSpace(filter.Name)
+	filter.Query = strings.TrimSpace(filter.Query)
+	if err := toValidationError(u.validate.Struct(filter)); err != nil {
+		u.log(ctx).WarnContext(ctx, "Validation failed for saved filter", "error", err, "filter_id", filter.ID)
+		return err
+	}
+	if _, err := querylang.ParseFilter(filter.Query, timezone.Now(ctx)); err != nil {
+		u.log(ctx).WarnContext(ctx, "Invalid saved filter query", "error", err, "filter_id", filter.ID)
+		return err
+	}
+	return nil
+}
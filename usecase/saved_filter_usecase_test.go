@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+	"todo-app/domain"
+	"todo-app/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSavedFilterUsecase(t *testing.T) {
+	ctx := context.Background()
+	todoRepo := repository.NewMemoryTodoRepo(slog.Default())
+	usecase := NewSavedFilterUsecase(repository.NewMemorySavedFilterRepo(), NewTodoUsecase(todoRepo, nil, slog.Default()), slog.Default())
+
+	today := time.Now().UTC()
+	yesterday := today.AddDate(0, 0, -1)
+	nextWeek := today.AddDate(0, 0, 5)
+	for _, todo := range []*domain.Todo{
+		{Title: "Due today", Status: "IN_PROGRESS", Tags: []string{"backend"}, DueDate: &today},
+		{Title: "Late", Status: "IN_PROGRESS", DueDate: &yesterday},
+		{Title: "Soon", Status: "IN_PROGRESS", Tags: []string{"backend"}, DueDate: &nextWeek},
+		{Title: "Done", Status: "COMPLETED", DueDate: &yesterday, CompletedAt: &today},
+	} {
+		require.NoError(t, todoRepo.Create(ctx, todo))
+	}
+	titles := func(todos []domain.Todo) []string {
+		out := make([]string, len(todos))
+		for i, todo := range todos {
+			out[i] = todo.Title
+		}
+		return out
+	}
+
+	t.Run("smart lists", func(t *testing.T) {
+		for key, want := range map[string][]string{
+			"today":               {"Due today"},
+			"overdue":             {"Late"},
+			"upcoming":            {"Due today", "Soon"},
+			"completed-this-week": {"Done"},
+		} {
+			todos, err := usecase.Todos(ctx, "alice", domain.SmartListID(key))
+			require.NoError(t, err, key)
+			assert.Equal(t, want, titles(todos), key)
+		}
+	})
+
+	t.Run("saved filters belong to their owner", func(t *testing.T) {
+		filter := &domain.SavedFilter{Name: " Backend ", Query: "tag:backend", Sort: "due", Owner: "alice"}
+		require.NoError(t, usecase.Create(ctx, filter))
+		assert.Equal(t, "Backend", filter.Name)
+
+		todos, err := usecase.Todos(ctx, "alice", filter.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Due today", "Soon"}, titles(todos))
+
+		_, err = usecase.Get(ctx, "bob", filter.ID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		assert.ErrorIs(t, usecase.Delete(ctx, "bob", filter.ID), domain.ErrNotFound)
+
+		filters, err := usecase.List(ctx, "alice")
+		require.NoError(t, err)
+		require.Len(t, filters, len(smartLists)+1)
+		assert.True(t, filters[0].BuiltIn)
+		assert.Equal(t, filter.ID, filters[len(filters)-1].ID)
+	})
+
+	t.Run("smart lists are read-only", func(t *testing.T) {
+		id := domain.SmartListID("today")
+
+		assert.ErrorIs(t, usecase.Update(ctx, &domain.SavedFilter{ID: id, Name: "Mine", Owner: "alice"}), domain.ErrReadOnly)
+		assert.ErrorIs(t, usecase.Delete(ctx, "alice", id), domain.ErrReadOnly)
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		err := usecase.Create(ctx, &domain.SavedFilter{Name: "Broken", Query: "due<soon", Owner: "alice"})
+
+		var queryErr *domain.QueryError
+		require.ErrorAs(t, err, &queryErr)
+		assert.Equal(t, domain.QueryInvalidDate, queryErr.Code)
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		err := usecase.Create(ctx, &domain.SavedFilter{Query: "tag:x", Sort: "priority", Owner: "alice"})
+
+		var validationErr *domain.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Len(t, validationErr.Violations, 2)
+	})
+}
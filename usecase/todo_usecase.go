@@ -1,115 +1,425 @@
 package usecase
 
 import (
+	"context"
 	"errors"
-	"fmt"
 	"log/slog"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 	"todo-app/domain"
+	"todo-app/logging"
+	"todo-app/querylang"
+	"todo-app/search"
+	"todo-app/timezone"
+	"todo-app/tracing"
+	"unicode/utf8"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type todoUsecase struct {
 	repo     domain.TodoRepository
+	bus      domain.EventBus
 	validate *validator.Validate
 	logger   *slog.Logger
 }
 
-func NewTodoUsecase(repo domain.TodoRepository, logger *slog.Logger) domain.TodoUsecase {
+// NewTodoUsecase returns the todo usecase. Change events are recorded by
+// repo in its outbox, in the same transaction as each write. Domain events
+// are published to bus once a write is committed; bus may be nil.
+func NewTodoUsecase(repo domain.TodoRepository, bus domain.EventBus, logger *slog.Logger) domain.TodoUsecase {
 	return &todoUsecase{
 		repo:     repo,
-		validate: validator.New(),
+		bus:      bus,
+		validate: newValidator(),
 		logger:   logger,
 	}
 }
 
-func (u *todoUsecase) Create(todo *domain.Todo) error {
-	if err := u.validate.Struct(todo); err != nil {
-		u.logger.Warn("Validation failed for create", "error", err)
-		return fmt.Errorf("%w: %v", domain.ErrValidationFailed, err)
+// log returns the request-scoped logger from ctx, falling back to the
+// usecase's own logger outside of a request.
+func (u *todoUsecase) log(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx, u.logger)
+}
+
+func (u *todoUsecase) Create(ctx context.Context, todo *domain.Todo) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "todoUsecase.Create")
+	defer func() { tracing.Finish(span, err) }()
+
+	events, err := u.create(ctx, u.repo, todo)
+	if err != nil {
+		return err
 	}
+	u.publish(ctx, events...)
+	return nil
+}
 
-	if err := u.repo.Create(todo); err != nil {
-		return err // Error already logged in repository
+// create stores todo and returns the events to publish once the write is
+// committed. update and delete follow the same pattern.
+func (u *todoUsecase) create(ctx context.Context, repo domain.TodoRepository, todo *domain.Todo) ([]domain.DomainEvent, error) {
+	normalizeDueDate(todo)
+	trackCompletion(todo, nil, time.Now().UTC())
+	if err := u.validateTodo(ctx, todo); err != nil {
+		u.log(ctx).WarnContext(ctx, "Validation failed for create", "error", err)
+		return nil, err
 	}
+
+	if err := repo.Create(ctx, todo); err != nil {
+		return nil, err // Error already logged in repository
+	}
+	return []domain.DomainEvent{domain.TodoCreatedEvent{Todo: *todo, OccurredAt: time.Now().UTC()}}, nil
+}
+
+func (u *todoUsecase) Update(ctx context.Context, todo *domain.Todo) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "todoUsecase.Update",
+		trace.WithAttributes(attribute.String("todo.id", todo.ID.String())))
+	defer func() { tracing.Finish(span, err) }()
+
+	events, err := u.update(ctx, u.repo, todo)
+	if err != nil {
+		return err
+	}
+	u.publish(ctx, events...)
 	return nil
 }
 
-func (u *todoUsecase) Update(todo *domain.Todo) error {
-	if err := u.validate.Struct(todo); err != nil {
-		u.logger.Warn("Validation failed for update", "error", err, "todo_id", todo.ID)
-		return fmt.Errorf("%w: %v", domain.ErrValidationFailed, err)
+func (u *todoUsecase) update(ctx context.Context, repo domain.TodoRepository, todo *domain.Todo) ([]domain.DomainEvent, error) {
+	normalizeDueDate(todo)
+	if err := u.validateTodo(ctx, todo); err != nil {
+		u.log(ctx).WarnContext(ctx, "Validation failed for update", "error", err, "todo_id", todo.ID)
+		return nil, err
 	}
 
-	existing, err := u.repo.FindByID(todo.ID)
+	existing, err := repo.FindByID(ctx, todo.ID)
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
-			return err
+			return nil, err
 		}
-		return err // Error already logged in repository
+		return nil, err // Error already logged in repository
 	}
 	if existing == nil {
-		u.logger.Warn("Todo not found for update", "todo_id", todo.ID)
-		return domain.ErrNotFound
+		u.log(ctx).WarnContext(ctx, "Todo not found for update", "todo_id", todo.ID)
+		return nil, domain.ErrNotFound
 	}
 
 	todo.CreatedAt = existing.CreatedAt
+	trackCompletion(todo, existing, time.Now().UTC())
 
-	fmt.Println("update todo")
+	if err := repo.Update(ctx, todo); err != nil {
+		return nil, err // Error already logged in repository
+	}
 
-	fmt.Println(todo.CreatedAt, todo.UpdatedAt, todo.ID)
-	if err := u.repo.Update(todo); err != nil {
-		return err // Error already logged in repository
+	at := time.Now().UTC()
+	events := []domain.DomainEvent{domain.TodoUpdatedEvent{
+		Todo:       *todo,
+		Previous:   *existing,
+		Changed:    domain.ChangedFields(existing, todo),
+		OccurredAt: at,
+	}}
+	if existing.Status != todo.Status {
+		events = append(events, domain.TodoStatusChangedEvent{Todo: *todo, From: existing.Status, To: todo.Status, OccurredAt: at})
 	}
-	return nil
+	return events, nil
 }
 
-func (u *todoUsecase) List(sortBy, search string) ([]domain.Todo, error) {
-	todos, err := u.repo.FindAll()
+// maxQueryLength is the longest filter query List accepts, in characters.
+const maxQueryLength = 500
+
+func (u *todoUsecase) List(ctx context.Context, opts domain.ListOptions) (_ []domain.Todo, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "todoUsecase.List", trace.WithAttributes(
+		attribute.String("todo.sort_by", opts.SortBy),
+		attribute.Bool("todo.search", opts.Search != ""),
+		attribute.Bool("todo.query", opts.Query != ""),
+	))
+	defer func() { tracing.Finish(span, err) }()
+
+	filter, err := u.parseQuery(ctx, opts.Query)
 	if err != nil {
-		return nil, err // Error already logged in repository
+		return nil, err
 	}
 
-	if search != "" {
-		filtered := []domain.Todo{}
-		for _, t := range todos {
-			if strings.Contains(strings.ToLower(t.Title), strings.ToLower(search)) ||
-				strings.Contains(strings.ToLower(t.Description), strings.ToLower(search)) {
-				filtered = append(filtered, t)
+	var todos []domain.Todo
+	switch {
+	case opts.Search != "":
+		results, err := u.repo.Search(ctx, opts.Search, 0)
+		if err != nil {
+			return nil, err // Error already logged in repository
+		}
+		todos = make([]domain.Todo, len(results))
+		for i, result := range results {
+			todos[i] = result.Todo
+		}
+		if filter != nil {
+			// Intersect with the filter, keeping the relevance order.
+			filtered, err := u.repo.FindByFilter(ctx, filter)
+			if err != nil {
+				return nil, err // Error already logged in repository
+			}
+			keep := make(map[uuid.UUID]bool, len(filtered))
+			for _, todo := range filtered {
+				keep[todo.ID] = true
 			}
+			todos = slices.DeleteFunc(todos, func(todo domain.Todo) bool { return !keep[todo.ID] })
+		}
+		u.log(ctx).InfoContext(ctx, "Todos filtered", "search", opts.Search, "query", opts.Query, "count", len(todos))
+	case filter != nil:
+		if todos, err = u.repo.FindByFilter(ctx, filter); err != nil {
+			return nil, err // Error already logged in repository
+		}
+		u.log(ctx).InfoContext(ctx, "Todos filtered", "query", opts.Query, "count", len(todos))
+	default:
+		if todos, err = u.repo.FindAll(ctx); err != nil {
+			return nil, err // Error already logged in repository
 		}
-		todos = filtered
-		u.logger.Info("Todos filtered", "search", search, "count", len(todos))
 	}
 
-	switch sortBy {
-	case "title", "date", "status":
+	switch sortBy := opts.SortBy; sortBy {
+	case "title", "date", "status", "due":
 		u.sortTodos(todos, sortBy)
-		u.logger.Info("Todos sorted", "sort_by", sortBy)
+		u.log(ctx).InfoContext(ctx, "Todos sorted", "sort_by", sortBy)
 	case "":
 		// No sorting
 	default:
-		u.logger.Warn("Invalid sort parameter", "sort_by", sortBy)
-		return nil, fmt.Errorf("invalid sort parameter: %s", sortBy)
+		u.log(ctx).WarnContext(ctx, "Invalid sort parameter", "sort_by", sortBy)
+		return nil, domain.NewValidationError(violation("sort_by", "oneof", "title date status due"))
 	}
 
 	return todos, nil
 }
 
-func (u *todoUsecase) Delete(id uuid.UUID) error {
-	if id == uuid.Nil {
-		u.logger.Warn("Invalid ID for deletion", "todo_id", id)
-		return fmt.Errorf("%w: ID cannot be empty", domain.ErrValidationFailed)
+func (u *todoUsecase) Export(ctx context.Context, opts domain.ListOptions, fn func(domain.Todo) error) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "todoUsecase.Export", trace.WithAttributes(
+		attribute.String("todo.sort_by", opts.SortBy),
+		attribute.Bool("todo.search", opts.Search != ""),
+		attribute.Bool("todo.query", opts.Query != ""),
+	))
+	defer func() { tracing.Finish(span, err) }()
+
+	if opts.Search != "" {
+		// Search ranks every match in memory, so there is nothing to stream.
+		todos, err := u.List(ctx, opts)
+		if err != nil {
+			return err
+		}
+		for _, todo := range todos {
+			if err := fn(todo); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	switch opts.SortBy {
+	case "", "title", "date", "status", "due":
+	default:
+		u.log(ctx).WarnContext(ctx, "Invalid sort parameter", "sort_by", opts.SortBy)
+		return domain.NewValidationError(violation("sort_by", "oneof", "title date status due"))
+	}
+	filter, err := u.parseQuery(ctx, opts.Query)
+	if err != nil {
+		return err
+	}
+	if filter == nil {
+		filter = domain.AndFilter{}
+	}
+	return u.repo.Stream(ctx, filter, opts.SortBy, fn)
+}
+
+func (u *todoUsecase) Facets(ctx context.Context, query string) (_ *domain.Facets, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "todoUsecase.Facets", trace.WithAttributes(
+		attribute.Bool("todo.query", query != ""),
+	))
+	defer func() { tracing.Finish(span, err) }()
+
+	filter, err := u.parseQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		filter = domain.AndFilter{}
 	}
+	return u.repo.Facets(ctx, filter)
+}
+
+// parseQuery compiles a filter query of List or Facets. It returns a nil
+// filter for an empty query.
+func (u *todoUsecase) parseQuery(ctx context.Context, query string) (domain.Filter, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, nil
+	}
+	if utf8.RuneCountInString(query) > maxQueryLength {
+		return nil, domain.NewValidationError(violation("q", "max", strconv.Itoa(maxQueryLength)))
+	}
+	filter, err := querylang.ParseFilter(query, timezone.Now(ctx))
+	if err != nil {
+		u.log(ctx).WarnContext(ctx, "Invalid filter query", "error", err)
+		return nil, err
+	}
+	return filter, nil
+}
+
+// Search bounds: the result count when none is requested, the largest
+// count that may be requested and the longest query in characters.
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	maxSearchLength    = 200
+)
+
+func (u *todoUsecase) Search(ctx context.Context, query string, limit int) (_ []domain.SearchResult, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "todoUsecase.Search", trace.WithAttributes(
+		attribute.Int("search.limit", limit),
+	))
+	defer func() { tracing.Finish(span, err) }()
+
+	query = strings.TrimSpace(query)
+	var violations []domain.FieldViolation
+	switch {
+	case query == "":
+		violations = append(violations, violation("q", "required", ""))
+	case utf8.RuneCountInString(query) > maxSearchLength:
+		violations = append(violations, violation("q", "max", strconv.Itoa(maxSearchLength)))
+	}
+	switch {
+	case limit < 0:
+		violations = append(violations, violation("limit", "gte", "1"))
+	case limit > maxSearchLimit:
+		violations = append(violations, violation("limit", "lte", strconv.Itoa(maxSearchLimit)))
+	case limit == 0:
+		limit = defaultSearchLimit
+	}
+	if len(violations) > 0 {
+		err := domain.NewValidationError(violations...)
+		u.log(ctx).WarnContext(ctx, "Validation failed for search", "error", err)
+		return nil, err
+	}
+
+	return u.repo.Search(ctx, query, limit)
+}
+
+func (u *todoUsecase) FuzzySearch(ctx context.Context, query string, threshold float64, limit int) (_ []domain.FuzzyMatch, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "todoUsecase.FuzzySearch", trace.WithAttributes(
+		attribute.Float64("search.threshold", threshold),
+		attribute.Int("search.limit", limit),
+	))
+	defer func() { tracing.Finish(span, err) }()
+
+	query = strings.TrimSpace(query)
+	var violations []domain.FieldViolation
+	switch {
+	case query == "":
+		violations = append(violations, violation("q", "required", ""))
+	case utf8.RuneCountInString(query) > maxSearchLength:
+		violations = append(violations, violation("q", "max", strconv.Itoa(maxSearchLength)))
+	}
+	switch {
+	case threshold < 0:
+		violations = append(violations, violation("threshold", "gte", "0"))
+	case threshold > 1:
+		violations = append(violations, violation("threshold", "lte", "1"))
+	case threshold == 0:
+		threshold = search.DefaultThreshold
+	}
+	switch {
+	case limit < 0:
+		violations = append(violations, violation("limit", "gte", "1"))
+	case limit > maxSearchLimit:
+		violations = append(violations, violation("limit", "lte", strconv.Itoa(maxSearchLimit)))
+	case limit == 0:
+		limit = defaultSearchLimit
+	}
+	if len(violations) > 0 {
+		err := domain.NewValidationError(violations...)
+		u.log(ctx).WarnContext(ctx, "Validation failed for fuzzy search", "error", err)
+		return nil, err
+	}
+
+	return u.repo.FuzzySearch(ctx, query, threshold, limit)
+}
+
+func (u *todoUsecase) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "todoUsecase.Delete",
+		trace.WithAttributes(attribute.String("todo.id", id.String())))
+	defer func() { tracing.Finish(span, err) }()
 
-	if err := u.repo.Delete(id); err != nil {
+	events, err := u.delete(ctx, u.repo, id)
+	if err != nil {
 		return err
 	}
+	u.publish(ctx, events...)
 	return nil
 }
 
+func (u *todoUsecase) delete(ctx context.Context, repo domain.TodoRepository, id uuid.UUID) ([]domain.DomainEvent, error) {
+	if id == uuid.Nil {
+		u.log(ctx).WarnContext(ctx, "Invalid ID for deletion", "todo_id", id)
+		return nil, domain.NewValidationError(violation("id", "required", ""))
+	}
+
+	existing, err := repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err // Error already logged in repository
+	}
+	if err := repo.Delete(ctx, id); err != nil {
+		return nil, err
+	}
+	return []domain.DomainEvent{domain.TodoDeletedEvent{Todo: *existing, OccurredAt: time.Now().UTC()}}, nil
+}
+
+// publish hands committed changes to the event bus. It must never be called
+// for writes that may still be rolled back.
+func (u *todoUsecase) publish(ctx context.Context, events ...domain.DomainEvent) {
+	if u.bus == nil || len(events) == 0 {
+		return
+	}
+	u.bus.Publish(ctx, events...)
+}
+
+// validateTodo runs struct validation in its own span so slow or failing
+// validation is distinguishable from storage time in traces.
+func (u *todoUsecase) validateTodo(ctx context.Context, todo *domain.Todo) error {
+	_, span := tracing.Tracer().Start(ctx, "todoUsecase.validate")
+	defer span.End()
+
+	err := toValidationError(u.validate.Struct(todo))
+	if err != nil {
+		span.RecordError(err)
+	}
+	return err
+}
+
+// normalizeDueDate stores due dates in UTC.
+func normalizeDueDate(todo *domain.Todo) {
+	if todo.DueDate != nil {
+		due := todo.DueDate.UTC()
+		todo.DueDate = &due
+	}
+}
+
+// trackCompletion sets the CompletedAt of todo, which replaces previous or
+// is new if previous is nil. Todos in progress have none, todos that stay
+// completed keep theirs and todos completed now get now. A new todo may
+// bring its own, as imported ones do.
+func trackCompletion(todo, previous *domain.Todo, now time.Time) {
+	switch {
+	case todo.Status != "COMPLETED":
+		todo.CompletedAt = nil
+	case previous != nil && previous.Status == "COMPLETED" && previous.CompletedAt != nil:
+		todo.CompletedAt = previous.CompletedAt
+	case previous == nil && todo.CompletedAt != nil:
+		completed := todo.CompletedAt.UTC()
+		todo.CompletedAt = &completed
+	default:
+		todo.CompletedAt = &now
+	}
+}
+
 func (u *todoUsecase) sortTodos(todos []domain.Todo, sortBy string) {
 	switch sortBy {
 	case "title":
@@ -118,6 +428,11 @@ func (u *todoUsecase) sortTodos(todos []domain.Todo, sortBy string) {
 		sortTodos(todos, func(i, j int) bool { return todos[i].CreatedAt.Before(todos[j].CreatedAt) })
 	case "status":
 		sortTodos(todos, func(i, j int) bool { return todos[i].Status < todos[j].Status })
+	case "due":
+		sortTodos(todos, func(i, j int) bool {
+			a, b := todos[i].DueDate, todos[j].DueDate
+			return a != nil && (b == nil || a.Before(*b))
+		})
 	}
 }
 
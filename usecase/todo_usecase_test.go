@@ -1,18 +1,24 @@
 package usecase
 
 import (
+	"context"
 	"log/slog"
+	"strings"
 	"testing"
 	"todo-app/domain"
 	"todo-app/domain/mocks"
+	"todo-app/repository"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTodoUsecase_Create(t *testing.T) {
 	mockRepo := new(mocks.MockTodoRepository)
 	logger := slog.Default()
-	usecase := NewTodoUsecase(mockRepo, logger)
+	usecase := NewTodoUsecase(mockRepo, nil, logger)
 
 	todo := &domain.Todo{
 		Title:       "Test Todo",
@@ -22,9 +28,9 @@ func TestTodoUsecase_Create(t *testing.T) {
 
 	// Success case
 	t.Run("success", func(t *testing.T) {
-		mockRepo.On("Create", todo).Return(nil).Once()
+		mockRepo.On("Create", mock.Anything, todo).Return(nil).Once()
 
-		err := usecase.Create(todo)
+		err := usecase.Create(context.Background(), todo)
 
 		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
@@ -32,11 +38,347 @@ func TestTodoUsecase_Create(t *testing.T) {
 
 	// Error case
 	t.Run("error", func(t *testing.T) {
-		mockRepo.On("Create", todo).Return(assert.AnError).Once()
+		mockRepo.On("Create", mock.Anything, todo).Return(assert.AnError).Once()
 
-		err := usecase.Create(todo)
+		err := usecase.Create(context.Background(), todo)
 
 		assert.Error(t, err)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("validation error", func(t *testing.T) {
+		err := usecase.Create(context.Background(), &domain.Todo{Status: "DONE"})
+
+		var validationErr *domain.ValidationError
+		assert.ErrorIs(t, err, domain.ErrValidationFailed)
+		assert.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []domain.FieldViolation{
+			{Field: "title", Rule: "required", Message: "title is required"},
+			{Field: "status", Rule: "oneof", Param: "IN_PROGRESS COMPLETED", Message: "status must be one of: IN_PROGRESS, COMPLETED"},
+		}, validationErr.Violations)
+	})
+}
+
+func TestTodoUsecase_List_InvalidSort(t *testing.T) {
+	mockRepo := new(mocks.MockTodoRepository)
+	usecase := NewTodoUsecase(mockRepo, nil, slog.Default())
+	mockRepo.On("FindAll", mock.Anything).Return([]domain.Todo{}, nil).Once()
+
+	_, err := usecase.List(context.Background(), domain.ListOptions{SortBy: "priority"})
+
+	var validationErr *domain.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "sort_by", validationErr.Violations[0].Field)
+}
+
+func TestTodoUsecase_Search(t *testing.T) {
+	mockRepo := new(mocks.MockTodoRepository)
+	usecase := NewTodoUsecase(mockRepo, nil, slog.Default())
+
+	t.Run("default limit", func(t *testing.T) {
+		results := []domain.SearchResult{{Todo: domain.Todo{Title: "Weekly report"}, Rank: 1}}
+		mockRepo.On("Search", mock.Anything, "report", 20).Return(results, nil).Once()
+
+		got, err := usecase.Search(context.Background(), "  report ", 0)
+
+		require.NoError(t, err)
+		assert.Equal(t, results, got)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		_, err := usecase.Search(context.Background(), " ", 101)
+
+		var validationErr *domain.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []domain.FieldViolation{
+			{Field: "q", Rule: "required", Message: "q is required"},
+			{Field: "limit", Rule: "lte", Param: "100", Message: "limit must be at most 100"},
+		}, validationErr.Violations)
+	})
+}
+
+func TestTodoUsecase_Export(t *testing.T) {
+	mockRepo := new(mocks.MockTodoRepository)
+	usecase := NewTodoUsecase(mockRepo, nil, slog.Default())
+	collect := func(opts domain.ListOptions) ([]domain.Todo, error) {
+		var got []domain.Todo
+		err := usecase.Export(context.Background(), opts, func(todo domain.Todo) error {
+			got = append(got, todo)
+			return nil
+		})
+		return got, err
+	}
+
+	t.Run("streams the filter", func(t *testing.T) {
+		todos := []domain.Todo{{Title: "Ship API"}}
+		backend := domain.FieldFilter{Field: domain.FilterTag, Op: domain.OpEq, Value: "backend"}
+		mockRepo.On("Stream", mock.Anything, backend, "due", mock.Anything).Return(todos, nil).Once()
+
+		got, err := collect(domain.ListOptions{SortBy: "due", Query: "tag:backend"})
+
+		require.NoError(t, err)
+		assert.Equal(t, todos, got)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("streams everything without a query", func(t *testing.T) {
+		mockRepo.On("Stream", mock.Anything, domain.AndFilter{}, "", mock.Anything).Return([]domain.Todo{}, nil).Once()
+
+		_, err := collect(domain.ListOptions{})
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("search ranks in memory", func(t *testing.T) {
+		mockRepo.On("Search", mock.Anything, "report", 0).Return([]domain.SearchResult{{Todo: domain.Todo{Title: "Weekly report"}}}, nil).Once()
+
+		got, err := collect(domain.ListOptions{Search: "report"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []domain.Todo{{Title: "Weekly report"}}, got)
+	})
+
+	t.Run("invalid options before streaming", func(t *testing.T) {
+		_, err := collect(domain.ListOptions{SortBy: "priority"})
+		var validationErr *domain.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+
+		_, err = collect(domain.ListOptions{Query: "status:"})
+		assert.ErrorIs(t, err, domain.ErrInvalidQuery)
+		mockRepo.AssertNotCalled(t, "Stream", mock.Anything, mock.Anything, "priority", mock.Anything)
+	})
+}
+
+func TestTodoUsecase_FuzzySearch(t *testing.T) {
+	mockRepo := new(mocks.MockTodoRepository)
+	usecase := NewTodoUsecase(mockRepo, nil, slog.Default())
+
+	t.Run("defaults", func(t *testing.T) {
+		matches := []domain.FuzzyMatch{{Todo: domain.Todo{Title: "Buy groceries"}, Score: 0.58}}
+		mockRepo.On("FuzzySearch", mock.Anything, "grocerys", 0.3, 20).Return(matches, nil).Once()
+
+		got, err := usecase.FuzzySearch(context.Background(), " grocerys ", 0, 0)
+
+		require.NoError(t, err)
+		assert.Equal(t, matches, got)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		_, err := usecase.FuzzySearch(context.Background(), "", 1.5, -1)
+
+		var validationErr *domain.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []domain.FieldViolation{
+			{Field: "q", Rule: "required", Message: "q is required"},
+			{Field: "threshold", Rule: "lte", Param: "1", Message: "threshold must be at most 1"},
+			{Field: "limit", Rule: "gte", Param: "1", Message: "limit must be at least 1"},
+		}, validationErr.Violations)
+	})
+}
+
+func TestTodoUsecase_List_Search(t *testing.T) {
+	mockRepo := new(mocks.MockTodoRepository)
+	usecase := NewTodoUsecase(mockRepo, nil, slog.Default())
+	mockRepo.On("Search", mock.Anything, "report", 0).Return([]domain.SearchResult{
+		{Todo: domain.Todo{Title: "Write report"}, Rank: 1},
+		{Todo: domain.Todo{Title: "Archive report"}, Rank: 0.5},
+	}, nil).Once()
+
+	todos, err := usecase.List(context.Background(), domain.ListOptions{SortBy: "title", Search: "report"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Archive report", todos[0].Title, "sort_by overrides relevance order")
+	assert.Len(t, todos, 2)
+}
+
+func TestTodoUsecase_List_Query(t *testing.T) {
+	mockRepo := new(mocks.MockTodoRepository)
+	usecase := NewTodoUsecase(mockRepo, nil, slog.Default())
+	backend := domain.FieldFilter{Field: domain.FilterTag, Op: domain.OpEq, Value: "backend"}
+
+	t.Run("filter", func(t *testing.T) {
+		todos := []domain.Todo{{Title: "Ship API"}}
+		mockRepo.On("FindByFilter", mock.Anything, backend).Return(todos, nil).Once()
+
+		got, err := usecase.List(context.Background(), domain.ListOptions{Query: " tag:backend "})
+
+		require.NoError(t, err)
+		assert.Equal(t, todos, got)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("intersects with search in relevance order", func(t *testing.T) {
+		first, second, third := uuid.New(), uuid.New(), uuid.New()
+		mockRepo.On("Search", mock.Anything, "report", 0).Return([]domain.SearchResult{
+			{Todo: domain.Todo{ID: first}, Rank: 1},
+			{Todo: domain.Todo{ID: second}, Rank: 0.8},
+			{Todo: domain.Todo{ID: third}, Rank: 0.5},
+		}, nil).Once()
+		mockRepo.On("FindByFilter", mock.Anything, backend).Return([]domain.Todo{{ID: third}, {ID: first}}, nil).Once()
+
+		got, err := usecase.List(context.Background(), domain.ListOptions{Search: "report", Query: "tag:backend"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []domain.Todo{{ID: first}, {ID: third}}, got)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		_, err := usecase.List(context.Background(), domain.ListOptions{Query: "status:DONE"})
+
+		var queryErr *domain.QueryError
+		require.ErrorAs(t, err, &queryErr)
+		assert.Equal(t, domain.QueryError{Position: 8, Code: domain.QueryInvalidValue, Param: "DONE"}, *queryErr)
+	})
+
+	t.Run("too long", func(t *testing.T) {
+		_, err := usecase.List(context.Background(), domain.ListOptions{Query: strings.Repeat("a", 501)})
+
+		var validationErr *domain.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, "q", validationErr.Violations[0].Field)
+	})
+}
+
+func TestTodoUsecase_Facets(t *testing.T) {
+	mockRepo := new(mocks.MockTodoRepository)
+	usecase := NewTodoUsecase(mockRepo, nil, slog.Default())
+	facets := &domain.Facets{Total: 1, Status: []domain.FacetCount{{Value: "COMPLETED", Count: 1}}}
+
+	t.Run("all todos", func(t *testing.T) {
+		mockRepo.On("Facets", mock.Anything, domain.AndFilter{}).Return(facets, nil).Once()
+
+		got, err := usecase.Facets(context.Background(), " ")
+
+		require.NoError(t, err)
+		assert.Equal(t, facets, got)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		done := domain.FieldFilter{Field: domain.FilterStatus, Op: domain.OpEq, Value: "COMPLETED"}
+		mockRepo.On("Facets", mock.Anything, done).Return(facets, nil).Once()
+
+		_, err := usecase.Facets(context.Background(), "status:completed")
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		_, err := usecase.Facets(context.Background(), "(tag:api")
+
+		assert.ErrorIs(t, err, domain.ErrInvalidQuery)
+	})
+}
+
+// recordingBus collects published events.
+type recordingBus struct {
+	events []domain.DomainEvent
+}
+
+func (b *recordingBus) Publish(ctx context.Context, events ...domain.DomainEvent) {
+	b.events = append(b.events, events...)
+}
+
+func (b *recordingBus) Subscribe(string, domain.EventHandler)      {}
+func (b *recordingBus) SubscribeAsync(string, domain.EventHandler) {}
+
+func TestTodoUsecase_TracksCompletion(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryTodoRepo(slog.Default())
+	usecase := NewTodoUsecase(repo, nil, slog.Default())
+
+	todo := &domain.Todo{Title: "Write report", Status: "IN_PROGRESS"}
+	require.NoError(t, usecase.Create(ctx, todo))
+	assert.Nil(t, todo.CompletedAt)
+
+	todo.Status = "COMPLETED"
+	require.NoError(t, usecase.Update(ctx, todo))
+	require.NotNil(t, todo.CompletedAt)
+	completed := *todo.CompletedAt
+
+	forged := completed.AddDate(-1, 0, 0)
+	require.NoError(t, usecase.Update(ctx, &domain.Todo{ID: todo.ID, Title: "Write the report", Status: "COMPLETED", CompletedAt: &forged}))
+	stored, err := repo.FindByID(ctx, todo.ID)
+	require.NoError(t, err)
+	assert.True(t, completed.Equal(*stored.CompletedAt), "stays completed at %s, got %s", completed, stored.CompletedAt)
+
+	todo = &domain.Todo{ID: todo.ID, Title: "Write the report", Status: "IN_PROGRESS", CompletedAt: &completed}
+	require.NoError(t, usecase.Update(ctx, todo))
+	assert.Nil(t, todo.CompletedAt)
+
+	imported := &domain.Todo{Title: "Old", Status: "COMPLETED", CompletedAt: &forged}
+	require.NoError(t, usecase.Create(ctx, imported))
+	assert.True(t, forged.Equal(*imported.CompletedAt))
+}
+
+func TestTodoUsecase_PublishesEvents(t *testing.T) {
+	existing := &domain.Todo{ID: uuid.New(), Title: "Write report", Status: "IN_PROGRESS", Tags: []string{"work"}}
+
+	t.Run("create", func(t *testing.T) {
+		mockRepo, bus := new(mocks.MockTodoRepository), &recordingBus{}
+		usecase := NewTodoUsecase(mockRepo, bus, slog.Default())
+		mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil).Once()
+
+		require.NoError(t, usecase.Create(context.Background(), &domain.Todo{Title: "New", Status: "IN_PROGRESS"}))
+
+		require.Len(t, bus.events, 1)
+		created := bus.events[0].(domain.TodoCreatedEvent)
+		assert.Equal(t, "New", created.Todo.Title)
+		assert.False(t, created.OccurredAt.IsZero())
+	})
+
+	t.Run("update with status change", func(t *testing.T) {
+		mockRepo, bus := new(mocks.MockTodoRepository), &recordingBus{}
+		usecase := NewTodoUsecase(mockRepo, bus, slog.Default())
+		mockRepo.On("FindByID", mock.Anything, existing.ID).Return(existing, nil).Once()
+		mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil).Once()
+
+		todo := &domain.Todo{ID: existing.ID, Title: "Write report", Status: "COMPLETED", Tags: []string{"work", "q3"}}
+		require.NoError(t, usecase.Update(context.Background(), todo))
+
+		require.Len(t, bus.events, 2)
+		updated := bus.events[0].(domain.TodoUpdatedEvent)
+		assert.Equal(t, []string{"status", "tags"}, updated.Changed)
+		assert.True(t, updated.HasChanged("status"))
+		assert.Equal(t, "IN_PROGRESS", updated.Previous.Status)
+		assert.Equal(t, domain.TodoStatusChangedEvent{Todo: *todo, From: "IN_PROGRESS", To: "COMPLETED", OccurredAt: updated.OccurredAt}, bus.events[1])
+	})
+
+	t.Run("update without status change", func(t *testing.T) {
+		mockRepo, bus := new(mocks.MockTodoRepository), &recordingBus{}
+		usecase := NewTodoUsecase(mockRepo, bus, slog.Default())
+		mockRepo.On("FindByID", mock.Anything, existing.ID).Return(existing, nil).Once()
+		mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil).Once()
+
+		require.NoError(t, usecase.Update(context.Background(), &domain.Todo{ID: existing.ID, Title: "Write the report", Status: "IN_PROGRESS", Tags: []string{"work"}}))
+
+		require.Len(t, bus.events, 1)
+		assert.Equal(t, []string{"title"}, bus.events[0].(domain.TodoUpdatedEvent).Changed)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		mockRepo, bus := new(mocks.MockTodoRepository), &recordingBus{}
+		usecase := NewTodoUsecase(mockRepo, bus, slog.Default())
+		mockRepo.On("FindByID", mock.Anything, existing.ID).Return(existing, nil).Once()
+		mockRepo.On("Delete", mock.Anything, existing.ID).Return(nil).Once()
+
+		require.NoError(t, usecase.Delete(context.Background(), existing.ID))
+
+		require.Len(t, bus.events, 1)
+		assert.Equal(t, *existing, bus.events[0].(domain.TodoDeletedEvent).Todo)
+	})
+
+	t.Run("failed write", func(t *testing.T) {
+		mockRepo, bus := new(mocks.MockTodoRepository), &recordingBus{}
+		usecase := NewTodoUsecase(mockRepo, bus, slog.Default())
+		mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(assert.AnError).Once()
+
+		assert.Error(t, usecase.Create(context.Background(), &domain.Todo{Title: "New", Status: "IN_PROGRESS"}))
+		assert.Empty(t, bus.events)
+	})
 }
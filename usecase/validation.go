@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"todo-app/domain"
+	"todo-app/i18n"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// newValidator reports fields by their JSON name so violations match what
+// API clients send.
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return f.Name
+		}
+		return name
+	})
+	return v
+}
+
+// toValidationError translates validator.ValidationErrors into a
+// domain.ValidationError. Other errors are returned unchanged.
+func toValidationError(err error) error {
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return err
+	}
+	violations := make([]domain.FieldViolation, len(fieldErrs))
+	for i, fe := range fieldErrs {
+		violations[i] = violation(fieldPath(fe), ruleOf(fe), fe.Param())
+	}
+	return &domain.ValidationError{Violations: violations}
+}
+
+// fieldPath is the JSON path of the field, such as "tags[2]" for an element
+// of a slice.
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if _, path, ok := strings.Cut(ns, "."); ok {
+		return path
+	}
+	return fe.Field()
+}
+
+// ruleOf distinguishes length limits on collections, which count items, from
+// those on strings, which count characters.
+func ruleOf(fe validator.FieldError) string {
+	if fe.Kind() == reflect.Slice || fe.Kind() == reflect.Map {
+		switch fe.Tag() {
+		case "max":
+			return "max_items"
+		case "min":
+			return "min_items"
+		}
+	}
+	return fe.Tag()
+}
+
+// violation builds a FieldViolation with its English message.
+func violation(field, rule, param string) domain.FieldViolation {
+	return domain.FieldViolation{
+		Field:   field,
+		Rule:    rule,
+		Param:   param,
+		Message: i18n.Violation(i18n.Default(), field, rule, param),
+	}
+}
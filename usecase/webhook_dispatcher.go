@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+	"todo-app/domain"
+	"todo-app/logging"
+
+	"github.com/google/uuid"
+)
+
+// WebhookPolicy controls how deliveries are retried and when a failing
+// webhook is disabled.
+type WebhookPolicy struct {
+	// MaxAttempts is how often a delivery is tried before it is failed.
+	MaxAttempts int
+	// InitialBackoff is the wait after the first failed attempt. It doubles
+	// after every further failure, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// DisableAfter is how many consecutive failed attempts, across all of
+	// its deliveries, disable a webhook.
+	DisableAfter int
+	// BatchSize caps the deliveries attempted per DeliverDue call.
+	BatchSize int
+	// Lease hides a claimed delivery from other workers while it is sent.
+	// It must exceed the sender's timeout.
+	Lease time.Duration
+}
+
+// Backoff returns the wait after attempt failed.
+func (p WebhookPolicy) Backoff(attempt int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 1; i < attempt && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, p.MaxBackoff)
+}
+
+// WebhookDispatcher turns todo events into persistent webhook deliveries
+// and works through the ones that are due.
+type WebhookDispatcher struct {
+	repo   domain.WebhookRepository
+	sender domain.WebhookSender
+	policy WebhookPolicy
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+func NewWebhookDispatcher(repo domain.WebhookRepository, sender domain.WebhookSender, policy WebhookPolicy, logger *slog.Logger) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		repo:   repo,
+		sender: sender,
+		policy: policy,
+		logger: logger,
+		now:    func() time.Time { return time.Now().UTC() },
+	}
+}
+
+func (d *WebhookDispatcher) log(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx, d.logger)
+}
+
+// Enqueue queues event for every enabled webhook subscribed to its type,
+// with the event as JSON for payload. It is fed by the outbox relay, so the
+// event ID is stable and an event relayed twice is queued once.
+func (d *WebhookDispatcher) Enqueue(ctx context.Context, event domain.TodoEvent) error {
+	webhooks, err := d.repo.FindAll(ctx)
+	if err != nil {
+		return err // Error already logged in repository
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encoding webhook payload: %w", err)
+	}
+
+	now := d.now()
+	var deliveries []domain.WebhookDelivery
+	for _, webhook := range webhooks {
+		if !webhook.Enabled || !webhook.Wants(event.Type) {
+			continue
+		}
+		deliveries = append(deliveries, domain.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			Payload:       payload,
+			Status:        domain.DeliveryPending,
+			NextAttemptAt: now,
+		})
+	}
+	return d.repo.Enqueue(ctx, deliveries)
+}
+
+// DeliverDue makes one attempt for each delivery that is due, concurrently,
+// and returns how many were attempted.
+func (d *WebhookDispatcher) DeliverDue(ctx context.Context) (int, error) {
+	deliveries, err := d.repo.ClaimDue(ctx, d.now(), d.policy.Lease, d.policy.BatchSize)
+	if err != nil {
+		return 0, err // Error already logged in repository
+	}
+
+	webhooks := make(map[uuid.UUID]*domain.Webhook)
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		webhook, ok := webhooks[delivery.WebhookID]
+		if !ok {
+			if webhook, err = d.repo.FindByID(ctx, delivery.WebhookID); err != nil {
+				continue // deleted meanwhile; its deliveries went with it
+			}
+			webhooks[delivery.WebhookID] = webhook
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.attempt(ctx, webhook, delivery)
+		}()
+	}
+	wg.Wait()
+	return len(deliveries), nil
+}
+
+// attempt sends delivery once and records the outcome, scheduling a retry
+// or failing the delivery when it is out of attempts.
+func (d *WebhookDispatcher) attempt(ctx context.Context, webhook *domain.Webhook, delivery *domain.WebhookDelivery) {
+	start := d.now()
+	status, err := d.sender.Send(ctx, webhook, delivery)
+	attempt := &domain.WebhookAttempt{
+		Number:      delivery.AttemptCount + 1,
+		StatusCode:  status,
+		DurationMS:  d.now().Sub(start).Milliseconds(),
+		AttemptedAt: start,
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+
+	delivery.AttemptCount = attempt.Number
+	logger := d.log(ctx).With("webhook_id", webhook.ID, "delivery_id", delivery.ID, "attempt", attempt.Number)
+	switch {
+	case attempt.Succeeded():
+		delivery.Status, delivery.LastError = domain.DeliverySucceeded, ""
+		logger.InfoContext(ctx, "Webhook delivered", "status", status)
+	case attempt.Number >= d.policy.MaxAttempts:
+		delivery.Status, delivery.LastError = domain.DeliveryFailed, failure(attempt)
+		logger.WarnContext(ctx, "Webhook delivery failed permanently", "error", delivery.LastError)
+	default:
+		delivery.LastError = failure(attempt)
+		delivery.NextAttemptAt = d.now().Add(d.policy.Backoff(attempt.Number))
+		logger.InfoContext(ctx, "Webhook delivery failed, will retry", "error", delivery.LastError, "next_attempt_at", delivery.NextAttemptAt)
+	}
+
+	disabled, err := d.repo.RecordAttempt(ctx, delivery, attempt, d.policy.DisableAfter)
+	if err != nil {
+		// The lease expires and the delivery is attempted again.
+		return
+	}
+	if disabled {
+		logger.WarnContext(ctx, "Webhook disabled after repeated failures", "consecutive_failures", d.policy.DisableAfter)
+	}
+}
+
+func failure(attempt *domain.WebhookAttempt) string {
+	if attempt.Error != "" {
+		return attempt.Error
+	}
+	return fmt.Sprintf("unexpected status %d", attempt.StatusCode)
+}
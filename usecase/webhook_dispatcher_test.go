@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+	"todo-app/domain"
+	"todo-app/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// senderFunc answers every delivery with the status and error it returns.
+type senderFunc func(delivery *domain.WebhookDelivery) (int, error)
+
+func (f senderFunc) Send(_ context.Context, _ *domain.Webhook, delivery *domain.WebhookDelivery) (int, error) {
+	return f(delivery)
+}
+
+func TestWebhookPolicy_Backoff(t *testing.T) {
+	policy := WebhookPolicy{InitialBackoff: 30 * time.Second, MaxBackoff: 5 * time.Minute}
+
+	assert.Equal(t, 30*time.Second, policy.Backoff(1))
+	assert.Equal(t, time.Minute, policy.Backoff(2))
+	assert.Equal(t, 4*time.Minute, policy.Backoff(4))
+	assert.Equal(t, 5*time.Minute, policy.Backoff(5))
+	assert.Equal(t, 5*time.Minute, policy.Backoff(60))
+}
+
+func TestWebhookDispatcher(t *testing.T) {
+	ctx := context.Background()
+	policy := WebhookPolicy{MaxAttempts: 3, InitialBackoff: time.Minute, MaxBackoff: time.Hour, DisableAfter: 100, BatchSize: 10, Lease: time.Minute}
+
+	setup := func(t *testing.T, send senderFunc, policy WebhookPolicy) (*WebhookDispatcher, *repository.MemoryWebhookRepo, *domain.Webhook, *time.Time) {
+		repo := repository.NewMemoryWebhookRepo()
+		webhook := &domain.Webhook{URL: "https://example.com/hook", Secret: "0123456789abcdef", EventTypes: []domain.TodoEventType{domain.TodoCreated}, Enabled: true}
+		require.NoError(t, repo.Create(ctx, webhook))
+		other := &domain.Webhook{URL: "https://example.com/other", Secret: "0123456789abcdef", EventTypes: []domain.TodoEventType{domain.TodoDeleted}, Enabled: true}
+		require.NoError(t, repo.Create(ctx, other))
+
+		now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+		d := NewWebhookDispatcher(repo, send, policy, slog.Default())
+		d.now = func() time.Time { return now }
+		return d, repo, webhook, &now
+	}
+	event := domain.TodoEvent{ID: "b-1", Type: domain.TodoCreated, Todo: domain.Todo{Title: "Buy milk"}}
+
+	t.Run("delivers to subscribed webhooks", func(t *testing.T) {
+		var mu sync.Mutex
+		var sent []string
+		d, repo, webhook, _ := setup(t, func(delivery *domain.WebhookDelivery) (int, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			sent = append(sent, delivery.EventID)
+			return 204, nil
+		}, policy)
+
+		require.NoError(t, d.Enqueue(ctx, event))
+		n, err := d.DeliverDue(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, n)
+		assert.Equal(t, []string{"b-1"}, sent)
+		deliveries, err := repo.Deliveries(ctx, webhook.ID, 10)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, domain.DeliverySucceeded, deliveries[0].Status)
+		var payload domain.TodoEvent
+		require.NoError(t, json.Unmarshal(deliveries[0].Payload, &payload))
+		assert.Equal(t, event, payload)
+	})
+
+	t.Run("retries with backoff until attempts run out", func(t *testing.T) {
+		d, repo, webhook, now := setup(t, func(*domain.WebhookDelivery) (int, error) {
+			return 0, errors.New("connection refused")
+		}, policy)
+		require.NoError(t, d.Enqueue(ctx, event))
+
+		for attempt := 1; attempt <= 3; attempt++ {
+			n, err := d.DeliverDue(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, 1, n, "attempt %d", attempt)
+			n, err = d.DeliverDue(ctx)
+			require.NoError(t, err)
+			assert.Zero(t, n, "the retry waits for its backoff")
+			*now = now.Add(policy.Backoff(attempt))
+		}
+
+		deliveries, err := repo.Deliveries(ctx, webhook.ID, 10)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, domain.DeliveryFailed, deliveries[0].Status)
+		assert.Equal(t, "connection refused", deliveries[0].LastError)
+		assert.Len(t, deliveries[0].Attempts, 3)
+		n, err := d.DeliverDue(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+
+	t.Run("disables after repeated failures", func(t *testing.T) {
+		policy := policy
+		policy.DisableAfter = 2
+		d, repo, webhook, _ := setup(t, func(*domain.WebhookDelivery) (int, error) {
+			return 500, nil
+		}, policy)
+		require.NoError(t, d.Enqueue(ctx, event))
+		require.NoError(t, d.Enqueue(ctx, domain.TodoEvent{ID: "b-2", Type: domain.TodoCreated}))
+
+		_, err := d.DeliverDue(ctx)
+		require.NoError(t, err)
+
+		found, err := repo.FindByID(ctx, webhook.ID)
+		require.NoError(t, err)
+		assert.False(t, found.Enabled)
+		deliveries, err := repo.Deliveries(ctx, webhook.ID, 10)
+		require.NoError(t, err)
+		for _, delivery := range deliveries {
+			assert.Equal(t, "unexpected status 500", delivery.LastError)
+		}
+		require.NoError(t, d.Enqueue(ctx, domain.TodoEvent{ID: "b-3", Type: domain.TodoCreated}))
+		deliveries, err = repo.Deliveries(ctx, webhook.ID, 10)
+		require.NoError(t, err)
+		assert.Len(t, deliveries, 2, "disabled webhooks get no new deliveries")
+	})
+}
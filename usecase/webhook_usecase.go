@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"time"
+	"todo-app/domain"
+	"todo-app/logging"
+	"todo-app/tracing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// deliveryHistory is how many recent deliveries Deliveries returns.
+const deliveryHistory = 50
+
+type webhookUsecase struct {
+	repo     domain.WebhookRepository
+	validate *validator.Validate
+	logger   *slog.Logger
+}
+
+func NewWebhookUsecase(repo domain.WebhookRepository, logger *slog.Logger) domain.WebhookUsecase {
+	return &webhookUsecase{
+		repo:     repo,
+		validate: newValidator(),
+		logger:   logger,
+	}
+}
+
+func (u *webhookUsecase) log(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx, u.logger)
+}
+
+// Create stores a new webhook, generating a secret when none is given.
+func (u *webhookUsecase) Create(ctx context.Context, webhook *domain.Webhook) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "webhookUsecase.Create")
+	defer func() { tracing.Finish(span, err) }()
+
+	if webhook.Secret == "" {
+		if webhook.Secret, err = newSecret(); err != nil {
+			return err
+		}
+	}
+	webhook.ConsecutiveFailures, webhook.DisabledAt = 0, nil
+	if err := u.validateWebhook(ctx, webhook); err != nil {
+		return err
+	}
+	return u.repo.Create(ctx, webhook)
+}
+
+// Update replaces the settings of a webhook. An empty secret keeps the
+// current one. Enabling a disabled webhook clears its failure count, so
+// deliveries that were waiting for it resume.
+func (u *webhookUsecase) Update(ctx context.Context, webhook *domain.Webhook) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "webhookUsecase.Update",
+		trace.WithAttributes(attribute.String("webhook.id", webhook.ID.String())))
+	defer func() { tracing.Finish(span, err) }()
+
+	existing, err := u.find(ctx, webhook.Owner, webhook.ID)
+	if err != nil {
+		return err
+	}
+	if webhook.Secret == "" {
+		webhook.Secret = existing.Secret
+	}
+	if err := u.validateWebhook(ctx, webhook); err != nil {
+		return err
+	}
+
+	webhook.CreatedAt = existing.CreatedAt
+	webhook.ConsecutiveFailures, webhook.DisabledAt = existing.ConsecutiveFailures, existing.DisabledAt
+	switch {
+	case webhook.Enabled && !existing.Enabled:
+		webhook.ConsecutiveFailures, webhook.DisabledAt = 0, nil
+	case !webhook.Enabled && existing.Enabled:
+		now := time.Now().UTC()
+		webhook.DisabledAt = &now
+	}
+	return u.repo.Update(ctx, webhook)
+}
+
+func (u *webhookUsecase) Get(ctx context.Context, owner string, id uuid.UUID) (_ *domain.Webhook, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "webhookUsecase.Get",
+		trace.WithAttributes(attribute.String("webhook.id", id.String())))
+	defer func() { tracing.Finish(span, err) }()
+
+	return u.find(ctx, owner, id)
+}
+
+func (u *webhookUsecase) List(ctx context.Context, owner string) (_ []domain.Webhook, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "webhookUsecase.List")
+	defer func() { tracing.Finish(span, err) }()
+
+	return u.repo.FindByOwner(ctx, owner)
+}
+
+func (u *webhookUsecase) Delete(ctx context.Context, owner string, id uuid.UUID) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "webhookUsecase.Delete",
+		trace.WithAttributes(attribute.String("webhook.id", id.String())))
+	defer func() { tracing.Finish(span, err) }()
+
+	if _, err := u.find(ctx, owner, id); err != nil {
+		return err
+	}
+	return u.repo.Delete(ctx, id)
+}
+
+// Deliveries returns the most recent deliveries of a webhook with their
+// attempts.
+func (u *webhookUsecase) Deliveries(ctx context.Context, owner string, id uuid.UUID) (_ []domain.WebhookDelivery, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "webhookUsecase.Deliveries",
+		trace.WithAttributes(attribute.String("webhook.id", id.String())))
+	defer func() { tracing.Finish(span, err) }()
+
+	if _, err := u.find(ctx, owner, id); err != nil {
+		return nil, err
+	}
+	return u.repo.Deliveries(ctx, id, deliveryHistory)
+}
+
+// find returns the webhook of owner with id. Webhooks of other owners are
+// reported as not found, so their IDs are not disclosed.
+func (u *webhookUsecase) find(ctx context.Context, owner string, id uuid.UUID) (*domain.Webhook, error) {
+	webhook, err := u.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err // Error already logged in repository
+	}
+	if webhook.Owner != owner {
+		u.log(ctx).WarnContext(ctx, "Webhook belongs to another user", "webhook_id", id)
+		return nil, domain.ErrNotFound
+	}
+	return webhook, nil
+}
+
+func (u *webhookUsecase) validateWebhook(ctx context.Context, webhook *domain.Webhook) error {
+	if err := toValidationError(u.validate.Struct(webhook)); err != nil {
+		u.log(ctx).WarnContext(ctx, "Validation failed for webhook", "error", err, "webhook_id", webhook.ID)
+		return err
+	}
+	return nil
+}
+
+// newSecret returns 32 random bytes, hex encoded.
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"todo-app/domain"
+	"todo-app/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookUsecase(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryWebhookRepo()
+	usecase := NewWebhookUsecase(repo, slog.Default())
+
+	t.Run("create generates a secret", func(t *testing.T) {
+		webhook := &domain.Webhook{URL: "https://example.com/hook", EventTypes: []domain.TodoEventType{domain.TodoCreated}, Enabled: true}
+
+		require.NoError(t, usecase.Create(ctx, webhook))
+
+		assert.Len(t, webhook.Secret, 64)
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		err := usecase.Create(ctx, &domain.Webhook{URL: "ftp://example.com", EventTypes: []domain.TodoEventType{"todo.archived"}})
+
+		var validationErr *domain.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		rules := map[string]string{}
+		for _, v := range validationErr.Violations {
+			rules[v.Field] = v.Rule
+		}
+		assert.Equal(t, "http_url", rules["url"])
+		assert.Equal(t, "oneof", rules["event_types[0]"])
+	})
+
+	t.Run("update keeps the secret and re-enabling resets failures", func(t *testing.T) {
+		webhook := &domain.Webhook{URL: "https://example.com/hook", Secret: "0123456789abcdef", EventTypes: []domain.TodoEventType{domain.TodoCreated}, Enabled: true, Owner: "alice"}
+		require.NoError(t, usecase.Create(ctx, webhook))
+
+		update := &domain.Webhook{ID: webhook.ID, URL: "https://example.com/other", EventTypes: webhook.EventTypes, Owner: "alice"}
+		require.NoError(t, usecase.Update(ctx, update))
+		found, err := usecase.Get(ctx, "alice", webhook.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "0123456789abcdef", found.Secret)
+		assert.False(t, found.Enabled)
+		assert.NotNil(t, found.DisabledAt)
+
+		found.ConsecutiveFailures = 20
+		require.NoError(t, repo.Update(ctx, found))
+		update = &domain.Webhook{ID: webhook.ID, URL: found.URL, EventTypes: found.EventTypes, Enabled: true, Owner: "alice"}
+		require.NoError(t, usecase.Update(ctx, update))
+		assert.Zero(t, update.ConsecutiveFailures)
+		assert.Nil(t, update.DisabledAt)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := usecase.Deliveries(ctx, "alice", [16]byte{1})
+
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("webhooks of other users are not found", func(t *testing.T) {
+		webhook := &domain.Webhook{URL: "https://example.com/bob", EventTypes: []domain.TodoEventType{domain.TodoCreated}, Enabled: true, Owner: "bob"}
+		require.NoError(t, usecase.Create(ctx, webhook))
+
+		_, err := usecase.Get(ctx, "alice", webhook.ID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		_, err = usecase.Deliveries(ctx, "alice", webhook.ID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		err = usecase.Update(ctx, &domain.Webhook{ID: webhook.ID, URL: "https://example.com/alice", EventTypes: webhook.EventTypes, Owner: "alice"})
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		assert.ErrorIs(t, usecase.Delete(ctx, "alice", webhook.ID), domain.ErrNotFound)
+
+		webhooks, err := usecase.List(ctx, "alice")
+		require.NoError(t, err)
+		for _, w := range webhooks {
+			assert.NotEqual(t, webhook.ID, w.ID)
+		}
+		found, err := usecase.Get(ctx, "bob", webhook.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com/bob", found.URL)
+	})
+}
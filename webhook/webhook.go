@@ -0,0 +1,143 @@
+// Package webhook delivers todo events to the URLs subscribed at /webhooks.
+// Each request body is the event as JSON, signed with the webhook's secret
+// so receivers can check it came from this service.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+	"todo-app/domain"
+)
+
+// Headers sent with every delivery.
+const (
+	// HeaderSignature carries Sign(secret, timestamp, body).
+	HeaderSignature = "X-Webhook-Signature"
+	// HeaderTimestamp is the Unix time the attempt was signed at. Receivers
+	// should reject old timestamps to stop replays.
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	// HeaderEvent is the event type, such as todo.created.
+	HeaderEvent = "X-Webhook-Event"
+	// HeaderDelivery identifies the delivery. It is the same on every retry,
+	// so receivers can drop duplicates.
+	HeaderDelivery = "X-Webhook-Delivery"
+	// HeaderWebhook identifies the subscription.
+	HeaderWebhook = "X-Webhook-ID"
+)
+
+// Sign returns the signature of body sent at timestamp: "sha256=" followed
+// by the hex HMAC-SHA256, keyed with secret, of "<timestamp>.<body>".
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the signature of body sent at
+// timestamp, comparing in constant time.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// ErrForbiddenAddress is returned for a webhook URL that resolves to a
+// loopback, private, link-local or otherwise internal address.
+var ErrForbiddenAddress = errors.New("webhook address not allowed")
+
+// Sender posts deliveries over HTTP. It implements domain.WebhookSender.
+type Sender struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewSender returns a Sender giving up on each request after timeout.
+// Redirects are not followed: a webhook URL must answer directly. Unless
+// allowPrivate is set, connections to internal addresses are refused, so a
+// webhook cannot reach services inside the deployment. The check runs on
+// the address actually dialed, after DNS resolution, which also catches
+// names that resolve to internal addresses. Proxies from the environment
+// are only used with allowPrivate, as they would dial in our stead.
+func NewSender(timeout time.Duration, allowPrivate bool) *Sender {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !allowPrivate {
+		dialer := &net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second, Control: refusePrivate}
+		transport.DialContext = dialer.DialContext
+		transport.Proxy = nil
+	}
+	return &Sender{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		now: time.Now,
+	}
+}
+
+// refusePrivate is a net.Dialer Control function failing connections to
+// addresses that are not publicly routable.
+func refusePrivate(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+	}
+	if !publicAddr(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// sharedAddressSpace is the carrier-grade NAT range of RFC 6598, which
+// netip does not count as private.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// publicAddr reports whether addr is a globally routable unicast address.
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() &&
+		!addr.IsPrivate() &&
+		!addr.IsLoopback() &&
+		!addr.IsLinkLocalUnicast() &&
+		!sharedAddressSpace.Contains(addr)
+}
+
+// Send posts the payload of delivery to webhook. A response outside 2xx is
+// not an error; the caller decides from the status code.
+func (s *Sender) Send(ctx context.Context, webhook *domain.Webhook, delivery *domain.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("building request: %w", err)
+	}
+	timestamp := s.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "todo-app-webhooks/1")
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, delivery.Payload))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderEvent, string(delivery.EventType))
+	req.Header.Set(HeaderDelivery, delivery.ID.String())
+	req.Header.Set(HeaderWebhook, webhook.ID.String())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Drain a little of the body so the connection can be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,172 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+	"todo-app/domain"
+	"todo-app/repository"
+	"todo-app/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"type":"todo.created"}`)
+	signature := Sign("secret", 1760778000, body)
+
+	assert.Equal(t, "sha256=", signature[:7])
+	assert.True(t, Verify("secret", 1760778000, body, signature))
+	assert.False(t, Verify("other", 1760778000, body, signature))
+	assert.False(t, Verify("secret", 1760778001, body, signature))
+	assert.False(t, Verify("secret", 1760778000, []byte(`{}`), signature))
+}
+
+// receiver is a webhook endpoint answering with status and recording what
+// it was sent.
+type receiver struct {
+	*httptest.Server
+	requests chan *http.Request
+	bodies   chan []byte
+}
+
+func newReceiver(t *testing.T, status int) *receiver {
+	r := &receiver{requests: make(chan *http.Request, 10), bodies: make(chan []byte, 10)}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.requests <- req
+		r.bodies <- body
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func TestSender(t *testing.T) {
+	recv := newReceiver(t, http.StatusAccepted)
+	webhook := &domain.Webhook{URL: recv.URL, Secret: "0123456789abcdef"}
+	delivery := &domain.WebhookDelivery{EventType: domain.TodoUpdated, Payload: json.RawMessage(`{"id":"b-1"}`)}
+
+	status, err := NewSender(time.Second, true).Send(context.Background(), webhook, delivery)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusAccepted, status)
+	req, body := <-recv.requests, <-recv.bodies
+	assert.Equal(t, `{"id":"b-1"}`, string(body))
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, "todo.updated", req.Header.Get(HeaderEvent))
+	assert.Equal(t, delivery.ID.String(), req.Header.Get(HeaderDelivery))
+	assert.Equal(t, webhook.ID.String(), req.Header.Get(HeaderWebhook))
+	timestamp, err := strconv.ParseInt(req.Header.Get(HeaderTimestamp), 10, 64)
+	require.NoError(t, err)
+	assert.True(t, Verify(webhook.Secret, timestamp, body, req.Header.Get(HeaderSignature)))
+
+	t.Run("connection error", func(t *testing.T) {
+		closed := httptest.NewServer(http.NotFoundHandler())
+		closed.Close()
+
+		_, err := NewSender(time.Second, true).Send(context.Background(), &domain.Webhook{URL: closed.URL}, delivery)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestSenderRefusesPrivateAddresses(t *testing.T) {
+	recv := newReceiver(t, http.StatusOK)
+	_, port, err := net.SplitHostPort(recv.Listener.Addr().String())
+	require.NoError(t, err)
+	delivery := &domain.WebhookDelivery{EventType: domain.TodoCreated, Payload: json.RawMessage(`{}`)}
+	sender := NewSender(time.Second, false)
+
+	for _, url := range []string{
+		recv.URL,
+		"http://localhost:" + port,
+		"http://[::1]:" + port,
+		"http://0.0.0.0:" + port,
+		"http://169.254.169.254/latest/meta-data/",
+		"http://10.0.0.1/",
+		"http://192.168.1.1/",
+		"http://[fd00::1]/",
+	} {
+		t.Run(url, func(t *testing.T) {
+			_, err := sender.Send(context.Background(), &domain.Webhook{URL: url}, delivery)
+
+			assert.ErrorIs(t, err, ErrForbiddenAddress)
+		})
+	}
+	assert.Empty(t, recv.requests)
+}
+
+func TestPublicAddr(t *testing.T) {
+	for addr, public := range map[string]bool{
+		"93.184.216.34":       true,
+		"2606:2800:220:1::1":  true,
+		"127.0.0.1":           false,
+		"::1":                 false,
+		"10.1.2.3":            false,
+		"172.16.0.1":          false,
+		"192.168.0.1":         false,
+		"100.64.0.1":          false,
+		"169.254.169.254":     false,
+		"fe80::1":             false,
+		"fc00::1":             false,
+		"::ffff:127.0.0.1":    false,
+		"::ffff:93.184.216.3": true,
+		"0.0.0.0":             false,
+		"224.0.0.1":           false,
+		"255.255.255.255":     false,
+	} {
+		assert.Equal(t, public, publicAddr(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestWorker(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	recv := newReceiver(t, http.StatusOK)
+	repo := repository.NewMemoryWebhookRepo()
+	webhook := &domain.Webhook{URL: recv.URL, Secret: "0123456789abcdef", EventTypes: []domain.TodoEventType{domain.TodoCreated}, Enabled: true}
+	require.NoError(t, repo.Create(ctx, webhook))
+
+	dispatcher := usecase.NewWebhookDispatcher(repo, NewSender(time.Second, true), usecase.WebhookPolicy{
+		MaxAttempts: 3, InitialBackoff: time.Minute, MaxBackoff: time.Hour, DisableAfter: 10, BatchSize: 10, Lease: time.Minute,
+	}, slog.Default())
+	stopped := make(chan struct{})
+	go func() {
+		NewWorker(dispatcher, 10*time.Millisecond).Run(ctx)
+		close(stopped)
+	}()
+
+	event := domain.TodoEvent{ID: "1", Type: domain.TodoCreated, Todo: domain.Todo{Title: "Buy milk"}}
+	require.NoError(t, dispatcher.Enqueue(ctx, domain.TodoEvent{ID: "2", Type: domain.TodoDeleted}))
+	require.NoError(t, dispatcher.Enqueue(ctx, event))
+	require.NoError(t, dispatcher.Enqueue(ctx, event))
+
+	select {
+	case body := <-recv.bodies:
+		var got domain.TodoEvent
+		require.NoError(t, json.Unmarshal(body, &got))
+		assert.Equal(t, domain.TodoCreated, got.Type)
+		assert.Equal(t, "Buy milk", got.Todo.Title)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no delivery received")
+	}
+	assert.Equal(t, domain.TodoCreated, domain.TodoEventType((<-recv.requests).Header.Get(HeaderEvent)))
+
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker did not stop")
+	}
+	assert.Empty(t, recv.bodies, "unsubscribed and repeated events are not delivered")
+}
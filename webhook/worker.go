@@ -0,0 +1,43 @@
+package webhook
+
+import (
+	"context"
+	"time"
+	"todo-app/usecase"
+)
+
+// Worker sends the webhook deliveries that are due. Deliveries are queued by
+// the dispatcher, which the outbox relay feeds with every todo event.
+type Worker struct {
+	dispatcher   *usecase.WebhookDispatcher
+	pollInterval time.Duration
+}
+
+// NewWorker returns a Worker checking for due deliveries every pollInterval
+// once Run is started.
+func NewWorker(dispatcher *usecase.WebhookDispatcher, pollInterval time.Duration) *Worker {
+	return &Worker{
+		dispatcher:   dispatcher,
+		pollInterval: pollInterval,
+	}
+}
+
+// Run sends due deliveries every pollInterval, checking again straight away
+// for as long as there were some, until ctx is done.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for ctx.Err() == nil {
+			n, err := w.dispatcher.DeliverDue(ctx)
+			if err != nil || n == 0 {
+				break
+			}
+		}
+	}
+}